@@ -1,12 +1,19 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"runtime"
+	"strings"
 
+	"github.com/rodrigo-brito/ninjabot/config"
 	"github.com/rodrigo-brito/ninjabot/download"
 	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/optimizer"
 	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/strategy"
 
 	"github.com/urfave/cli/v2"
 )
@@ -67,16 +74,34 @@ func main() {
 						Value:    false,
 						Required: false,
 					},
+					&cli.BoolFlag{
+						Name:     "market-data",
+						Aliases:  []string{"m"},
+						Usage:    "save funding rate, mark price and open interest (futures only)",
+						Value:    false,
+						Required: false,
+					},
 				},
 				Action: func(c *cli.Context) error {
 					var (
-						exc service.Feeder
-						err error
+						exc     service.Feeder
+						err     error
+						options []download.Option
 					)
 
+					if c.Bool("market-data") && !c.Bool("futures") {
+						return errors.New("market data is only available for futures")
+					}
+
 					if c.Bool("futures") {
 						// fetch data from binance futures
-						exc, err = exchange.NewBinanceFuture(c.Context)
+						var futureOptions []exchange.BinanceFutureOption
+						if c.Bool("market-data") {
+							futureOptions = append(futureOptions, exchange.WithBinanceFutureMarketData())
+							options = append(options, download.WithMetadata(exchange.MarketDataKeys...))
+						}
+
+						exc, err = exchange.NewBinanceFuture(c.Context, futureOptions...)
 						if err != nil {
 							return err
 						}
@@ -88,7 +113,6 @@ func main() {
 						}
 					}
 
-					var options []download.Option
 					if days := c.Int("days"); days > 0 {
 						options = append(options, download.WithDays(days))
 					}
@@ -106,6 +130,143 @@ func main() {
 
 				},
 			},
+			{
+				Name:     "record",
+				HelpName: "record",
+				Usage:    "Record real-time trades and order book updates",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "pair",
+						Aliases:  []string{"p"},
+						Usage:    "eg. BTCUSDT",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "trades",
+						Aliases:  []string{"t"},
+						Usage:    "output of aggregated trades, eg. ./btc-trades.csv",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "orderbook",
+						Aliases:  []string{"b"},
+						Usage:    "output of order book updates, eg. ./btc-book.csv",
+						Required: false,
+					},
+					&cli.IntFlag{
+						Name:     "depth",
+						Aliases:  []string{"d"},
+						Usage:    "levels of the order book snapshots",
+						Value:    1000,
+						Required: false,
+					},
+					&cli.DurationFlag{
+						Name:     "duration",
+						Usage:    "eg. 1h (default until interrupted)",
+						Required: false,
+					},
+				},
+				Action: record,
+			},
+			runCommand(config.ModeBacktest, "Run a backtest from a config file"),
+			runCommand(config.ModePaper, "Run a paper trading bot from a config file"),
+			runCommand(config.ModeLive, "Run a live trading bot from a config file"),
+			{
+				Name:     "strategies",
+				HelpName: "strategies",
+				Usage:    "List the strategies available in config files, with their parameters",
+				Action:   listStrategies,
+			},
+			{
+				Name:     "optimize",
+				HelpName: "optimize",
+				Usage:    "Search the best parameters of a strategy with backtests",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "strategy",
+						Aliases:  []string{"s"},
+						Usage:    fmt.Sprintf("eg. emacross, available: %s", strings.Join(strategy.Registered(), ", ")),
+						Required: true,
+					},
+					&cli.StringSliceFlag{
+						Name:     "feed",
+						Aliases:  []string{"f"},
+						Usage:    "pair and CSV file, eg. BTCUSDT=./btc.csv",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "timeframe",
+						Aliases:  []string{"t"},
+						Usage:    "timeframe of CSV files, eg. 1h",
+						Required: true,
+					},
+					&cli.StringSliceFlag{
+						Name:     "param",
+						Aliases:  []string{"p"},
+						Usage:    "parameter space as name=min:max:step, eg. ema=5:20:1 (default: declared by the strategy)",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "metric",
+						Aliases:  []string{"m"},
+						Usage:    "profit, sqn, profit_factor or max_drawdown",
+						Value:    string(optimizer.MetricProfit),
+						Required: false,
+					},
+					&cli.IntFlag{
+						Name:     "samples",
+						Usage:    "number of random samples, eg. 100 (default: grid search)",
+						Required: false,
+					},
+					&cli.Int64Flag{
+						Name:     "seed",
+						Usage:    "seed of random search",
+						Value:    1,
+						Required: false,
+					},
+					&cli.IntFlag{
+						Name:     "workers",
+						Aliases:  []string{"w"},
+						Usage:    "number of parallel backtests",
+						Value:    runtime.NumCPU(),
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "base",
+						Usage:    "base coin of the wallet",
+						Value:    "USDT",
+						Required: false,
+					},
+					&cli.Float64Flag{
+						Name:     "balance",
+						Usage:    "initial balance of the wallet",
+						Value:    10000,
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "output",
+						Aliases:  []string{"o"},
+						Usage:    "eg. ./results.csv",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "in-sample",
+						Usage:    "walk-forward in-sample period, eg. 90d",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "out-sample",
+						Usage:    "walk-forward out-of-sample period, eg. 30d",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "equity",
+						Usage:    "walk-forward out-of-sample equity output, eg. ./equity.csv",
+						Required: false,
+					},
+				},
+				Action: optimize,
+			},
 		},
 	}
 
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+	"github.com/xhit/go-str2duration/v2"
+
+	"github.com/rodrigo-brito/ninjabot"
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/optimizer"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+func optimize(c *cli.Context) error {
+	name := c.String("strategy")
+	factory := func(params optimizer.Params) (strategy.Strategy, error) {
+		values := make(strategy.Params, len(params))
+		for param, value := range params {
+			values[param] = value
+		}
+		return strategy.New(name, values)
+	}
+
+	str, err := factory(optimizer.Params{})
+	if err != nil {
+		return err
+	}
+
+	var (
+		pairs []string
+		feeds []exchange.PairFeed
+	)
+	for _, feed := range c.StringSlice("feed") {
+		pair, file, ok := strings.Cut(feed, "=")
+		if !ok {
+			return fmt.Errorf("invalid feed %q, expected PAIR=FILE", feed)
+		}
+
+		pairs = append(pairs, pair)
+		feeds = append(feeds, exchange.PairFeed{
+			Pair:      pair,
+			File:      file,
+			Timeframe: c.String("timeframe"),
+		})
+	}
+
+	csvFeed, err := exchange.NewCSVFeed(str.Timeframe(), feeds...)
+	if err != nil {
+		return err
+	}
+
+	var parameters []optimizer.Parameter
+	for _, definition := range c.StringSlice("param") {
+		parameter, err := optimizer.ParseParameter(definition)
+		if err != nil {
+			return err
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	// without parameters in the command, search the space declared by the strategy
+	if len(parameters) == 0 {
+		declarations, err := strategy.Describe(name)
+		if err != nil {
+			return err
+		}
+
+		parameters = optimizer.ParametersOf(declarations)
+		if len(parameters) == 0 {
+			return fmt.Errorf("strategy %s does not declare parameters, use --param", name)
+		}
+	}
+
+	options := []optimizer.Option{
+		optimizer.WithMetric(optimizer.Metric(c.String("metric"))),
+		optimizer.WithWorkers(c.Int("workers")),
+		optimizer.WithPaperWallet(c.String("base"), exchange.WithPaperAsset(c.String("base"), c.Float64("balance"))),
+	}
+	if samples := c.Int("samples"); samples > 0 {
+		options = append(options, optimizer.WithRandomSearch(samples, c.Int64("seed")))
+	}
+
+	log.SetLevel(log.ErrorLevel)
+	opt := optimizer.New(csvFeed, ninjabot.Settings{Pairs: pairs}, factory, parameters, options...)
+
+	if c.String("in-sample") != "" || c.String("out-sample") != "" {
+		return walkForward(c, opt)
+	}
+
+	results, err := opt.Run(c.Context)
+	if err != nil {
+		return err
+	}
+
+	printResults(parameters, results)
+
+	if output := c.String("output"); output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return optimizer.WriteCSV(file, parameters, results)
+	}
+
+	return nil
+}
+
+func walkForward(c *cli.Context, opt *optimizer.Optimizer) error {
+	inSample, err := str2duration.ParseDuration(c.String("in-sample"))
+	if err != nil {
+		return fmt.Errorf("invalid in-sample period: %w", err)
+	}
+
+	outSample, err := str2duration.ParseDuration(c.String("out-sample"))
+	if err != nil {
+		return fmt.Errorf("invalid out-of-sample period: %w", err)
+	}
+
+	report, err := opt.WalkForward(c.Context, inSample, outSample)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(report)
+
+	if output := c.String("equity"); output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return report.WriteEquityCSV(file)
+	}
+
+	return nil
+}
+
+// printResults displays the 10 best results
+func printResults(parameters []optimizer.Parameter, results []optimizer.Result) {
+	header := []string{"#"}
+	for _, parameter := range parameters {
+		header = append(header, parameter.Name)
+	}
+	header = append(header, "Trades", "% Win", "Profit", "SQN", "Pr Fact.", "Max DD")
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	for i, result := range results {
+		if i >= 10 {
+			break
+		}
+
+		line := []string{strconv.Itoa(i + 1)}
+		for _, parameter := range parameters {
+			line = append(line, strconv.FormatFloat(result.Params[parameter.Name], 'f', -1, 64))
+		}
+
+		if result.Err != nil {
+			line = append(line, result.Err.Error(), "", "", "", "", "")
+			table.Append(line)
+			continue
+		}
+
+		var winPercent float64
+		if result.Trades > 0 {
+			winPercent = float64(result.Win) / float64(result.Trades) * 100
+		}
+
+		line = append(line,
+			strconv.Itoa(result.Trades),
+			fmt.Sprintf("%.1f %%", winPercent),
+			fmt.Sprintf("%.2f", result.Profit),
+			fmt.Sprintf("%.1f", result.SQN),
+			fmt.Sprintf("%.3f", result.ProfitFactor),
+			fmt.Sprintf("%.2f %%", result.MaxDrawdown*100),
+		)
+		table.Append(line)
+	}
+	table.Render()
+}
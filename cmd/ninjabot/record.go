@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/rodrigo-brito/ninjabot/download"
+	"github.com/rodrigo-brito/ninjabot/exchange"
+)
+
+func record(c *cli.Context) error {
+	if c.String("trades") == "" && c.String("orderbook") == "" {
+		return errors.New("at least one output must be informed: --trades or --orderbook")
+	}
+
+	ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
+	defer cancel()
+
+	if duration := c.Duration("duration"); duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	binance, err := exchange.NewBinance(ctx)
+	if err != nil {
+		return err
+	}
+
+	pair := c.String("pair")
+	recorder := download.NewRecorder(binance)
+	errs := make([]error, 2)
+	wg := new(sync.WaitGroup)
+	if output := c.String("trades"); output != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[0] = recorder.RecordTrades(ctx, pair, output)
+		}()
+	}
+
+	if output := c.String("orderbook"); output != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[1] = recorder.RecordOrderBook(ctx, pair, output, c.Int("depth"))
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/rodrigo-brito/ninjabot/config"
+	"github.com/rodrigo-brito/ninjabot/examples/strategies"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+func init() {
+	// example strategies available in the config files
+	strategy.Register("emacross", func(params strategy.Params) (strategy.Strategy, error) {
+		return &strategies.CrossEMA{
+			EMAPeriod: params.Int("ema", 0),
+			SMAPeriod: params.Int("sma", 0),
+		}, nil
+	})
+	strategy.Register("turtle", func(params strategy.Params) (strategy.Strategy, error) {
+		return &strategies.Turtle{
+			EntryPeriod: params.Int("entry", 0),
+			ExitPeriod:  params.Int("exit", 0),
+		}, nil
+	})
+	strategy.Register("ocosell", func(strategy.Params) (strategy.Strategy, error) {
+		return new(strategies.OCOSell), nil
+	})
+	strategy.Register("trailing", func(strategy.Params) (strategy.Strategy, error) {
+		return strategies.NewTrailing(), nil
+	})
+}
+
+// runCommand creates the command that executes the bot of a config file in the given mode
+func runCommand(mode config.Mode, usage string) *cli.Command {
+	return &cli.Command{
+		Name:     string(mode),
+		HelpName: string(mode),
+		Usage:    usage,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "config",
+				Aliases:  []string{"c"},
+				Usage:    "YAML or TOML file, eg. ./ninjabot.yaml",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load(c.String("config"))
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
+			defer cancel()
+
+			return runBot(ctx, cfg, mode)
+		},
+	}
+}
+
+func runBot(ctx context.Context, cfg config.Config, mode config.Mode) error {
+	bot, err := cfg.NewBot(ctx, mode)
+	if err != nil {
+		return err
+	}
+	return bot.Run(ctx)
+}
+
+func listStrategies(_ *cli.Context) error {
+	for _, name := range strategy.Registered() {
+		fmt.Println(name)
+
+		params, err := strategy.Describe(name)
+		if err != nil {
+			return err
+		}
+		for _, param := range params {
+			fmt.Printf("  %s\n", param)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,381 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/xhit/go-str2duration/v2"
+	"gorm.io/gorm"
+
+	"github.com/rodrigo-brito/ninjabot"
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/plot"
+	"github.com/rodrigo-brito/ninjabot/rules"
+	"github.com/rodrigo-brito/ninjabot/script"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/storage"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+const (
+	defaultBase    = "USDT"
+	defaultBalance = 10000
+	defaultFile    = "ninjabot.db"
+	defaultSQLite  = "ninjabot.sqlite"
+
+	// scriptExtension is the extension of strategies in Starlark, other files are loaded as rules in YAML
+	scriptExtension = ".star"
+)
+
+// Bot is a bot created from a config file, with the paper wallet and the chart of the mode
+type Bot struct {
+	*ninjabot.NinjaBot
+
+	Mode   Mode
+	Wallet *exchange.PaperWallet
+	Chart  *plot.Chart
+
+	config Config
+}
+
+// NewBot creates a bot from the config, in the given mode. The strategies are built from the registry,
+// or from the rules or script of a file. Scripts are reloaded when the file changes, except in backtests.
+func (c Config) NewBot(ctx context.Context, mode Mode) (*Bot, error) {
+	if err := c.Validate(mode); err != nil {
+		return nil, err
+	}
+
+	if c.LogLevel != "" {
+		level, err := log.ParseLevel(c.LogLevel)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidConfig, err)
+		}
+		log.SetLevel(level)
+	}
+
+	bot := &Bot{
+		Mode:   mode,
+		config: c,
+	}
+
+	var options []ninjabot.Option
+	strategies := make([]strategy.Strategy, 0, len(c.Strategies))
+	for _, allocation := range c.Strategies {
+		str, name, err := allocation.newStrategy(mode)
+		if err != nil {
+			return nil, err
+		}
+		strategies = append(strategies, str)
+
+		id := allocation.ID
+		if id == "" {
+			id = name
+		}
+
+		pairs := allocation.Pairs
+		if len(pairs) == 0 {
+			pairs = c.Pairs
+		}
+
+		options = append(options, ninjabot.WithStrategy(id, str, pairs, allocation.Capital))
+	}
+
+	// the backtest feed and the chart use the smallest timeframe of the strategies, as the bot updates
+	// the wallet with it, larger timeframes are resampled from it
+	main, err := smallestTimeframe(strategies)
+	if err != nil {
+		return nil, err
+	}
+
+	var exch service.Exchange
+	switch mode {
+	case ModeBacktest:
+		feeder, err := c.backtestFeed(main.Timeframe())
+		if err != nil {
+			return nil, err
+		}
+
+		bot.Wallet = exchange.NewPaperWallet(ctx, c.base(), c.walletOptions(feeder)...)
+		exch = bot.Wallet
+		options = append(options, ninjabot.WithBacktest(bot.Wallet))
+		if c.Backtest.Progress != nil {
+			options = append(options, ninjabot.WithProgressBar(*c.Backtest.Progress))
+		}
+	case ModePaper:
+		feeder, err := c.newExchange(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		bot.Wallet = exchange.NewPaperWallet(ctx, c.base(), c.walletOptions(feeder)...)
+		exch = bot.Wallet
+		options = append(options, ninjabot.WithPaperWallet(bot.Wallet))
+	case ModeLive:
+		exch, err = c.newExchange(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := c.newStorage(mode)
+	if err != nil {
+		return nil, err
+	}
+	if db != nil {
+		options = append(options, ninjabot.WithStorage(db))
+	}
+
+	if c.Chart.Port > 0 || (mode == ModeBacktest && c.Backtest.Report != "") {
+		chartOptions := []plot.Option{plot.WithStrategyIndicators(main)}
+		if c.Chart.Port > 0 {
+			chartOptions = append(chartOptions, plot.WithPort(c.Chart.Port))
+		}
+		if bot.Wallet != nil {
+			chartOptions = append(chartOptions, plot.WithPaperWallet(bot.Wallet))
+		}
+
+		bot.Chart, err = plot.NewChart(chartOptions...)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, ninjabot.WithCandleSubscription(bot.Chart), ninjabot.WithOrderSubscription(bot.Chart))
+	}
+
+	settings := ninjabot.Settings{
+		Pairs: c.Pairs,
+		Telegram: ninjabot.TelegramSettings{
+			Enabled: c.Notifiers.Telegram.Token != "" && mode != ModeBacktest,
+			Token:   c.Notifiers.Telegram.Token,
+			Users:   c.Notifiers.Telegram.Users,
+		},
+	}
+
+	bot.NinjaBot, err = ninjabot.NewBot(ctx, settings, exch, nil, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return bot, nil
+}
+
+// Run executes the bot. The chart is served during the execution in paper and live modes. Backtests print
+// the summary, save the reports and serve the chart at the end of the execution.
+func (b *Bot) Run(ctx context.Context) error {
+	if b.Mode != ModeBacktest && b.Chart != nil && b.config.Chart.Port > 0 {
+		go func() {
+			if err := b.Chart.Start(); err != nil {
+				log.Error(err)
+			}
+		}()
+	}
+
+	if err := b.NinjaBot.Run(ctx); err != nil {
+		return err
+	}
+
+	if b.Mode != ModeBacktest {
+		return nil
+	}
+
+	b.Summary()
+
+	report := b.Report()
+	if b.config.Backtest.JSON != "" {
+		if err := report.SaveJSON(b.config.Backtest.JSON); err != nil {
+			return err
+		}
+	}
+
+	if b.config.Backtest.Report != "" {
+		if err := b.Chart.SaveHTML(b.config.Backtest.Report, report); err != nil {
+			return err
+		}
+	}
+
+	if b.config.Chart.Port > 0 {
+		return b.Chart.Start()
+	}
+
+	return nil
+}
+
+// newStrategy builds the strategy of the config, and returns it with its name
+func (s StrategyConfig) newStrategy(mode Mode) (strategy.Strategy, string, error) {
+	if s.File == "" {
+		str, err := strategy.New(s.Name, s.Params)
+		if err != nil {
+			return nil, "", fmt.Errorf("strategy %s: %w", s.Name, err)
+		}
+		return str, s.Name, nil
+	}
+
+	if strings.EqualFold(filepath.Ext(s.File), scriptExtension) {
+		var options []script.Option
+		if mode != ModeBacktest {
+			// backtests are limited only by the steps, wall-clock timeouts would make them non-deterministic
+			options = append(options, script.WithTimeout(script.DefaultTimeout), script.WithAutoReload())
+		}
+
+		str, err := script.Load(s.File, s.Params, options...)
+		if err != nil {
+			return nil, "", fmt.Errorf("strategy %s: %w", s.File, err)
+		}
+		return str, str.Name(), nil
+	}
+
+	definition, err := rules.Load(s.File)
+	if err != nil {
+		return nil, "", fmt.Errorf("strategy %s: %w", s.File, err)
+	}
+
+	str, err := rules.New(definition, s.Params)
+	if err != nil {
+		return nil, "", fmt.Errorf("strategy %s: %w", s.File, err)
+	}
+
+	name := str.Name()
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(s.File), filepath.Ext(s.File))
+	}
+	return str, name, nil
+}
+
+func (c Config) base() string {
+	if c.Paper.Base == "" {
+		return defaultBase
+	}
+	return strings.ToUpper(c.Paper.Base)
+}
+
+func (c Config) walletOptions(feeder service.Feeder) []exchange.PaperWalletOption {
+	options := []exchange.PaperWalletOption{
+		exchange.WithDataFeed(feeder),
+		exchange.WithPaperFee(c.Paper.MakerFee, c.Paper.TakerFee),
+	}
+
+	if len(c.Paper.Assets) == 0 {
+		options = append(options, exchange.WithPaperAsset(c.base(), defaultBalance))
+	}
+	for asset, amount := range c.Paper.Assets {
+		options = append(options, exchange.WithPaperAsset(strings.ToUpper(asset), amount))
+	}
+
+	if c.Paper.Slippage > 0 {
+		options = append(options, exchange.WithPaperSlippage(exchange.FixedSlippage(c.Paper.Slippage)))
+	}
+
+	if c.Exchange.Name == ExchangeBinanceFutures {
+		options = append(options, exchange.WithPaperFutures())
+		for pair, leverage := range c.Exchange.Leverage {
+			options = append(options, exchange.WithPaperLeverage(pair, leverage, c.marginType()))
+		}
+	}
+
+	return options
+}
+
+func (c Config) marginType() exchange.MarginType {
+	if strings.EqualFold(c.Exchange.MarginType, string(exchange.MarginTypeCrossed)) {
+		return exchange.MarginTypeCrossed
+	}
+	return exchange.MarginTypeIsolated
+}
+
+func (c Config) newExchange(ctx context.Context) (service.Exchange, error) {
+	if c.Exchange.Name == ExchangeBinanceFutures {
+		var options []exchange.BinanceFutureOption
+		if c.Exchange.APIKey != "" {
+			options = append(options, exchange.WithBinanceFutureCredentials(c.Exchange.APIKey, c.Exchange.SecretKey))
+		}
+		if c.Exchange.HeikinAshi {
+			options = append(options, exchange.WithBinanceFuturesHeikinAshiCandle())
+		}
+		for pair, leverage := range c.Exchange.Leverage {
+			options = append(options, exchange.WithBinanceFutureLeverage(strings.ToUpper(pair), leverage,
+				c.marginType()))
+		}
+		return exchange.NewBinanceFuture(ctx, options...)
+	}
+
+	var options []exchange.BinanceOption
+	if c.Exchange.APIKey != "" {
+		options = append(options, exchange.WithBinanceCredentials(c.Exchange.APIKey, c.Exchange.SecretKey))
+	}
+	if c.Exchange.Testnet {
+		options = append(options, exchange.WithTestNet())
+	}
+	if c.Exchange.HeikinAshi {
+		options = append(options, exchange.WithBinanceHeikinAshiCandle())
+	}
+	return exchange.NewBinance(ctx, options...)
+}
+
+// smallestTimeframe returns the strategy with the smallest timeframe, the first one in case of a tie
+func smallestTimeframe(strategies []strategy.Strategy) (strategy.Strategy, error) {
+	var (
+		smallest strategy.Strategy
+		duration time.Duration
+	)
+	for _, str := range strategies {
+		timeframe, err := str2duration.ParseDuration(str.Timeframe())
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid timeframe %q: %s", ErrInvalidConfig, str.Timeframe(), err)
+		}
+
+		if smallest == nil || timeframe < duration {
+			smallest, duration = str, timeframe
+		}
+	}
+	return smallest, nil
+}
+
+func (c Config) backtestFeed(timeframe string) (service.Feeder, error) {
+	if c.Backtest.Feeds[0].Type == FeedTrades {
+		feeds := make([]exchange.PairTradeFeed, 0, len(c.Backtest.Feeds))
+		for _, feed := range c.Backtest.Feeds {
+			feeds = append(feeds, exchange.PairTradeFeed{Pair: feed.Pair, File: feed.File})
+		}
+		return exchange.NewTradeFeed(feeds...)
+	}
+
+	feeds := make([]exchange.PairFeed, 0, len(c.Backtest.Feeds))
+	for _, feed := range c.Backtest.Feeds {
+		feeds = append(feeds, exchange.PairFeed{
+			Pair:       feed.Pair,
+			File:       feed.File,
+			Timeframe:  feed.Timeframe,
+			HeikinAshi: feed.HeikinAshi,
+		})
+	}
+	return exchange.NewCSVFeed(timeframe, feeds...)
+}
+
+// newStorage returns the storage of the config, or nil to use the default storage of the bot
+func (c Config) newStorage(mode Mode) (storage.Storage, error) {
+	switch c.Storage.Type {
+	case StorageMemory:
+		return storage.FromMemory()
+	case StorageFile:
+		path := c.Storage.Path
+		if path == "" {
+			path = defaultFile
+		}
+		return storage.FromFile(path)
+	case StorageSQLite:
+		path := c.Storage.Path
+		if path == "" {
+			path = defaultSQLite
+		}
+		return storage.FromSQL(sqlite.Open(path), &gorm.Config{})
+	}
+
+	if mode == ModeBacktest {
+		return storage.FromMemory()
+	}
+	return nil, nil
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/rules"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+type holdStrategy struct {
+	timeframe string
+	hold      int
+	candles   int
+}
+
+func (h holdStrategy) Timeframe() string {
+	return h.timeframe
+}
+
+func (h holdStrategy) WarmupPeriod() int {
+	return 1
+}
+
+func (h holdStrategy) Indicators(_ *model.Dataframe) []strategy.ChartIndicator {
+	return nil
+}
+
+// OnCandle buys in the first candle and sells after holding the position for some candles
+func (h *holdStrategy) OnCandle(df *model.Dataframe, broker service.Broker) {
+	h.candles++
+	switch h.candles {
+	case 1:
+		_, err := broker.CreateOrderMarket(model.SideTypeBuy, df.Pair, 0.1)
+		if err != nil {
+			panic(err)
+		}
+	case h.hold + 1:
+		_, err := broker.CreateOrderMarket(model.SideTypeSell, df.Pair, 0.1)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func init() {
+	strategy.Register("config-test-hold", func(params strategy.Params) (strategy.Strategy, error) {
+		return &holdStrategy{
+			timeframe: params.String("timeframe", "1h"),
+			hold:      params.Int("hold", 10),
+		}, nil
+	})
+}
+
+func TestConfig_NewBot(t *testing.T) {
+	t.Run("backtest", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "report.json")
+		progress := false
+		config := Config{
+			Pairs:    []string{"BTCUSDT"},
+			LogLevel: "error",
+			Paper: PaperConfig{
+				Assets:   map[string]float64{"USDT": 50000},
+				TakerFee: 0.001,
+			},
+			Backtest: BacktestConfig{
+				Feeds: []FeedConfig{
+					{Pair: "BTCUSDT", File: "../testdata/btc-1h.csv", Timeframe: "1h"},
+				},
+				JSON:     output,
+				Progress: &progress,
+			},
+			Strategies: []StrategyConfig{
+				{Name: "config-test-hold", Params: strategy.Params{"hold": 5}},
+				{Name: "config-test-hold", ID: "daily", Params: strategy.Params{"timeframe": "1d"}, Capital: 10000},
+			},
+		}
+
+		bot, err := config.NewBot(context.Background(), ModeBacktest)
+		require.NoError(t, err)
+		require.NotNil(t, bot.Wallet)
+		require.Nil(t, bot.Chart)
+		require.NoError(t, bot.Run(context.Background()))
+
+		// one trade of each strategy
+		for _, id := range []string{"config-test-hold", "daily"} {
+			summary := bot.Controller().StrategyResults[id]["BTCUSDT"]
+			require.NotNil(t, summary)
+			require.Len(t, append(summary.Win(), summary.Lose()...), 1)
+		}
+
+		_, err = os.Stat(output)
+		require.NoError(t, err)
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		progress := false
+		config := Config{
+			Pairs:    []string{"BTCUSDT"},
+			LogLevel: "error",
+			Backtest: BacktestConfig{
+				Feeds:    []FeedConfig{{Pair: "BTCUSDT", File: "../testdata/btc-1h.csv", Timeframe: "1h"}},
+				Progress: &progress,
+			},
+			Strategies: []StrategyConfig{
+				{File: "../examples/rules/emacross.yaml", Params: strategy.Params{"fast": 9}},
+			},
+		}
+
+		bot, err := config.NewBot(context.Background(), ModeBacktest)
+		require.NoError(t, err)
+		require.NoError(t, bot.Run(context.Background()))
+
+		summary := bot.Controller().StrategyResults["emacross-rsi"]["BTCUSDT"]
+		require.NotNil(t, summary)
+		require.NotEmpty(t, summary.Trades)
+	})
+
+	t.Run("script", func(t *testing.T) {
+		progress := false
+		config := Config{
+			Pairs:    []string{"BTCUSDT"},
+			LogLevel: "error",
+			Backtest: BacktestConfig{
+				Feeds:    []FeedConfig{{Pair: "BTCUSDT", File: "../testdata/btc-1h.csv", Timeframe: "1h"}},
+				Progress: &progress,
+			},
+			Strategies: []StrategyConfig{
+				{File: "../examples/scripts/emacross.star", ID: "script", Params: strategy.Params{"ema": 9}},
+			},
+		}
+
+		bot, err := config.NewBot(context.Background(), ModeBacktest)
+		require.NoError(t, err)
+		require.NoError(t, bot.Run(context.Background()))
+
+		summary := bot.Controller().StrategyResults["script"]["BTCUSDT"]
+		require.NotNil(t, summary)
+		require.NotEmpty(t, summary.Trades)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		file := writeConfig(t, "rules.yaml", "timeframe: 1h\nentry:\n  all: [close > sma]\n")
+		config := Config{
+			Pairs:      []string{"BTCUSDT"},
+			Backtest:   BacktestConfig{Feeds: []FeedConfig{{Pair: "BTCUSDT", File: "btc.csv", Timeframe: "1h"}}},
+			Strategies: []StrategyConfig{{File: file}},
+		}
+
+		_, err := config.NewBot(context.Background(), ModeBacktest)
+		require.ErrorIs(t, err, rules.ErrInvalidRules)
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		config := Config{
+			Pairs:      []string{"BTCUSDT"},
+			Backtest:   BacktestConfig{Feeds: []FeedConfig{{Pair: "BTCUSDT", File: "btc.csv", Timeframe: "1h"}}},
+			Strategies: []StrategyConfig{{Name: "not-found"}},
+		}
+
+		_, err := config.NewBot(context.Background(), ModeBacktest)
+		require.ErrorIs(t, err, strategy.ErrStrategyNotFound)
+	})
+
+	t.Run("invalid log level", func(t *testing.T) {
+		config := Config{
+			Pairs:      []string{"BTCUSDT"},
+			LogLevel:   "verbose",
+			Backtest:   BacktestConfig{Feeds: []FeedConfig{{Pair: "BTCUSDT", File: "btc.csv", Timeframe: "1h"}}},
+			Strategies: []StrategyConfig{{Name: "config-test-hold"}},
+		}
+
+		_, err := config.NewBot(context.Background(), ModeBacktest)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+	t.Run("invalid timeframe", func(t *testing.T) {
+		config := Config{
+			Pairs:      []string{"BTCUSDT"},
+			Backtest:   BacktestConfig{Feeds: []FeedConfig{{Pair: "BTCUSDT", File: "btc.csv", Timeframe: "1h"}}},
+			Strategies: []StrategyConfig{{Name: "config-test-hold", Params: strategy.Params{"timeframe": "1x"}}},
+		}
+
+		_, err := config.NewBot(context.Background(), ModeBacktest)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
+
+func TestSmallestTimeframe(t *testing.T) {
+	daily := &holdStrategy{timeframe: "1d"}
+	hourly := &holdStrategy{timeframe: "1h"}
+	other := &holdStrategy{timeframe: "60m"}
+
+	smallest, err := smallestTimeframe([]strategy.Strategy{daily, hourly, other})
+	require.NoError(t, err)
+	require.Same(t, hourly, smallest)
+
+	smallest, err = smallestTimeframe([]strategy.Strategy{daily})
+	require.NoError(t, err)
+	require.Same(t, daily, smallest)
+}
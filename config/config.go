@@ -0,0 +1,234 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+// Mode is the execution mode of a bot created from a config file
+type Mode string
+
+const (
+	// ModeBacktest simulates the strategies with the historical data of the backtest feeds
+	ModeBacktest Mode = "backtest"
+	// ModePaper simulates the strategies with real-time data of the exchange and a paper wallet
+	ModePaper Mode = "paper"
+	// ModeLive executes the strategies in the exchange
+	ModeLive Mode = "live"
+)
+
+// Exchanges supported in config files
+const (
+	ExchangeBinance        = "binance"
+	ExchangeBinanceFutures = "binance-futures"
+)
+
+// Storages supported in config files
+const (
+	StorageMemory = "memory"
+	StorageFile   = "file"
+	StorageSQLite = "sqlite"
+)
+
+// Feed types of the backtest
+const (
+	FeedCandles = "candles"
+	FeedTrades  = "trades"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+type Config struct {
+	Pairs      []string         `yaml:"pairs" toml:"pairs"`
+	LogLevel   string           `yaml:"log_level" toml:"log_level"`
+	Exchange   ExchangeConfig   `yaml:"exchange" toml:"exchange"`
+	Paper      PaperConfig      `yaml:"paper" toml:"paper"`
+	Backtest   BacktestConfig   `yaml:"backtest" toml:"backtest"`
+	Storage    StorageConfig    `yaml:"storage" toml:"storage"`
+	Notifiers  NotifiersConfig  `yaml:"notifiers" toml:"notifiers"`
+	Chart      ChartConfig      `yaml:"chart" toml:"chart"`
+	Strategies []StrategyConfig `yaml:"strategies" toml:"strategies"`
+}
+
+type ExchangeConfig struct {
+	// Name of the exchange: binance (default) or binance-futures
+	Name       string `yaml:"name" toml:"name"`
+	APIKey     string `yaml:"api_key" toml:"api_key"`
+	SecretKey  string `yaml:"secret_key" toml:"secret_key"`
+	Testnet    bool   `yaml:"testnet" toml:"testnet"`
+	HeikinAshi bool   `yaml:"heikin_ashi" toml:"heikin_ashi"`
+
+	// Leverage by pair and margin type (isolated or crossed) of futures
+	Leverage   map[string]int `yaml:"leverage" toml:"leverage"`
+	MarginType string         `yaml:"margin_type" toml:"margin_type"`
+}
+
+// PaperConfig is the paper wallet used in backtest and paper modes
+type PaperConfig struct {
+	// Base coin of the wallet, default: USDT
+	Base string `yaml:"base" toml:"base"`
+	// Assets are the initial balances, default: 10000 of the base coin
+	Assets   map[string]float64 `yaml:"assets" toml:"assets"`
+	MakerFee float64            `yaml:"maker_fee" toml:"maker_fee"`
+	TakerFee float64            `yaml:"taker_fee" toml:"taker_fee"`
+	// Slippage of market orders in basis points, eg: 5 = 0.05%
+	Slippage float64 `yaml:"slippage" toml:"slippage"`
+}
+
+type BacktestConfig struct {
+	Feeds []FeedConfig `yaml:"feeds" toml:"feeds"`
+	// Report and JSON are optional outputs of the backtest results, in HTML and JSON
+	Report   string `yaml:"report" toml:"report"`
+	JSON     string `yaml:"json" toml:"json"`
+	Progress *bool  `yaml:"progress" toml:"progress"`
+}
+
+type FeedConfig struct {
+	Pair string `yaml:"pair" toml:"pair"`
+	File string `yaml:"file" toml:"file"`
+	// Type of the file: candles (default) or trades
+	Type       string `yaml:"type" toml:"type"`
+	Timeframe  string `yaml:"timeframe" toml:"timeframe"`
+	HeikinAshi bool   `yaml:"heikin_ashi" toml:"heikin_ashi"`
+}
+
+type StorageConfig struct {
+	// Type of the storage: memory, file or sqlite.
+	// By default, backtests use memory and the other modes use the file ninjabot.db.
+	Type string `yaml:"type" toml:"type"`
+	Path string `yaml:"path" toml:"path"`
+}
+
+type NotifiersConfig struct {
+	Telegram TelegramConfig `yaml:"telegram" toml:"telegram"`
+}
+
+type TelegramConfig struct {
+	Token string `yaml:"token" toml:"token"`
+	Users []int  `yaml:"users" toml:"users"`
+}
+
+type ChartConfig struct {
+	// Port of the chart server, the chart is disabled if zero
+	Port int `yaml:"port" toml:"port"`
+}
+
+type StrategyConfig struct {
+	// Name of the strategy in the registry, see strategy.Register
+	Name string `yaml:"name" toml:"name"`
+	// File of a strategy with rules in YAML or a script in Starlark (.star), used instead of the registry,
+	// see rules.Load and script.Load
+	File string `yaml:"file" toml:"file"`
+	// ID identifies the strategy in orders and reports, default: the name of the strategy
+	ID     string          `yaml:"id" toml:"id"`
+	Params strategy.Params `yaml:"params" toml:"params"`
+	// Pairs of the strategy, default: the pairs of the config
+	Pairs []string `yaml:"pairs" toml:"pairs"`
+	// Capital is the budget of the strategy, zero shares the whole account balance
+	Capital float64 `yaml:"capital" toml:"capital"`
+}
+
+// Load reads a config file in YAML or TOML, by the file extension.
+// Environment variables are expanded, eg: api_key: ${BINANCE_API_KEY}.
+func Load(file string) (Config, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return Config{}, err
+	}
+	content = []byte(os.ExpandEnv(string(content)))
+
+	var config Config
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	default:
+		return Config{}, fmt.Errorf("%w: unsupported file extension %s", ErrInvalidConfig, filepath.Ext(file))
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("%w: %s", ErrInvalidConfig, err)
+	}
+
+	return config, nil
+}
+
+// Validate checks the config for a given mode
+func (c Config) Validate(mode Mode) error {
+	switch mode {
+	case ModeBacktest, ModePaper, ModeLive:
+	default:
+		return fmt.Errorf("%w: unknown mode %s", ErrInvalidConfig, mode)
+	}
+
+	if len(c.Strategies) == 0 {
+		return fmt.Errorf("%w: no strategy defined", ErrInvalidConfig)
+	}
+
+	for _, str := range c.Strategies {
+		if str.Name == "" && str.File == "" {
+			return fmt.Errorf("%w: strategy without name or file", ErrInvalidConfig)
+		}
+
+		if str.Name != "" && str.File != "" {
+			return fmt.Errorf("%w: strategy %s with name and file", ErrInvalidConfig, str.Name)
+		}
+
+		if len(str.Pairs) == 0 && len(c.Pairs) == 0 {
+			return fmt.Errorf("%w: no pairs defined for strategy %s%s", ErrInvalidConfig, str.Name, str.File)
+		}
+	}
+
+	switch c.Exchange.Name {
+	case "", ExchangeBinance, ExchangeBinanceFutures:
+	default:
+		return fmt.Errorf("%w: unknown exchange %s", ErrInvalidConfig, c.Exchange.Name)
+	}
+
+	switch c.Storage.Type {
+	case "", StorageMemory, StorageFile, StorageSQLite:
+	default:
+		return fmt.Errorf("%w: unknown storage %s", ErrInvalidConfig, c.Storage.Type)
+	}
+
+	if mode == ModeLive && (c.Exchange.APIKey == "" || c.Exchange.SecretKey == "") {
+		return fmt.Errorf("%w: exchange credentials are required in live mode", ErrInvalidConfig)
+	}
+
+	if mode == ModeBacktest {
+		if len(c.Backtest.Feeds) == 0 {
+			return fmt.Errorf("%w: no backtest feed defined", ErrInvalidConfig)
+		}
+
+		for _, feed := range c.Backtest.Feeds {
+			if feed.Type != c.Backtest.Feeds[0].Type {
+				return fmt.Errorf("%w: backtest feeds must have the same type", ErrInvalidConfig)
+			}
+
+			switch feed.Type {
+			case "", FeedCandles:
+				if feed.Timeframe == "" {
+					return fmt.Errorf("%w: timeframe is required for the feed of %s", ErrInvalidConfig, feed.Pair)
+				}
+			case FeedTrades:
+			default:
+				return fmt.Errorf("%w: unknown feed type %s", ErrInvalidConfig, feed.Type)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	expected := Config{
+		Pairs:    []string{"BTCUSDT", "ETHUSDT"},
+		LogLevel: "warn",
+		Exchange: ExchangeConfig{
+			Name:      ExchangeBinanceFutures,
+			APIKey:    "my-key",
+			SecretKey: "my-secret",
+			Leverage:  map[string]int{"BTCUSDT": 5},
+		},
+		Paper: PaperConfig{
+			Assets:   map[string]float64{"USDT": 1000},
+			MakerFee: 0.001,
+		},
+		Storage: StorageConfig{Type: StorageMemory},
+		Notifiers: NotifiersConfig{
+			Telegram: TelegramConfig{Token: "token", Users: []int{42}},
+		},
+		Strategies: []StrategyConfig{
+			{Name: "emacross", Params: strategy.Params{"ema": 9, "sma": 21.5}, Capital: 500},
+		},
+	}
+
+	t.Setenv("NINJABOT_TEST_KEY", "my-key")
+
+	t.Run("yaml", func(t *testing.T) {
+		file := writeConfig(t, "bot.yaml", `
+pairs: [BTCUSDT, ETHUSDT]
+log_level: warn
+exchange:
+  name: binance-futures
+  api_key: ${NINJABOT_TEST_KEY}
+  secret_key: my-secret
+  leverage:
+    BTCUSDT: 5
+paper:
+  assets:
+    USDT: 1000
+  maker_fee: 0.001
+storage:
+  type: memory
+notifiers:
+  telegram:
+    token: token
+    users: [42]
+strategies:
+  - name: emacross
+    capital: 500
+    params:
+      ema: 9
+      sma: 21.5
+`)
+		config, err := Load(file)
+		require.NoError(t, err)
+		require.Equal(t, expected, config)
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		file := writeConfig(t, "bot.toml", `
+pairs = ["BTCUSDT", "ETHUSDT"]
+log_level = "warn"
+
+[exchange]
+name = "binance-futures"
+api_key = "${NINJABOT_TEST_KEY}"
+secret_key = "my-secret"
+leverage = { BTCUSDT = 5 }
+
+[paper]
+assets = { USDT = 1000.0 }
+maker_fee = 0.001
+
+[storage]
+type = "memory"
+
+[notifiers.telegram]
+token = "token"
+users = [42]
+
+[[strategies]]
+name = "emacross"
+capital = 500.0
+params = { ema = 9, sma = 21.5 }
+`)
+		config, err := Load(file)
+		require.NoError(t, err)
+
+		// TOML integers are decoded as int64
+		require.Equal(t, int64(9), config.Strategies[0].Params["ema"])
+		config.Strategies[0].Params["ema"] = 9
+		require.Equal(t, expected, config)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := Load(writeConfig(t, "bot.yml", "pairs: [BTCUSDT]\npair: ETHUSDT\n"))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		_, err := Load(writeConfig(t, "bot.json", "{}"))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		_, err := Load("not-found.yaml")
+		require.Error(t, err)
+	})
+
+	t.Run("example", func(t *testing.T) {
+		config, err := Load("../examples/config/ninjabot.yaml")
+		require.NoError(t, err)
+		require.NoError(t, config.Validate(ModeBacktest))
+		require.Equal(t, "emacross", config.Strategies[0].Name)
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{
+		Pairs:      []string{"BTCUSDT"},
+		Exchange:   ExchangeConfig{APIKey: "key", SecretKey: "secret"},
+		Backtest:   BacktestConfig{Feeds: []FeedConfig{{Pair: "BTCUSDT", File: "btc.csv", Timeframe: "1h"}}},
+		Strategies: []StrategyConfig{{Name: "emacross"}},
+	}
+
+	for _, mode := range []Mode{ModeBacktest, ModePaper, ModeLive} {
+		require.NoError(t, valid.Validate(mode))
+	}
+
+	tt := []struct {
+		name   string
+		mode   Mode
+		update func(config *Config)
+	}{
+		{"unknown mode", "simulation", func(*Config) {}},
+		{"no strategy", ModePaper, func(config *Config) { config.Strategies = nil }},
+		{"strategy without name", ModePaper, func(config *Config) { config.Strategies[0].Name = "" }},
+		{"strategy with name and file", ModePaper, func(config *Config) { config.Strategies[0].File = "rules.yaml" }},
+		{"no pairs", ModePaper, func(config *Config) { config.Pairs = nil }},
+		{"unknown exchange", ModePaper, func(config *Config) { config.Exchange.Name = "ftx" }},
+		{"unknown storage", ModePaper, func(config *Config) { config.Storage.Type = "redis" }},
+		{"live without credentials", ModeLive, func(config *Config) { config.Exchange.SecretKey = "" }},
+		{"backtest without feeds", ModeBacktest, func(config *Config) { config.Backtest.Feeds = nil }},
+		{"feed without timeframe", ModeBacktest, func(config *Config) { config.Backtest.Feeds[0].Timeframe = "" }},
+		{"unknown feed type", ModeBacktest, func(config *Config) { config.Backtest.Feeds[0].Type = "book" }},
+		{"mixed feed types", ModeBacktest, func(config *Config) {
+			config.Backtest.Feeds = append(config.Backtest.Feeds, FeedConfig{Pair: "ETHUSDT", Type: FeedTrades})
+		}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			config := valid
+			config.Strategies = append([]StrategyConfig(nil), valid.Strategies...)
+			config.Backtest.Feeds = append([]FeedConfig(nil), valid.Backtest.Feeds...)
+			tc.update(&config)
+			require.ErrorIs(t, config.Validate(tc.mode), ErrInvalidConfig)
+		})
+	}
+}
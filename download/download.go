@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/csv"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
@@ -28,6 +29,9 @@ func NewDownloader(exchange service.Feeder) Downloader {
 type Parameters struct {
 	Start time.Time
 	End   time.Time
+
+	// Metadata are additional columns written from the candles metadata
+	Metadata []string
 }
 
 type Option func(*Parameters)
@@ -46,6 +50,14 @@ func WithDays(days int) Option {
 	}
 }
 
+// WithMetadata saves the values of the candles metadata as additional columns, eg: the futures market data
+// attached by exchange.WithBinanceFutureMarketData
+func WithMetadata(keys ...string) Option {
+	return func(parameters *Parameters) {
+		parameters.Metadata = append(parameters.Metadata, keys...)
+	}
+}
+
 func candlesCount(start, end time.Time, timeframe string) (int, time.Duration, error) {
 	totalDuration := end.Sub(start)
 	interval, err := str2duration.ParseDuration(timeframe)
@@ -97,9 +109,9 @@ func (d Downloader) Download(ctx context.Context, pair, timeframe string, output
 	isLastLoop := false
 
 	// write headers
-	err = writer.Write([]string{
+	err = writer.Write(append([]string{
 		"time", "open", "close", "low", "high", "volume",
-	})
+	}, parameters.Metadata...))
 	if err != nil {
 		return err
 	}
@@ -119,7 +131,12 @@ func (d Downloader) Download(ctx context.Context, pair, timeframe string, output
 		}
 
 		for _, candle := range candles {
-			err := writer.Write(candle.ToSlice(info.QuotePrecision))
+			row := candle.ToSlice(info.QuotePrecision)
+			for _, key := range parameters.Metadata {
+				row = append(row, strconv.FormatFloat(candle.Metadata[key], 'f', -1, 64))
+			}
+
+			err := writer.Write(row)
 			if err != nil {
 				return err
 			}
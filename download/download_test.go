@@ -3,10 +3,12 @@ package download
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
 	"github.com/rodrigo-brito/ninjabot/service"
 
 	"github.com/stretchr/testify/assert"
@@ -98,3 +100,43 @@ func TestDownloader_download(t *testing.T) {
 		require.Len(t, csvFeed.CandlePairTimeFrame["BTCUSDT--1d"], 14)
 	})
 }
+
+type metadataFeeder struct {
+	service.Feeder
+}
+
+func (m metadataFeeder) CandlesByPeriod(ctx context.Context, pair, period string,
+	start, end time.Time) ([]model.Candle, error) {
+	candles, err := m.Feeder.CandlesByPeriod(ctx, pair, period, start, end)
+	for i := range candles {
+		candles[i].Metadata = map[string]float64{exchange.MetadataFundingRate: 0.0001}
+	}
+	return candles, err
+}
+
+func TestDownloader_WithMetadata(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "btc.csv")
+	csvFeed, err := exchange.NewCSVFeed("1d", exchange.PairFeed{
+		Pair:      "BTCUSDT",
+		File:      "../testdata/btc-1d.csv",
+		Timeframe: "1d",
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2021, 4, 26, 0, 0, 0, 0, time.UTC)
+	downloader := NewDownloader(metadataFeeder{csvFeed})
+	err = downloader.Download(context.Background(), "BTCUSDT", "1d", output,
+		WithInterval(start, start.AddDate(0, 0, 5)), WithMetadata(exchange.MetadataFundingRate))
+	require.NoError(t, err)
+
+	// metadata columns are loaded by the CSV feed
+	feed, err := exchange.NewCSVFeed("1d", exchange.PairFeed{
+		Pair:      "BTCUSDT",
+		File:      output,
+		Timeframe: "1d",
+	})
+	require.NoError(t, err)
+	candles := feed.CandlePairTimeFrame["BTCUSDT--1d"]
+	require.NotEmpty(t, candles)
+	require.Equal(t, 0.0001, candles[0].Metadata[exchange.MetadataFundingRate])
+}
@@ -0,0 +1,178 @@
+package download
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+var ErrFeedNotSupported = errors.New("feed not supported by the exchange")
+
+// TradeHeader is the header of the trades files, with the time in milliseconds
+var TradeHeader = []string{"time", "id", "price", "quantity", "buyer_maker"}
+
+// OrderBookHeader is the header of the order book files, with one row for each level of the snapshots and diffs.
+// The time is in milliseconds and the type is "snapshot" or "diff".
+var OrderBookHeader = []string{"time", "first_update_id", "update_id", "type", "side", "price", "quantity"}
+
+// Recorder writes the real-time trades and order book updates of an exchange to CSV files
+type Recorder struct {
+	exchange service.Feeder
+}
+
+func NewRecorder(exchange service.Feeder) Recorder {
+	return Recorder{
+		exchange: exchange,
+	}
+}
+
+// RecordTrades writes the aggregated trades of a pair until the context is canceled
+func (r Recorder) RecordTrades(ctx context.Context, pair, output string) error {
+	feeder, ok := r.exchange.(service.TradeFeeder)
+	if !ok {
+		return ErrFeedNotSupported
+	}
+
+	recordFile, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer recordFile.Close()
+
+	writer := csv.NewWriter(recordFile)
+	if err := writer.Write(TradeHeader); err != nil {
+		return err
+	}
+
+	log.Infof("Recording trades of %s", pair)
+	ctrade, cerr := feeder.TradeSubscription(ctx, pair)
+	for {
+		select {
+		case trade, ok := <-ctrade:
+			if !ok {
+				writer.Flush()
+				return writer.Error()
+			}
+
+			err := writer.Write([]string{
+				strconv.FormatInt(trade.Time.UnixMilli(), 10),
+				strconv.FormatInt(trade.ID, 10),
+				strconv.FormatFloat(trade.Price, 'f', -1, 64),
+				strconv.FormatFloat(trade.Quantity, 'f', -1, 64),
+				strconv.FormatBool(trade.BuyerMaker),
+			})
+			if err != nil {
+				return err
+			}
+			writer.Flush()
+		case err := <-cerr:
+			if err != nil {
+				log.Error("recorder/trades: ", err)
+			}
+		}
+	}
+}
+
+// RecordOrderBook writes the order book updates of a pair until the context is canceled. The file starts with
+// a snapshot limited to the given depth, followed by the diffs. A new snapshot is written when an update is
+// missing in the stream, eg: after a reconnection.
+func (r Recorder) RecordOrderBook(ctx context.Context, pair, output string, depth int) error {
+	feeder, ok := r.exchange.(service.OrderBookFeeder)
+	if !ok {
+		return ErrFeedNotSupported
+	}
+
+	recordFile, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer recordFile.Close()
+
+	writer := csv.NewWriter(recordFile)
+	if err := writer.Write(OrderBookHeader); err != nil {
+		return err
+	}
+
+	log.Infof("Recording order book of %s", pair)
+	var lastUpdateID int64
+	cbook, cerr := feeder.OrderBookSubscription(ctx, pair)
+	for {
+		select {
+		case diff, ok := <-cbook:
+			if !ok {
+				writer.Flush()
+				return writer.Error()
+			}
+
+			if lastUpdateID == 0 || diff.FirstUpdateID > lastUpdateID+1 {
+				snapshot, err := feeder.OrderBook(ctx, pair, depth)
+				if err != nil {
+					return err
+				}
+
+				if err := writeOrderBook(writer, snapshot); err != nil {
+					return err
+				}
+				lastUpdateID = snapshot.UpdateID
+			}
+
+			// diffs included in the snapshot
+			if diff.UpdateID <= lastUpdateID {
+				continue
+			}
+
+			if err := writeOrderBook(writer, diff); err != nil {
+				return err
+			}
+			lastUpdateID = diff.UpdateID
+			writer.Flush()
+		case err := <-cerr:
+			if err != nil {
+				log.Error("recorder/orderBook: ", err)
+			}
+		}
+	}
+}
+
+func writeOrderBook(writer *csv.Writer, book model.OrderBook) error {
+	bookType := "diff"
+	if book.Snapshot {
+		bookType = "snapshot"
+	}
+
+	prefix := []string{
+		strconv.FormatInt(book.Time.UnixMilli(), 10),
+		strconv.FormatInt(book.FirstUpdateID, 10),
+		strconv.FormatInt(book.UpdateID, 10),
+		bookType,
+	}
+
+	sides := []struct {
+		name   string
+		levels []model.PriceLevel
+	}{
+		{name: "bid", levels: book.Bids},
+		{name: "ask", levels: book.Asks},
+	}
+
+	for _, side := range sides {
+		for _, level := range side.levels {
+			err := writer.Write(append(prefix[:len(prefix):len(prefix)],
+				side.name,
+				strconv.FormatFloat(level.Price, 'f', -1, 64),
+				strconv.FormatFloat(level.Quantity, 'f', -1, 64),
+			))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
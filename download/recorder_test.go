@@ -0,0 +1,93 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/testdata/mocks"
+)
+
+type recorderFeeder struct {
+	*mocks.Feeder
+	*mocks.OrderBookFeeder
+	*mocks.TradeFeeder
+}
+
+func TestRecorder_RecordTrades(t *testing.T) {
+	t.Run("not supported", func(t *testing.T) {
+		recorder := NewRecorder(mocks.NewFeeder(t))
+		err := recorder.RecordTrades(context.Background(), "BTCUSDT", filepath.Join(t.TempDir(), "trades.csv"))
+		require.ErrorIs(t, err, ErrFeedNotSupported)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		feeder := mocks.NewTradeFeeder(t)
+		ctrade := make(chan model.TradeTick, 2)
+		feeder.EXPECT().TradeSubscription(mock.Anything, "BTCUSDT").Return(ctrade, make(chan error))
+
+		ctrade <- model.TradeTick{ID: 1, Time: time.UnixMilli(1672531200123), Price: 16500.5, Quantity: 0.25}
+		ctrade <- model.TradeTick{ID: 2, Time: time.UnixMilli(1672531200456), Price: 16500, Quantity: 1,
+			BuyerMaker: true}
+		close(ctrade)
+
+		output := filepath.Join(t.TempDir(), "trades.csv")
+		recorder := NewRecorder(recorderFeeder{Feeder: mocks.NewFeeder(t), TradeFeeder: feeder})
+		require.NoError(t, recorder.RecordTrades(context.Background(), "BTCUSDT", output))
+
+		content, err := os.ReadFile(output)
+		require.NoError(t, err)
+		require.Equal(t, "time,id,price,quantity,buyer_maker\n"+
+			"1672531200123,1,16500.5,0.25,false\n"+
+			"1672531200456,2,16500,1,true\n", string(content))
+	})
+}
+
+func TestRecorder_RecordOrderBook(t *testing.T) {
+	feeder := mocks.NewOrderBookFeeder(t)
+	cbook := make(chan model.OrderBook, 4)
+	feeder.EXPECT().OrderBookSubscription(mock.Anything, "BTCUSDT").Return(cbook, make(chan error))
+	feeder.EXPECT().OrderBook(mock.Anything, "BTCUSDT", 5).Return(model.OrderBook{
+		Time:          time.UnixMilli(1000),
+		FirstUpdateID: 10,
+		UpdateID:      10,
+		Snapshot:      true,
+		Bids:          []model.PriceLevel{{Price: 99, Quantity: 1}},
+		Asks:          []model.PriceLevel{{Price: 101, Quantity: 2}},
+	}, nil).Once()
+	feeder.EXPECT().OrderBook(mock.Anything, "BTCUSDT", 5).Return(model.OrderBook{
+		Time:          time.UnixMilli(4000),
+		FirstUpdateID: 20,
+		UpdateID:      20,
+		Snapshot:      true,
+		Bids:          []model.PriceLevel{{Price: 98, Quantity: 1}},
+	}, nil).Once()
+
+	cbook <- model.OrderBook{Time: time.UnixMilli(2000), FirstUpdateID: 9, UpdateID: 10,
+		Bids: []model.PriceLevel{{Price: 99, Quantity: 1}}}
+	cbook <- model.OrderBook{Time: time.UnixMilli(3000), FirstUpdateID: 11, UpdateID: 12,
+		Bids: []model.PriceLevel{{Price: 99, Quantity: 0}}, Asks: []model.PriceLevel{{Price: 100, Quantity: 3}}}
+	cbook <- model.OrderBook{Time: time.UnixMilli(5000), FirstUpdateID: 18, UpdateID: 21,
+		Asks: []model.PriceLevel{{Price: 99, Quantity: 1}}}
+	close(cbook)
+
+	output := filepath.Join(t.TempDir(), "orderbook.csv")
+	recorder := NewRecorder(recorderFeeder{Feeder: mocks.NewFeeder(t), OrderBookFeeder: feeder})
+	require.NoError(t, recorder.RecordOrderBook(context.Background(), "BTCUSDT", output, 5))
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	require.Equal(t, "time,first_update_id,update_id,type,side,price,quantity\n"+
+		"1000,10,10,snapshot,bid,99,1\n"+
+		"1000,10,10,snapshot,ask,101,2\n"+
+		"3000,11,12,diff,bid,99,0\n"+
+		"3000,11,12,diff,ask,100,3\n"+
+		"4000,20,20,snapshot,bid,98,1\n"+
+		"5000,18,21,diff,ask,99,1\n", string(content))
+}
@@ -99,6 +99,17 @@ func main() {
 	// Print bot results
 	bot.Summary()
 
+	// Export results to JSON and to a standalone HTML page with the chart,
+	// the page inlines the plotly build generated with `go generate ./plot`
+	report := bot.Report()
+	if err := report.SaveJSON("report.json"); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := chart.SaveHTML("report.html", report); err != nil {
+		log.Fatal(err)
+	}
+
 	// Display candlesticks chart in local browser
 	err = chart.Start()
 	if err != nil {
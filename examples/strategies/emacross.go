@@ -1,6 +1,8 @@
 package strategies
 
 import (
+	"fmt"
+
 	"github.com/rodrigo-brito/ninjabot"
 	"github.com/rodrigo-brito/ninjabot/indicator"
 	"github.com/rodrigo-brito/ninjabot/service"
@@ -8,19 +10,58 @@ import (
 	"github.com/rodrigo-brito/ninjabot/tools/log"
 )
 
-type CrossEMA struct{}
+// CrossEMA buys when the EMA crosses over the SMA and sells when it crosses under.
+// Zero periods use the default values: EMA 8 and SMA 21.
+type CrossEMA struct {
+	EMAPeriod int
+	SMAPeriod int
+}
+
+func (e CrossEMA) periods() (int, int) {
+	emaPeriod, smaPeriod := e.EMAPeriod, e.SMAPeriod
+	if emaPeriod <= 0 {
+		emaPeriod = 8
+	}
+	if smaPeriod <= 0 {
+		smaPeriod = 21
+	}
+	return emaPeriod, smaPeriod
+}
+
+func (e CrossEMA) Parameters() []strategy.Param {
+	return []strategy.Param{
+		{Name: "ema", Type: strategy.ParamInt, Default: 8, Min: 2, Max: 50, Step: 1, Description: "EMA period"},
+		{Name: "sma", Type: strategy.ParamInt, Default: 21, Min: 2, Max: 200, Step: 1, Description: "SMA period"},
+	}
+}
+
+func (e CrossEMA) ParamValues() strategy.Params {
+	emaPeriod, smaPeriod := e.periods()
+	return strategy.Params{"ema": emaPeriod, "sma": smaPeriod}
+}
+
+func (e *CrossEMA) SetParams(params strategy.Params) error {
+	e.EMAPeriod = params.Int("ema", e.EMAPeriod)
+	e.SMAPeriod = params.Int("sma", e.SMAPeriod)
+	return nil
+}
 
 func (e CrossEMA) Timeframe() string {
 	return "4h"
 }
 
 func (e CrossEMA) WarmupPeriod() int {
-	return 22
+	emaPeriod, smaPeriod := e.periods()
+	if emaPeriod > smaPeriod {
+		return emaPeriod + 1
+	}
+	return smaPeriod + 1
 }
 
 func (e CrossEMA) Indicators(df *ninjabot.Dataframe) []strategy.ChartIndicator {
-	df.Metadata["ema8"] = indicator.EMA(df.Close, 8)
-	df.Metadata["sma21"] = indicator.SMA(df.Close, 21)
+	emaPeriod, smaPeriod := e.periods()
+	df.Metadata["ema"] = indicator.EMA(df.Close, emaPeriod)
+	df.Metadata["sma"] = indicator.SMA(df.Close, smaPeriod)
 
 	return []strategy.ChartIndicator{
 		{
@@ -29,14 +70,14 @@ func (e CrossEMA) Indicators(df *ninjabot.Dataframe) []strategy.ChartIndicator {
 			Time:      df.Time,
 			Metrics: []strategy.IndicatorMetric{
 				{
-					Values: df.Metadata["ema8"],
-					Name:   "EMA 8",
+					Values: df.Metadata["ema"],
+					Name:   fmt.Sprintf("EMA %d", emaPeriod),
 					Color:  "red",
 					Style:  strategy.StyleLine,
 				},
 				{
-					Values: df.Metadata["sma21"],
-					Name:   "SMA 21",
+					Values: df.Metadata["sma"],
+					Name:   fmt.Sprintf("SMA %d", smaPeriod),
 					Color:  "blue",
 					Style:  strategy.StyleLine,
 				},
@@ -55,7 +96,7 @@ func (e *CrossEMA) OnCandle(df *ninjabot.Dataframe, broker service.Broker) {
 	}
 
 	if quotePosition >= 10 && // minimum quote position to trade
-		df.Metadata["ema8"].Crossover(df.Metadata["sma21"]) { // trade signal (EMA8 > SMA21)
+		df.Metadata["ema"].Crossover(df.Metadata["sma"]) { // trade signal (EMA > SMA)
 
 		amount := quotePosition / closePrice // calculate amount of asset to buy
 		_, err := broker.CreateOrderMarket(ninjabot.SideTypeBuy, df.Pair, amount)
@@ -67,7 +108,7 @@ func (e *CrossEMA) OnCandle(df *ninjabot.Dataframe, broker service.Broker) {
 	}
 
 	if assetPosition > 0 &&
-		df.Metadata["ema8"].Crossunder(df.Metadata["sma21"]) { // trade signal (EMA8 < SMA21)
+		df.Metadata["ema"].Crossunder(df.Metadata["sma"]) { // trade signal (EMA < SMA)
 
 		_, err = broker.CreateOrderMarket(ninjabot.SideTypeSell, df.Pair, assetPosition)
 		if err != nil {
@@ -6,27 +6,15 @@ import (
 	"github.com/rodrigo-brito/ninjabot/model"
 	"github.com/rodrigo-brito/ninjabot/service"
 	"github.com/rodrigo-brito/ninjabot/strategy"
-	"github.com/rodrigo-brito/ninjabot/tools"
 	"github.com/rodrigo-brito/ninjabot/tools/log"
 )
 
-type trailing struct {
-	trailingStop map[string]*tools.TrailingStop
-	scheduler    map[string]*tools.Scheduler
-}
-
-func NewTrailing(pairs []string) strategy.HighFrequencyStrategy {
-	strategy := &trailing{
-		trailingStop: make(map[string]*tools.TrailingStop),
-		scheduler:    make(map[string]*tools.Scheduler),
-	}
+// trailing strategy uses a trailing stop managed by the order controller, that follows the price
+// of partial candles and is persisted between restarts
+type trailing struct{}
 
-	for _, pair := range pairs {
-		strategy.trailingStop[pair] = tools.NewTrailingStop()
-		strategy.scheduler[pair] = tools.NewScheduler(pair)
-	}
-
-	return strategy
+func NewTrailing() strategy.Strategy {
+	return &trailing{}
 }
 
 func (t trailing) Timeframe() string {
@@ -54,33 +42,23 @@ func (t trailing) OnCandle(df *model.Dataframe, broker service.Broker) {
 	if quote > 10.0 && // enough cash?
 		asset*df.Close.Last(0) < 10 && // without position yet
 		df.Metadata["ema_fast"].Crossover(df.Metadata["sma_slow"]) {
-		_, err = broker.CreateOrderMarketQuote(ninjabot.SideTypeBuy, df.Pair, quote)
+		order, err := broker.CreateOrderMarketQuote(ninjabot.SideTypeBuy, df.Pair, quote)
 		if err != nil {
 			log.Error(err)
 			return
 		}
 
-		t.trailingStop[df.Pair].Start(df.Close.Last(0), df.Low.Last(0))
-
-		return
-	}
-}
-
-func (t trailing) OnPartialCandle(df *model.Dataframe, broker service.Broker) {
-	if trailing := t.trailingStop[df.Pair]; trailing != nil && trailing.Update(df.Close.Last(0)) {
-		asset, _, err := broker.Position(df.Pair)
-		if err != nil {
-			log.Error(err)
+		trailer, ok := broker.(service.TrailingStopBroker)
+		if !ok {
+			log.Error("broker without trailing stops")
 			return
 		}
 
-		if asset > 0 {
-			_, err = broker.CreateOrderMarket(ninjabot.SideTypeSell, df.Pair, asset)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-			trailing.Stop()
+		// initial stop in the low of the candle
+		_, err = trailer.CreateTrailingStop(ninjabot.SideTypeSell, df.Pair, order.Quantity,
+			df.Close.Last(0)-df.Low.Last(0))
+		if err != nil {
+			log.Error(err)
 		}
 	}
 }
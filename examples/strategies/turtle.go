@@ -9,27 +9,67 @@ import (
 )
 
 // https://www.investopedia.com/articles/trading/08/turtle-trading.asp
-type Turtle struct{}
+// Zero periods use the default values: entry 40 and exit 20.
+type Turtle struct {
+	EntryPeriod int
+	ExitPeriod  int
+}
+
+func (e Turtle) periods() (int, int) {
+	entryPeriod, exitPeriod := e.EntryPeriod, e.ExitPeriod
+	if entryPeriod <= 0 {
+		entryPeriod = 40
+	}
+	if exitPeriod <= 0 {
+		exitPeriod = 20
+	}
+	return entryPeriod, exitPeriod
+}
+
+func (e Turtle) Parameters() []strategy.Param {
+	return []strategy.Param{
+		{Name: "entry", Type: strategy.ParamInt, Default: 40, Min: 5, Max: 100, Step: 5,
+			Description: "breakout period of the entry"},
+		{Name: "exit", Type: strategy.ParamInt, Default: 20, Min: 5, Max: 100, Step: 5,
+			Description: "breakout period of the exit"},
+	}
+}
+
+func (e Turtle) ParamValues() strategy.Params {
+	entryPeriod, exitPeriod := e.periods()
+	return strategy.Params{"entry": entryPeriod, "exit": exitPeriod}
+}
+
+func (e *Turtle) SetParams(params strategy.Params) error {
+	e.EntryPeriod = params.Int("entry", e.EntryPeriod)
+	e.ExitPeriod = params.Int("exit", e.ExitPeriod)
+	return nil
+}
 
 func (e Turtle) Timeframe() string {
 	return "4h"
 }
 
 func (e Turtle) WarmupPeriod() int {
-	return 40
+	entryPeriod, exitPeriod := e.periods()
+	if exitPeriod > entryPeriod {
+		return exitPeriod
+	}
+	return entryPeriod
 }
 
 func (e Turtle) Indicators(df *ninjabot.Dataframe) []strategy.ChartIndicator {
-	df.Metadata["max40"] = indicator.Max(df.Close, 40)
-	df.Metadata["low20"] = indicator.Min(df.Close, 20)
+	entryPeriod, exitPeriod := e.periods()
+	df.Metadata["max"] = indicator.Max(df.Close, entryPeriod)
+	df.Metadata["min"] = indicator.Min(df.Close, exitPeriod)
 
 	return nil
 }
 
 func (e *Turtle) OnCandle(df *ninjabot.Dataframe, broker service.Broker) {
 	closePrice := df.Close.Last(0)
-	highest := df.Metadata["max40"].Last(0)
-	lowest := df.Metadata["low20"].Last(0)
+	highest := df.Metadata["max"].Last(0)
+	lowest := df.Metadata["min"].Last(0)
 
 	assetPosition, quotePosition, err := broker.Position(df.Pair)
 	if err != nil {
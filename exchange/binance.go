@@ -16,6 +16,12 @@ import (
 
 type MetadataFetchers func(pair string, t time.Time) (string, float64)
 
+// userStreamKeepalive is the interval to extend the validity of the user data stream (listen key expires in 60m)
+const userStreamKeepalive = 30 * time.Minute
+
+// errCodeOrderNotFound is the code of API errors for orders that do not exist, in spot and futures
+const errCodeOrderNotFound int64 = -2013
+
 type Binance struct {
 	ctx        context.Context
 	client     *binance.Client
@@ -418,6 +424,9 @@ func (b *Binance) Order(pair string, id int64) (model.Order, error) {
 		Do(b.ctx)
 
 	if err != nil {
+		if apiError, ok := err.(*common.APIError); ok && apiError.Code == errCodeOrderNotFound {
+			return model.Order{}, fmt.Errorf("%w: %s", ErrOrderNotFound, apiError.Message)
+		}
 		return model.Order{}, err
 	}
 
@@ -428,6 +437,7 @@ func newOrder(order *binance.Order) model.Order {
 	var price float64
 	cost, _ := strconv.ParseFloat(order.CummulativeQuoteQuantity, 64)
 	quantity, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	executed := quantity
 	if cost > 0 && quantity > 0 {
 		price = cost / quantity
 	} else {
@@ -445,6 +455,123 @@ func newOrder(order *binance.Order) model.Order {
 		Status:     model.OrderStatusType(order.Status),
 		Price:      price,
 		Quantity:   quantity,
+
+		ExecutedQuantity: executed,
+	}
+}
+
+// newOrderFromUpdate converts an execution report of the user data stream to an order
+func newOrderFromUpdate(update binance.WsOrderUpdate) model.Order {
+	var price float64
+	cost, _ := strconv.ParseFloat(update.FilledQuoteVolume, 64)
+	quantity, _ := strconv.ParseFloat(update.FilledVolume, 64)
+	executed := quantity
+	if cost > 0 && quantity > 0 {
+		price = cost / quantity
+	} else {
+		price, _ = strconv.ParseFloat(update.Price, 64)
+		quantity, _ = strconv.ParseFloat(update.Volume, 64)
+	}
+
+	return model.Order{
+		ExchangeID: update.Id,
+		Pair:       update.Symbol,
+		CreatedAt:  time.Unix(0, update.CreateTime*int64(time.Millisecond)),
+		UpdatedAt:  time.Unix(0, update.TransactionTime*int64(time.Millisecond)),
+		Side:       model.SideType(update.Side),
+		Type:       model.OrderType(update.Type),
+		Status:     model.OrderStatusType(update.Status),
+		Price:      price,
+		Quantity:   quantity,
+
+		ExecutedQuantity: executed,
+	}
+}
+
+// OrderSubscription streams the execution reports of the account orders, using the user data stream
+func (b *Binance) OrderSubscription(ctx context.Context) (chan model.Order, chan error) {
+	corder := make(chan model.Order)
+	cerr := make(chan error)
+
+	go func() {
+		ba := &backoff.Backoff{
+			Min: 100 * time.Millisecond,
+			Max: 1 * time.Second,
+		}
+
+		for {
+			listenKey, err := b.client.NewStartUserStreamService().Do(ctx)
+			if err != nil {
+				select {
+				case cerr <- err:
+				case <-ctx.Done():
+				}
+				close(cerr)
+				close(corder)
+				return
+			}
+
+			done, stop, err := binance.WsUserDataServe(listenKey, func(event *binance.WsUserDataEvent) {
+				ba.Reset()
+				if event.Event == binance.UserDataEventTypeExecutionReport {
+					select {
+					case corder <- newOrderFromUpdate(event.OrderUpdate):
+					case <-ctx.Done():
+					}
+				}
+			}, func(err error) {
+				select {
+				case cerr <- err:
+				case <-ctx.Done():
+				}
+			})
+			if err != nil {
+				select {
+				case cerr <- err:
+				case <-ctx.Done():
+				}
+				close(cerr)
+				close(corder)
+				return
+			}
+
+			if finished := b.keepUserStream(ctx, listenKey, done, stop, cerr); finished {
+				close(cerr)
+				close(corder)
+				return
+			}
+			time.Sleep(ba.Duration())
+		}
+	}()
+
+	return corder, cerr
+}
+
+// keepUserStream extends the listen key until the stream is done or the context is canceled.
+// It returns true if the context was canceled.
+func (b *Binance) keepUserStream(ctx context.Context, listenKey string, done, stop chan struct{},
+	cerr chan error) bool {
+	ticker := time.NewTicker(userStreamKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the handlers must finish before the channels are closed
+			close(stop)
+			<-done
+			return true
+		case <-done:
+			return false
+		case <-ticker.C:
+			err := b.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx)
+			if err != nil {
+				select {
+				case cerr <- err:
+				case <-ctx.Done():
+				}
+			}
+		}
 	}
 }
 
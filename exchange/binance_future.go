@@ -43,6 +43,10 @@ type BinanceFuture struct {
 
 	MetadataFetchers []MetadataFetchers
 	PairOptions      []PairOption
+
+	// MarketData attaches funding rate, mark price and open interest to the candles
+	MarketData bool
+	market     marketData
 }
 
 type BinanceFutureOption func(*BinanceFuture)
@@ -168,9 +172,11 @@ func (b *BinanceFuture) validate(pair string, quantity float64) error {
 	return nil
 }
 
+// CreateOrderOCO is not supported by Binance Futures, bracket orders of the order controller
+// emulate the OCO behavior with independent orders
 func (b *BinanceFuture) CreateOrderOCO(_ model.SideType, _ string,
 	_, _, _, _ float64) ([]model.Order, error) {
-	panic("not implemented")
+	return nil, ErrOCONotSupported
 }
 
 func (b *BinanceFuture) CreateOrderStop(pair string, quantity float64, limit float64) (model.Order, error) {
@@ -339,6 +345,9 @@ func (b *BinanceFuture) Order(pair string, id int64) (model.Order, error) {
 		Do(b.ctx)
 
 	if err != nil {
+		if apiError, ok := err.(*common.APIError); ok && apiError.Code == errCodeOrderNotFound {
+			return model.Order{}, fmt.Errorf("%w: %s", ErrOrderNotFound, apiError.Message)
+		}
 		return model.Order{}, err
 	}
 
@@ -352,6 +361,7 @@ func newFutureOrder(order *futures.Order) model.Order {
 	)
 	cost, _ := strconv.ParseFloat(order.CumQuote, 64)
 	quantity, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	executed := quantity
 	if cost > 0 && quantity > 0 {
 		price = cost / quantity
 	} else {
@@ -371,6 +381,119 @@ func newFutureOrder(order *futures.Order) model.Order {
 		Status:     model.OrderStatusType(order.Status),
 		Price:      price,
 		Quantity:   quantity,
+
+		ExecutedQuantity: executed,
+	}
+}
+
+// newFutureOrderFromUpdate converts an order update of the user data stream to an order
+func newFutureOrderFromUpdate(update futures.WsOrderTradeUpdate) model.Order {
+	price, _ := strconv.ParseFloat(update.AveragePrice, 64)
+	quantity, _ := strconv.ParseFloat(update.AccumulatedFilledQty, 64)
+	executed := quantity
+	if price == 0 || quantity == 0 {
+		price, _ = strconv.ParseFloat(update.OriginalPrice, 64)
+		quantity, _ = strconv.ParseFloat(update.OriginalQty, 64)
+	}
+
+	return model.Order{
+		ExchangeID: update.ID,
+		Pair:       update.Symbol,
+		UpdatedAt:  time.Unix(0, update.TradeTime*int64(time.Millisecond)),
+		Side:       model.SideType(update.Side),
+		Type:       model.OrderType(update.Type),
+		Status:     model.OrderStatusType(update.Status),
+		Price:      price,
+		Quantity:   quantity,
+
+		ExecutedQuantity: executed,
+	}
+}
+
+// OrderSubscription streams the order updates of the account, using the user data stream
+func (b *BinanceFuture) OrderSubscription(ctx context.Context) (chan model.Order, chan error) {
+	corder := make(chan model.Order)
+	cerr := make(chan error)
+
+	go func() {
+		ba := &backoff.Backoff{
+			Min: 100 * time.Millisecond,
+			Max: 1 * time.Second,
+		}
+
+		for {
+			listenKey, err := b.client.NewStartUserStreamService().Do(ctx)
+			if err != nil {
+				select {
+				case cerr <- err:
+				case <-ctx.Done():
+				}
+				close(cerr)
+				close(corder)
+				return
+			}
+
+			done, stop, err := futures.WsUserDataServe(listenKey, func(event *futures.WsUserDataEvent) {
+				ba.Reset()
+				if event.Event == futures.UserDataEventTypeOrderTradeUpdate {
+					select {
+					case corder <- newFutureOrderFromUpdate(event.OrderTradeUpdate):
+					case <-ctx.Done():
+					}
+				}
+			}, func(err error) {
+				select {
+				case cerr <- err:
+				case <-ctx.Done():
+				}
+			})
+			if err != nil {
+				select {
+				case cerr <- err:
+				case <-ctx.Done():
+				}
+				close(cerr)
+				close(corder)
+				return
+			}
+
+			if finished := b.keepUserStream(ctx, listenKey, done, stop, cerr); finished {
+				close(cerr)
+				close(corder)
+				return
+			}
+			time.Sleep(ba.Duration())
+		}
+	}()
+
+	return corder, cerr
+}
+
+// keepUserStream extends the listen key until the stream is done or the context is canceled.
+// It returns true if the context was canceled.
+func (b *BinanceFuture) keepUserStream(ctx context.Context, listenKey string, done, stop chan struct{},
+	cerr chan error) bool {
+	ticker := time.NewTicker(userStreamKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the handlers must finish before the channels are closed
+			close(stop)
+			<-done
+			return true
+		case <-done:
+			return false
+		case <-ticker.C:
+			err := b.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx)
+			if err != nil {
+				select {
+				case cerr <- err:
+				case <-ctx.Done():
+				}
+			}
+		}
 	}
 }
 
@@ -447,6 +570,10 @@ func (b *BinanceFuture) CandlesSubscription(ctx context.Context, pair, period st
 	cerr := make(chan error)
 	ha := model.NewHeikinAshi()
 
+	if b.MarketData {
+		b.subscribeMarketData(ctx, pair)
+	}
+
 	go func() {
 		ba := &backoff.Backoff{
 			Min: 100 * time.Millisecond,
@@ -468,6 +595,16 @@ func (b *BinanceFuture) CandlesSubscription(ctx context.Context, pair, period st
 						key, value := fetcher(pair, candle.Time)
 						candle.Metadata[key] = value
 					}
+
+					if b.MarketData {
+						if err := b.refreshMarketData(ctx, pair); err != nil {
+							log.Warnf("market data of %s: %s", pair, err)
+						}
+					}
+				}
+
+				if b.MarketData {
+					b.setLastMarketData(&candle)
 				}
 
 				ccandle <- candle
@@ -521,7 +658,14 @@ func (b *BinanceFuture) CandlesByLimit(ctx context.Context, pair, period string,
 	}
 
 	// discard last candle, because it is incomplete
-	return candles[:len(candles)-1], nil
+	candles = candles[:len(candles)-1]
+	if b.MarketData {
+		if err := b.attachMarketData(ctx, pair, period, candles); err != nil {
+			return nil, err
+		}
+	}
+
+	return candles, nil
 }
 
 func (b *BinanceFuture) CandlesByPeriod(ctx context.Context, pair, period string,
@@ -551,6 +695,12 @@ func (b *BinanceFuture) CandlesByPeriod(ctx context.Context, pair, period string
 		candles = append(candles, candle)
 	}
 
+	if b.MarketData {
+		if err := b.attachMarketData(ctx, pair, period, candles); err != nil {
+			return nil, err
+		}
+	}
+
 	return candles, nil
 }
 
@@ -0,0 +1,309 @@
+package exchange
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/jpillora/backoff"
+	"github.com/xhit/go-str2duration/v2"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+// Metadata keys of the futures market data attached to the candles
+const (
+	MetadataFundingRate  = "funding_rate"
+	MetadataMarkPrice    = "mark_price"
+	MetadataOpenInterest = "open_interest"
+)
+
+// MarketDataKeys are the metadata keys of the futures market data
+var MarketDataKeys = []string{MetadataFundingRate, MetadataMarkPrice, MetadataOpenInterest}
+
+const (
+	markPriceLimit    = 1500
+	fundingRateLimit  = 1000
+	openInterestLimit = 500
+
+	// open interest history is only available for the last 30 days
+	openInterestHistory = 30 * 24 * time.Hour
+)
+
+// periods supported by the open interest history
+var openInterestPeriods = map[string]bool{
+	"5m": true, "15m": true, "30m": true, "1h": true, "2h": true, "4h": true, "6h": true, "12h": true, "1d": true,
+}
+
+type marketPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// marketData keeps the last market data of the pairs, updated by the subscriptions
+type marketData struct {
+	sync.Mutex
+	markPrice    map[string]float64
+	fundingRate  map[string]float64
+	openInterest map[string]float64
+}
+
+// WithBinanceFutureMarketData attaches the market data of the perpetual contracts to the candles metadata:
+// the last funding rate, the mark price and the open interest at the candle close. The values are available
+// in the strategies with Dataframe.Metadata, eg: df.Metadata["funding_rate"].
+func WithBinanceFutureMarketData() BinanceFutureOption {
+	return func(b *BinanceFuture) {
+		b.MarketData = true
+	}
+}
+
+// lastMarketValue returns the value of the last point until the given time, or zero if there is no point
+func lastMarketValue(points []marketPoint, t time.Time) float64 {
+	index := sort.Search(len(points), func(i int) bool {
+		return points[i].Time.After(t)
+	})
+	if index == 0 {
+		return 0
+	}
+	return points[index-1].Value
+}
+
+// setMarketData attaches the market data to the candles, with the values known at the close of each candle
+func setMarketData(candles []model.Candle, duration time.Duration, markPrices map[int64]float64, fundingRates,
+	openInterest []marketPoint) {
+	for i := range candles {
+		if candles[i].Metadata == nil {
+			candles[i].Metadata = make(map[string]float64)
+		}
+
+		closeTime := candles[i].Time.Add(duration - time.Millisecond)
+		candles[i].Metadata[MetadataMarkPrice] = markPrices[candles[i].Time.UnixMilli()]
+		candles[i].Metadata[MetadataFundingRate] = lastMarketValue(fundingRates, closeTime)
+		candles[i].Metadata[MetadataOpenInterest] = lastMarketValue(openInterest, closeTime)
+	}
+}
+
+// attachMarketData fetches the historical market data of the candles period and attaches it to the candles
+func (b *BinanceFuture) attachMarketData(ctx context.Context, pair, period string, candles []model.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	duration, err := str2duration.ParseDuration(period)
+	if err != nil {
+		return err
+	}
+
+	start := candles[0].Time
+	end := candles[len(candles)-1].Time.Add(duration)
+
+	markPrices, err := b.markPrices(ctx, pair, period, start, end, len(candles))
+	if err != nil {
+		return err
+	}
+
+	fundingRates, err := b.fundingRates(ctx, pair, start, end)
+	if err != nil {
+		return err
+	}
+
+	openInterest, err := b.openInterest(ctx, pair, period, start, end)
+	if err != nil {
+		return err
+	}
+
+	setMarketData(candles, duration, markPrices, fundingRates, openInterest)
+	return nil
+}
+
+// markPrices returns the close of the mark price klines, by open time in milliseconds
+func (b *BinanceFuture) markPrices(ctx context.Context, pair, period string, start, end time.Time,
+	limit int) (map[int64]float64, error) {
+	data, err := b.client.NewMarkPriceKlinesService().Symbol(pair).
+		Interval(period).
+		StartTime(start.UnixMilli()).
+		EndTime(end.UnixMilli()).
+		Limit(min(limit, markPriceLimit)).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[int64]float64, len(data))
+	for _, kline := range data {
+		prices[kline.OpenTime], err = strconv.ParseFloat(kline.Close, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return prices, nil
+}
+
+// fundingRates returns the funding rates settled in the period, including the last rate before the start
+func (b *BinanceFuture) fundingRates(ctx context.Context, pair string, start, end time.Time) ([]marketPoint, error) {
+	points := make([]marketPoint, 0)
+
+	// funding rates are settled at least once a day
+	begin := start.Add(-24 * time.Hour)
+	for begin.Before(end) {
+		data, err := b.client.NewFundingRateService().Symbol(pair).
+			StartTime(begin.UnixMilli()).
+			EndTime(end.UnixMilli()).
+			Limit(fundingRateLimit).
+			Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rate := range data {
+			value, err := strconv.ParseFloat(rate.FundingRate, 64)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, marketPoint{Time: time.UnixMilli(rate.FundingTime), Value: value})
+		}
+
+		if len(data) < fundingRateLimit {
+			break
+		}
+		begin = time.UnixMilli(data[len(data)-1].FundingTime + 1)
+	}
+
+	return points, nil
+}
+
+// openInterest returns the open interest history of the period. The history is limited to the last 30 days
+// and to some periods, the candles out of the history have an open interest equal to zero.
+func (b *BinanceFuture) openInterest(ctx context.Context, pair, period string, start,
+	end time.Time) ([]marketPoint, error) {
+	points := make([]marketPoint, 0)
+	if !openInterestPeriods[period] {
+		log.Warnf("open interest history not available for %s timeframe", period)
+		return points, nil
+	}
+
+	if oldest := time.Now().Add(-openInterestHistory); start.Before(oldest) {
+		start = oldest
+	}
+
+	for start.Before(end) {
+		data, err := b.client.NewOpenInterestStatisticsService().Symbol(pair).
+			Period(period).
+			StartTime(start.UnixMilli()).
+			EndTime(end.UnixMilli()).
+			Limit(openInterestLimit).
+			Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, statistic := range data {
+			value, err := strconv.ParseFloat(statistic.SumOpenInterest, 64)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, marketPoint{Time: time.UnixMilli(statistic.Timestamp), Value: value})
+		}
+
+		if len(data) < openInterestLimit {
+			break
+		}
+		start = time.UnixMilli(data[len(data)-1].Timestamp + 1)
+	}
+
+	return points, nil
+}
+
+// subscribeMarketData streams the mark price of a pair until the context is canceled
+func (b *BinanceFuture) subscribeMarketData(ctx context.Context, pair string) {
+	b.market.Lock()
+	if b.market.markPrice == nil {
+		b.market.markPrice = make(map[string]float64)
+		b.market.fundingRate = make(map[string]float64)
+		b.market.openInterest = make(map[string]float64)
+	}
+	b.market.Unlock()
+
+	if err := b.refreshMarketData(ctx, pair); err != nil {
+		log.Warnf("market data of %s: %s", pair, err)
+	}
+
+	go func() {
+		ba := &backoff.Backoff{
+			Min: 100 * time.Millisecond,
+			Max: 1 * time.Second,
+		}
+
+		for {
+			done, stop, err := futures.WsMarkPriceServe(pair, func(event *futures.WsMarkPriceEvent) {
+				ba.Reset()
+				price, err := strconv.ParseFloat(event.MarkPrice, 64)
+				if err != nil {
+					log.Warnf("mark price of %s: %s", pair, err)
+					return
+				}
+
+				b.market.Lock()
+				b.market.markPrice[pair] = price
+				b.market.Unlock()
+			}, func(err error) {
+				log.Warnf("mark price stream of %s: %s", pair, err)
+			})
+			if err != nil {
+				log.Errorf("mark price stream of %s: %s", pair, err)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				close(stop)
+				return
+			case <-done:
+				time.Sleep(ba.Duration())
+			}
+		}
+	}()
+}
+
+// refreshMarketData updates the last funding rate and the open interest of a pair
+func (b *BinanceFuture) refreshMarketData(ctx context.Context, pair string) error {
+	rates, err := b.client.NewFundingRateService().Symbol(pair).Limit(1).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	openInterest, err := b.client.NewGetOpenInterestService().Symbol(pair).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.market.Lock()
+	defer b.market.Unlock()
+
+	if len(rates) > 0 {
+		b.market.fundingRate[pair], err = strconv.ParseFloat(rates[0].FundingRate, 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	b.market.openInterest[pair], err = strconv.ParseFloat(openInterest.OpenInterest, 64)
+	return err
+}
+
+// setLastMarketData attaches the last market data of the subscriptions to a candle
+func (b *BinanceFuture) setLastMarketData(candle *model.Candle) {
+	b.market.Lock()
+	defer b.market.Unlock()
+
+	if candle.Metadata == nil {
+		candle.Metadata = make(map[string]float64)
+	}
+	candle.Metadata[MetadataMarkPrice] = b.market.markPrice[candle.Pair]
+	candle.Metadata[MetadataFundingRate] = b.market.fundingRate[candle.Pair]
+	candle.Metadata[MetadataOpenInterest] = b.market.openInterest[candle.Pair]
+}
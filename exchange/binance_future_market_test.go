@@ -0,0 +1,45 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+func TestSetMarketData(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := []model.Candle{
+		{Pair: "BTCUSDT", Time: start},
+		{Pair: "BTCUSDT", Time: start.Add(4 * time.Hour)},
+		{Pair: "BTCUSDT", Time: start.Add(8 * time.Hour), Metadata: map[string]float64{"custom": 1}},
+	}
+
+	markPrices := map[int64]float64{
+		start.UnixMilli():                    100,
+		start.Add(4 * time.Hour).UnixMilli(): 101,
+	}
+	fundingRates := []marketPoint{
+		{Time: start.Add(-8 * time.Hour), Value: 0.0001},
+		{Time: start.Add(8 * time.Hour), Value: -0.0002},
+	}
+	openInterest := []marketPoint{
+		{Time: start.Add(4 * time.Hour), Value: 10},
+		{Time: start.Add(8 * time.Hour), Value: 20},
+	}
+
+	setMarketData(candles, 4*time.Hour, markPrices, fundingRates, openInterest)
+
+	// values known at the candle close
+	require.Equal(t, map[string]float64{
+		MetadataMarkPrice: 100, MetadataFundingRate: 0.0001, MetadataOpenInterest: 0,
+	}, candles[0].Metadata)
+	require.Equal(t, map[string]float64{
+		MetadataMarkPrice: 101, MetadataFundingRate: 0.0001, MetadataOpenInterest: 10,
+	}, candles[1].Metadata)
+	require.Equal(t, map[string]float64{
+		"custom": 1, MetadataMarkPrice: 0, MetadataFundingRate: -0.0002, MetadataOpenInterest: 20,
+	}, candles[2].Metadata)
+}
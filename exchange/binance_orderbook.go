@@ -0,0 +1,208 @@
+package exchange
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/jpillora/backoff"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+// OrderBook returns a depth snapshot of a pair, limited to the given number of levels.
+// Binance supports the limits 5, 10, 20, 50, 100, 500, 1000 and 5000.
+func (b *Binance) OrderBook(ctx context.Context, pair string, depth int) (model.OrderBook, error) {
+	response, err := b.client.NewDepthService().Symbol(pair).Limit(depth).Do(ctx)
+	if err != nil {
+		return model.OrderBook{}, err
+	}
+
+	bids, err := newPriceLevels(response.Bids)
+	if err != nil {
+		return model.OrderBook{}, err
+	}
+
+	asks, err := newPriceLevels(response.Asks)
+	if err != nil {
+		return model.OrderBook{}, err
+	}
+
+	return model.OrderBook{
+		Pair:          pair,
+		Time:          time.Now(),
+		FirstUpdateID: response.LastUpdateID,
+		UpdateID:      response.LastUpdateID,
+		Snapshot:      true,
+		Bids:          bids,
+		Asks:          asks,
+	}, nil
+}
+
+// OrderBookSubscription streams the diffs of the order book of a pair, every 100ms.
+// The diffs must be applied to a snapshot with an update ID lower than the diff update ID.
+func (b *Binance) OrderBookSubscription(ctx context.Context, pair string) (chan model.OrderBook, chan error) {
+	cbook := make(chan model.OrderBook)
+	cerr := make(chan error)
+
+	go func() {
+		ba := &backoff.Backoff{
+			Min: 100 * time.Millisecond,
+			Max: 1 * time.Second,
+		}
+
+		for {
+			done, stop, err := binance.WsDepthServe100Ms(pair, func(event *binance.WsDepthEvent) {
+				ba.Reset()
+				book, err := newOrderBookFromDepthEvent(pair, event)
+				if err != nil {
+					cerr <- err
+					return
+				}
+				cbook <- book
+			}, func(err error) {
+				cerr <- err
+			})
+			if err != nil {
+				cerr <- err
+				close(cerr)
+				close(cbook)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				close(stop)
+				close(cerr)
+				close(cbook)
+				return
+			case <-done:
+				time.Sleep(ba.Duration())
+			}
+		}
+	}()
+
+	return cbook, cerr
+}
+
+// Trades returns the last aggregated trades of a pair
+func (b *Binance) Trades(ctx context.Context, pair string, limit int) ([]model.TradeTick, error) {
+	trades, err := b.client.NewAggTradesService().Symbol(pair).Limit(limit).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ticks := make([]model.TradeTick, 0, len(trades))
+	for _, trade := range trades {
+		tick, err := newTradeTick(pair, trade.AggTradeID, trade.Timestamp, trade.Price, trade.Quantity,
+			trade.IsBuyerMaker)
+		if err != nil {
+			return nil, err
+		}
+		ticks = append(ticks, tick)
+	}
+
+	return ticks, nil
+}
+
+// TradeSubscription streams the aggregated trades of a pair
+func (b *Binance) TradeSubscription(ctx context.Context, pair string) (chan model.TradeTick, chan error) {
+	ctrade := make(chan model.TradeTick)
+	cerr := make(chan error)
+
+	go func() {
+		ba := &backoff.Backoff{
+			Min: 100 * time.Millisecond,
+			Max: 1 * time.Second,
+		}
+
+		for {
+			done, stop, err := binance.WsAggTradeServe(pair, func(event *binance.WsAggTradeEvent) {
+				ba.Reset()
+				tick, err := newTradeTick(pair, event.AggTradeID, event.TradeTime, event.Price, event.Quantity,
+					event.IsBuyerMaker)
+				if err != nil {
+					cerr <- err
+					return
+				}
+				ctrade <- tick
+			}, func(err error) {
+				cerr <- err
+			})
+			if err != nil {
+				cerr <- err
+				close(cerr)
+				close(ctrade)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				close(stop)
+				close(cerr)
+				close(ctrade)
+				return
+			case <-done:
+				time.Sleep(ba.Duration())
+			}
+		}
+	}()
+
+	return ctrade, cerr
+}
+
+func newOrderBookFromDepthEvent(pair string, event *binance.WsDepthEvent) (model.OrderBook, error) {
+	bids, err := newPriceLevels(event.Bids)
+	if err != nil {
+		return model.OrderBook{}, err
+	}
+
+	asks, err := newPriceLevels(event.Asks)
+	if err != nil {
+		return model.OrderBook{}, err
+	}
+
+	return model.OrderBook{
+		Pair:          pair,
+		Time:          time.UnixMilli(event.Time),
+		FirstUpdateID: event.FirstUpdateID,
+		UpdateID:      event.LastUpdateID,
+		Bids:          bids,
+		Asks:          asks,
+	}, nil
+}
+
+func newPriceLevels(levels []binance.Bid) ([]model.PriceLevel, error) {
+	result := make([]model.PriceLevel, 0, len(levels))
+	for i := range levels {
+		price, quantity, err := levels[i].Parse()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, model.PriceLevel{Price: price, Quantity: quantity})
+	}
+	return result, nil
+}
+
+func newTradeTick(pair string, id, timestamp int64, price, quantity string, buyerMaker bool) (model.TradeTick, error) {
+	tick := model.TradeTick{
+		ID:         id,
+		Pair:       pair,
+		Time:       time.UnixMilli(timestamp),
+		BuyerMaker: buyerMaker,
+	}
+
+	var err error
+	tick.Price, err = strconv.ParseFloat(price, 64)
+	if err != nil {
+		return model.TradeTick{}, err
+	}
+
+	tick.Quantity, err = strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return model.TradeTick{}, err
+	}
+
+	return tick, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/adshao/go-binance/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/rodrigo-brito/ninjabot/model"
@@ -51,3 +52,46 @@ func TestFormatQuantity(t *testing.T) {
 		})
 	}
 }
+
+func TestNewOrderFromUpdate(t *testing.T) {
+	t.Run("new order", func(t *testing.T) {
+		order := newOrderFromUpdate(binance.WsOrderUpdate{
+			Symbol:            "BTCUSDT",
+			Side:              "BUY",
+			Type:              "LIMIT",
+			Status:            "NEW",
+			Id:                10,
+			Volume:            "2",
+			Price:             "100",
+			FilledVolume:      "0",
+			FilledQuoteVolume: "0",
+			CreateTime:        1000,
+			TransactionTime:   1000,
+		})
+		require.Equal(t, int64(10), order.ExchangeID)
+		require.Equal(t, model.OrderStatusTypeNew, order.Status)
+		require.Equal(t, 100.0, order.Price)
+		require.Equal(t, 2.0, order.Quantity)
+		require.Zero(t, order.ExecutedQuantity)
+	})
+
+	t.Run("filled order", func(t *testing.T) {
+		order := newOrderFromUpdate(binance.WsOrderUpdate{
+			Symbol:            "BTCUSDT",
+			Side:              "SELL",
+			Type:              "MARKET",
+			Status:            "FILLED",
+			Id:                11,
+			Volume:            "2",
+			FilledVolume:      "2",
+			FilledQuoteVolume: "210",
+			CreateTime:        1000,
+			TransactionTime:   2000,
+		})
+		require.Equal(t, model.SideTypeSell, order.Side)
+		require.Equal(t, model.OrderStatusTypeFilled, order.Status)
+		require.Equal(t, 105.0, order.Price)
+		require.Equal(t, 2.0, order.ExecutedQuantity)
+		require.Equal(t, int64(2000), order.UpdatedAt.UnixMilli())
+	})
+}
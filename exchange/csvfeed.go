@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
@@ -23,11 +25,17 @@ type PairFeed struct {
 	File       string
 	Timeframe  string
 	HeikinAshi bool
+
+	// IntrabarFile is an optional CSV file with a lower timeframe of the same pair,
+	// used to simulate the price path inside each candle
+	IntrabarFile string
 }
 
 type CSVFeed struct {
 	Feeds               map[string]PairFeed
 	CandlePairTimeFrame map[string][]model.Candle
+
+	intrabar map[string][]model.Candle
 }
 
 func (c CSVFeed) AssetsInfo(pair string) model.AssetInfo {
@@ -69,94 +77,179 @@ func NewCSVFeed(targetTimeframe string, feeds ...PairFeed) (*CSVFeed, error) {
 	csvFeed := &CSVFeed{
 		Feeds:               make(map[string]PairFeed),
 		CandlePairTimeFrame: make(map[string][]model.Candle),
+		intrabar:            make(map[string][]model.Candle),
 	}
 
 	for _, feed := range feeds {
 		csvFeed.Feeds[feed.Pair] = feed
 
-		csvFile, err := os.Open(feed.File)
+		candles, err := readCandles(feed.File, feed.Pair, feed.HeikinAshi)
 		if err != nil {
 			return nil, err
 		}
 
-		csvLines, err := csv.NewReader(csvFile).ReadAll()
+		csvFeed.CandlePairTimeFrame[csvFeed.feedTimeframeKey(feed.Pair, feed.Timeframe)] = candles
+
+		err = csvFeed.resample(feed.Pair, feed.Timeframe, targetTimeframe)
 		if err != nil {
 			return nil, err
 		}
 
-		var candles []model.Candle
-		ha := model.NewHeikinAshi()
-
-		// map each header label with its index
-		headerMap, additionalHeaders, hasCustomHeaders := parseHeaders(csvLines[0])
-		if hasCustomHeaders {
-			csvLines = csvLines[1:]
-		}
-
-		for _, line := range csvLines {
-			timestamp, err := strconv.Atoi(line[headerMap["time"]])
+		if feed.IntrabarFile != "" {
+			csvFeed.intrabar[feed.Pair], err = readCandles(feed.IntrabarFile, feed.Pair, false)
 			if err != nil {
 				return nil, err
 			}
+		}
+	}
 
-			candle := model.Candle{
-				Time:      time.Unix(int64(timestamp), 0).UTC(),
-				UpdatedAt: time.Unix(int64(timestamp), 0).UTC(),
-				Pair:      feed.Pair,
-				Complete:  true,
-			}
+	return csvFeed, nil
+}
 
-			candle.Open, err = strconv.ParseFloat(line[headerMap["open"]], 64)
-			if err != nil {
-				return nil, err
-			}
+func readCandles(file, pair string, heikinAshi bool) ([]model.Candle, error) {
+	csvFile, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer csvFile.Close()
 
-			candle.Close, err = strconv.ParseFloat(line[headerMap["close"]], 64)
-			if err != nil {
-				return nil, err
-			}
+	csvLines, err := csv.NewReader(csvFile).ReadAll()
+	if err != nil {
+		return nil, err
+	}
 
-			candle.Low, err = strconv.ParseFloat(line[headerMap["low"]], 64)
-			if err != nil {
-				return nil, err
-			}
+	var candles []model.Candle
+	ha := model.NewHeikinAshi()
 
-			candle.High, err = strconv.ParseFloat(line[headerMap["high"]], 64)
-			if err != nil {
-				return nil, err
-			}
+	// map each header label with its index
+	headerMap, additionalHeaders, hasCustomHeaders := parseHeaders(csvLines[0])
+	if hasCustomHeaders {
+		csvLines = csvLines[1:]
+	}
 
-			candle.Volume, err = strconv.ParseFloat(line[headerMap["volume"]], 64)
-			if err != nil {
-				return nil, err
-			}
+	for _, line := range csvLines {
+		timestamp, err := strconv.Atoi(line[headerMap["time"]])
+		if err != nil {
+			return nil, err
+		}
 
-			if hasCustomHeaders {
-				candle.Metadata = make(map[string]float64)
-				for _, header := range additionalHeaders {
-					candle.Metadata[header], err = strconv.ParseFloat(line[headerMap[header]], 64)
-					if err != nil {
-						return nil, err
-					}
-				}
-			}
+		candle := model.Candle{
+			Time:      time.Unix(int64(timestamp), 0).UTC(),
+			UpdatedAt: time.Unix(int64(timestamp), 0).UTC(),
+			Pair:      pair,
+			Complete:  true,
+		}
 
-			if feed.HeikinAshi {
-				candle = candle.ToHeikinAshi(ha)
-			}
+		candle.Open, err = strconv.ParseFloat(line[headerMap["open"]], 64)
+		if err != nil {
+			return nil, err
+		}
 
-			candles = append(candles, candle)
+		candle.Close, err = strconv.ParseFloat(line[headerMap["close"]], 64)
+		if err != nil {
+			return nil, err
 		}
 
-		csvFeed.CandlePairTimeFrame[csvFeed.feedTimeframeKey(feed.Pair, feed.Timeframe)] = candles
+		candle.Low, err = strconv.ParseFloat(line[headerMap["low"]], 64)
+		if err != nil {
+			return nil, err
+		}
 
-		err = csvFeed.resample(feed.Pair, feed.Timeframe, targetTimeframe)
+		candle.High, err = strconv.ParseFloat(line[headerMap["high"]], 64)
 		if err != nil {
 			return nil, err
 		}
+
+		candle.Volume, err = strconv.ParseFloat(line[headerMap["volume"]], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if hasCustomHeaders {
+			candle.Metadata = make(map[string]float64)
+			for _, header := range additionalHeaders {
+				candle.Metadata[header], err = strconv.ParseFloat(line[headerMap[header]], 64)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if heikinAshi {
+			candle = candle.ToHeikinAshi(ha)
+		}
+
+		candles = append(candles, candle)
 	}
 
-	return csvFeed, nil
+	return candles, nil
+}
+
+// IntrabarCandles returns the lower timeframe candles of a pair opened between start (inclusive) and end (exclusive),
+// loaded from the IntrabarFile of the pair feed
+func (c CSVFeed) IntrabarCandles(pair string, start, end time.Time) []model.Candle {
+	candles := c.intrabar[pair]
+	first := sort.Search(len(candles), func(i int) bool {
+		return !candles[i].Time.Before(start)
+	})
+	last := sort.Search(len(candles), func(i int) bool {
+		return !candles[i].Time.Before(end)
+	})
+	return candles[first:last]
+}
+
+// Clone returns a copy of the feed that can be consumed independently, eg: parallel backtests over the same data
+func (c *CSVFeed) Clone() *CSVFeed {
+	feed := &CSVFeed{
+		Feeds:               make(map[string]PairFeed, len(c.Feeds)),
+		CandlePairTimeFrame: make(map[string][]model.Candle, len(c.CandlePairTimeFrame)),
+		intrabar:            c.intrabar,
+	}
+
+	for pair, pairFeed := range c.Feeds {
+		feed.Feeds[pair] = pairFeed
+	}
+
+	for key, candles := range c.CandlePairTimeFrame {
+		feed.CandlePairTimeFrame[key] = candles
+	}
+
+	return feed
+}
+
+// Between returns a copy of the feed with the candles inside the period from start (inclusive) to end (exclusive)
+func (c *CSVFeed) Between(start, end time.Time) *CSVFeed {
+	feed := c.Clone()
+	for key, candles := range feed.CandlePairTimeFrame {
+		var duration time.Duration
+		if index := strings.LastIndex(key, "--"); index >= 0 {
+			duration, _ = str2duration.ParseDuration(key[index+2:])
+		}
+
+		feed.CandlePairTimeFrame[key] = lo.Filter(candles, func(candle model.Candle, _ int) bool {
+			return !candle.Time.Before(start) && !candle.Time.Add(duration).After(end)
+		})
+	}
+	return feed
+}
+
+// Period returns the time of the first and last candles of the source files
+func (c *CSVFeed) Period() (start, end time.Time) {
+	for pair, feed := range c.Feeds {
+		candles := c.CandlePairTimeFrame[c.feedTimeframeKey(pair, feed.Timeframe)]
+		if len(candles) == 0 {
+			continue
+		}
+
+		if first := candles[0].Time; start.IsZero() || first.Before(start) {
+			start = first
+		}
+
+		if last := candles[len(candles)-1].Time; last.After(end) {
+			end = last
+		}
+	}
+	return start, end
 }
 
 func (c CSVFeed) feedTimeframeKey(pair, timeframe string) string {
@@ -274,9 +367,28 @@ func (c *CSVFeed) resample(pair, sourceTimeframe, targetTimeframe string) error
 	return nil
 }
 
+// loadTimeframe resamples the source feed of a pair to a timeframe not loaded yet,
+// e.g. additional timeframes requested by multi-timeframe strategies
+func (c *CSVFeed) loadTimeframe(pair, timeframe string) error {
+	if _, ok := c.CandlePairTimeFrame[c.feedTimeframeKey(pair, timeframe)]; ok {
+		return nil
+	}
+
+	feed, ok := c.Feeds[pair]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInsufficientData, pair)
+	}
+
+	return c.resample(pair, feed.Timeframe, timeframe)
+}
+
 func (c CSVFeed) CandlesByPeriod(_ context.Context, pair, timeframe string,
 	start, end time.Time) ([]model.Candle, error) {
 
+	if err := c.loadTimeframe(pair, timeframe); err != nil {
+		return nil, err
+	}
+
 	key := c.feedTimeframeKey(pair, timeframe)
 	candles := make([]model.Candle, 0)
 	for _, candle := range c.CandlePairTimeFrame[key] {
@@ -290,6 +402,10 @@ func (c CSVFeed) CandlesByPeriod(_ context.Context, pair, timeframe string,
 
 func (c *CSVFeed) CandlesByLimit(_ context.Context, pair, timeframe string, limit int) ([]model.Candle, error) {
 	var result []model.Candle
+	if err := c.loadTimeframe(pair, timeframe); err != nil {
+		return nil, err
+	}
+
 	key := c.feedTimeframeKey(pair, timeframe)
 	if len(c.CandlePairTimeFrame[key]) < limit {
 		return nil, fmt.Errorf("%w: %s", ErrInsufficientData, pair)
@@ -302,8 +418,13 @@ func (c CSVFeed) CandlesSubscription(_ context.Context, pair, timeframe string)
 	ccandle := make(chan model.Candle)
 	cerr := make(chan error)
 	key := c.feedTimeframeKey(pair, timeframe)
+	err := c.loadTimeframe(pair, timeframe)
+	candles := c.CandlePairTimeFrame[key]
 	go func() {
-		for _, candle := range c.CandlePairTimeFrame[key] {
+		if err != nil {
+			cerr <- err
+		}
+		for _, candle := range candles {
 			ccandle <- candle
 		}
 		close(ccandle)
@@ -6,8 +6,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
 )
 
 func TestNewCSVFeed(t *testing.T) {
@@ -215,3 +218,50 @@ func TestIsFistCandlePeriod(t *testing.T) {
 		require.False(t, last)
 	})
 }
+
+func TestCSVFeed_AdditionalTimeframe(t *testing.T) {
+	feed, err := NewCSVFeed("1h", PairFeed{
+		Timeframe: "1h",
+		Pair:      "BTCUSDT",
+		File:      "../testdata/btc-1h-2021-05-13.csv",
+	})
+	require.NoError(t, err)
+	require.NotContains(t, feed.CandlePairTimeFrame, "BTCUSDT--4h")
+
+	candles, err := feed.CandlesByPeriod(context.Background(), "BTCUSDT", "4h", time.Time{}, time.Now())
+	require.NoError(t, err)
+	require.Len(t, candles, 24)
+	require.Len(t, lo.Filter(candles, func(c model.Candle, _ int) bool { return c.Complete }), 6)
+
+	_, err = feed.CandlesByPeriod(context.Background(), "ETHUSDT", "4h", time.Time{}, time.Now())
+	require.ErrorIs(t, err, ErrInsufficientData)
+}
+
+func TestCSVFeed_Between(t *testing.T) {
+	feed, err := NewCSVFeed("1d", PairFeed{
+		Timeframe: "1h",
+		Pair:      "BTCUSDT",
+		File:      "../testdata/btc-1h-2021-05-13.csv",
+	})
+	require.NoError(t, err)
+
+	start, end := feed.Period()
+	require.Equal(t, "2021-05-13 00:00:00", start.Format("2006-01-02 15:04:05"))
+	require.True(t, end.After(start))
+
+	periodStart := start.Add(2 * time.Hour)
+	periodEnd := start.Add(5 * time.Hour)
+	period := feed.Between(periodStart, periodEnd)
+
+	candles := period.CandlePairTimeFrame["BTCUSDT--1h"]
+	require.Len(t, candles, 3)
+	require.Equal(t, periodStart, candles[0].Time)
+	require.Equal(t, start.Add(4*time.Hour), candles[2].Time)
+
+	// daily candle is not complete inside the period
+	require.Empty(t, period.CandlePairTimeFrame["BTCUSDT--1d"])
+
+	// original feed is not modified
+	require.Greater(t, len(feed.CandlePairTimeFrame["BTCUSDT--1h"]), 3)
+	require.NotEmpty(t, feed.CandlePairTimeFrame["BTCUSDT--1d"])
+}
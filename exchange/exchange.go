@@ -18,6 +18,8 @@ var (
 	ErrInvalidQuantity   = errors.New("invalid quantity")
 	ErrInsufficientFunds = errors.New("insufficient funds or locked")
 	ErrInvalidAsset      = errors.New("invalid asset")
+	ErrOCONotSupported   = errors.New("OCO orders not supported")
+	ErrOrderNotFound     = errors.New("order not found")
 )
 
 type DataFeed struct {
@@ -30,6 +32,11 @@ type DataFeedSubscription struct {
 	Feeds                   *set.LinkedHashSetString
 	DataFeeds               map[string]*DataFeed
 	SubscriptionsByDataFeed map[string][]Subscription
+
+	// order book and trade subscriptions, by pair
+	OrderBookDepth         map[string]int
+	OrderBookSubscriptions map[string][]OrderBookConsumer
+	TradeSubscriptions     map[string][]TradeConsumer
 }
 
 type Subscription struct {
@@ -47,6 +54,10 @@ func (o *OrderError) Error() string {
 	return fmt.Sprintf("order error: %v", o.Err)
 }
 
+func (o *OrderError) Unwrap() error {
+	return o.Err
+}
+
 type DataFeedConsumer func(model.Candle)
 
 func NewDataFeed(exchange service.Exchange) *DataFeedSubscription {
@@ -55,6 +66,9 @@ func NewDataFeed(exchange service.Exchange) *DataFeedSubscription {
 		Feeds:                   set.NewLinkedHashSetString(),
 		DataFeeds:               make(map[string]*DataFeed),
 		SubscriptionsByDataFeed: make(map[string][]Subscription),
+		OrderBookDepth:          make(map[string]int),
+		OrderBookSubscriptions:  make(map[string][]OrderBookConsumer),
+		TradeSubscriptions:      make(map[string][]TradeConsumer),
 	}
 }
 
@@ -84,6 +98,8 @@ func (d *DataFeedSubscription) Preload(pair, timeframe string, candles []model.C
 			continue
 		}
 
+		candle.Timeframe = timeframe
+
 		for _, subscription := range d.SubscriptionsByDataFeed[key] {
 			subscription.consumer(candle)
 		}
@@ -104,10 +120,13 @@ func (d *DataFeedSubscription) Connect() {
 
 func (d *DataFeedSubscription) Start(loadSync bool) {
 	d.Connect()
+	d.startOrderBooks()
+	d.startTrades()
 	wg := new(sync.WaitGroup)
 	for key, feed := range d.DataFeeds {
 		wg.Add(1)
 		go func(key string, feed *DataFeed) {
+			_, timeframe := d.pairTimeframeFromKey(key)
 			for {
 				select {
 				case candle, ok := <-feed.Data:
@@ -115,6 +134,7 @@ func (d *DataFeedSubscription) Start(loadSync bool) {
 						wg.Done()
 						return
 					}
+					candle.Timeframe = timeframe
 					for _, subscription := range d.SubscriptionsByDataFeed[key] {
 						if subscription.onCandleClose && !candle.Complete {
 							continue
@@ -0,0 +1,133 @@
+package exchange
+
+import (
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+// SlippageModel calculates the slippage of orders executed at market price in the paper wallet,
+// such as market orders and triggered stop orders
+type SlippageModel interface {
+	// Slippage returns the absolute price difference from the reference price, against the order side
+	Slippage(side model.SideType, price float64, candle model.Candle) float64
+}
+
+// FixedSlippage is a slippage model with a fixed value in basis points, eg: 5 = 0.05%
+type FixedSlippage float64
+
+func (f FixedSlippage) Slippage(_ model.SideType, price float64, _ model.Candle) float64 {
+	return price * float64(f) / 10000
+}
+
+// VolatilitySlippage is a slippage model scaled by the candle range (high - low), relative to the close price.
+// eg: a factor of 0.1 in a candle with range of 2% results in a slippage of 0.2%
+type VolatilitySlippage float64
+
+func (v VolatilitySlippage) Slippage(_ model.SideType, price float64, candle model.Candle) float64 {
+	if candle.Close == 0 {
+		return 0
+	}
+	return price * float64(v) * (candle.High - candle.Low) / candle.Close
+}
+
+// WithPaperSlippage sets the slippage model of orders executed at market price
+func WithPaperSlippage(slippage SlippageModel) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.slippageModel = slippage
+	}
+}
+
+// WithPaperVolumeLimit limits the quantity filled in each candle to a fraction of the candle volume,
+// eg: 0.1 = 10%. Orders larger than the limit are partially filled over the next candles.
+func WithPaperVolumeLimit(fraction float64) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.volumeLimit = fraction
+	}
+}
+
+// executionPrice applies the slippage model to the reference price of an order
+func (p *PaperWallet) executionPrice(side model.SideType, price float64, candle model.Candle) float64 {
+	if p.slippageModel == nil {
+		return price
+	}
+
+	slippage := p.slippageModel.Slippage(side, price, candle)
+	if side == model.SideTypeBuy {
+		return price + slippage
+	}
+	return price - slippage
+}
+
+// fillQuantity returns the quantity of an order executed in a candle, limited by the candle volume
+func (p *PaperWallet) fillQuantity(remaining float64, candle model.Candle) float64 {
+	if p.volumeLimit <= 0 {
+		return remaining
+	}
+
+	if limit := candle.Volume * p.volumeLimit; limit < remaining {
+		return limit
+	}
+	return remaining
+}
+
+// fill executes a quantity of an order, moving the locked funds to the position.
+// The lock price is the price used to lock the funds when the order was created.
+func (p *PaperWallet) fill(order *model.Order, quantity, price, lockPrice, refPrice float64, candle model.Candle) {
+	if quantity <= 0 {
+		return
+	}
+
+	asset, quote := SplitAssetQuote(order.Pair)
+	if _, ok := p.assets[asset]; !ok {
+		p.assets[asset] = &assetInfo{}
+	}
+
+	if _, ok := p.assets[quote]; !ok {
+		p.assets[quote] = &assetInfo{}
+	}
+
+	if p.futures != nil {
+		p.fillPosition(order.Side, order.Pair, quantity, price)
+		p.registerFill(order, quantity, price, refPrice, candle)
+		return
+	}
+
+	p.updateAveragePrice(order.Side, order.Pair, quantity, price)
+	if order.Side == model.SideTypeBuy {
+		p.assets[asset].Free += quantity
+		p.assets[quote].Lock -= lockPrice * quantity
+		p.assets[quote].Free -= (price - lockPrice) * quantity
+	} else {
+		p.assets[asset].Lock -= quantity
+		p.assets[quote].Free += quantity * price
+	}
+
+	p.registerFill(order, quantity, price, refPrice, candle)
+}
+
+// registerFill updates the status, executed quantity and average price of an order
+func (p *PaperWallet) registerFill(order *model.Order, quantity, price, refPrice float64, candle model.Candle) {
+	if quantity <= 0 {
+		return
+	}
+
+	executed := order.ExecutedQuantity + quantity
+	order.Price = (order.Price*order.ExecutedQuantity + price*quantity) / executed
+	order.ExecutedQuantity = executed
+	order.UpdatedAt = candle.Time
+	order.Status = model.OrderStatusTypePartiallyFilled
+	if executed >= order.Quantity {
+		order.Status = model.OrderStatusTypeFilled
+	}
+
+	p.volume[order.Pair] += price * quantity
+	if price > refPrice {
+		p.slippage[order.Pair] += (price - refPrice) * quantity
+	} else {
+		p.slippage[order.Pair] += (refPrice - price) * quantity
+	}
+}
+
+// Slippage returns the total cost of slippage, by pair
+func (p *PaperWallet) Slippage() map[string]float64 {
+	return p.slippage
+}
@@ -0,0 +1,441 @@
+package exchange
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+// DefaultMaintenanceMargin is the maintenance margin rate of the paper wallet in futures mode,
+// the rate of the first tier of the main pairs in Binance Futures
+const DefaultMaintenanceMargin = 0.004
+
+// FundingRate is the funding rate of a perpetual contract at a given time.
+// Positive rates are paid by long positions to short positions.
+type FundingRate struct {
+	Time time.Time
+	Rate float64
+}
+
+type futuresPosition struct {
+	Quantity   float64 // negative for short positions
+	EntryPrice float64
+	Margin     float64
+}
+
+type futuresWallet struct {
+	leverage        map[string]int
+	marginType      map[string]MarginType
+	maintenanceRate float64
+	positions       map[string]*futuresPosition
+	fundingRates    map[string][]FundingRate
+	funding         map[string]float64
+	liquidations    map[string]int
+}
+
+// WithPaperFutures enables the futures mode of the paper wallet: positions are opened with margin, long and short
+// positions are liquidated when the margin balance reaches the maintenance margin, and funding payments are applied.
+// The default leverage is 1 with cross margin.
+func WithPaperFutures() PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.futuresMode()
+	}
+}
+
+// WithPaperLeverage sets the leverage and margin type of a pair, like WithBinanceFutureLeverage.
+// It enables the futures mode of the paper wallet.
+func WithPaperLeverage(pair string, leverage int, marginType MarginType) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		futures := wallet.futuresMode()
+		futures.leverage[strings.ToUpper(pair)] = leverage
+		futures.marginType[strings.ToUpper(pair)] = marginType
+	}
+}
+
+// WithPaperMaintenanceMargin sets the maintenance margin rate of the positions, eg: 0.004 = 0.4%.
+// It enables the futures mode of the paper wallet.
+func WithPaperMaintenanceMargin(rate float64) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.futuresMode().maintenanceRate = rate
+	}
+}
+
+// WithPaperFunding sets the funding rates of a pair, applied to the open positions at the funding times.
+// It enables the futures mode of the paper wallet.
+func WithPaperFunding(pair string, rates []FundingRate) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		pair = strings.ToUpper(pair)
+		futures := wallet.futuresMode()
+		futures.fundingRates[pair] = append(futures.fundingRates[pair], rates...)
+		sort.SliceStable(futures.fundingRates[pair], func(i, j int) bool {
+			return futures.fundingRates[pair][i].Time.Before(futures.fundingRates[pair][j].Time)
+		})
+	}
+}
+
+// LoadFundingRates reads the funding rates from a CSV file with the columns time (unix timestamp, in seconds or
+// milliseconds) and rate. The header is optional, the files of Binance public data are also supported.
+func LoadFundingRates(file string) ([]FundingRate, error) {
+	csvFile, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer csvFile.Close()
+
+	lines, err := csv.NewReader(csvFile).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	timeIndex, rateIndex := 0, 1
+	if len(lines) > 0 {
+		if _, err := strconv.ParseInt(lines[0][0], 10, 64); err != nil {
+			for i, header := range lines[0] {
+				switch header {
+				case "time", "calc_time", "funding_time":
+					timeIndex = i
+				case "rate", "funding_rate", "last_funding_rate":
+					rateIndex = i
+				}
+			}
+			lines = lines[1:]
+		}
+	}
+
+	rates := make([]FundingRate, 0, len(lines))
+	for _, line := range lines {
+		if len(line) <= timeIndex || len(line) <= rateIndex {
+			return nil, fmt.Errorf("invalid funding rate line: %v", line)
+		}
+
+		timestamp, err := strconv.ParseInt(line[timeIndex], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		rate, err := strconv.ParseFloat(line[rateIndex], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		fundingTime := time.Unix(timestamp, 0).UTC()
+		if timestamp > 1e11 {
+			fundingTime = time.UnixMilli(timestamp).UTC()
+		}
+		rates = append(rates, FundingRate{Time: fundingTime, Rate: rate})
+	}
+
+	return rates, nil
+}
+
+// futuresMode enables the futures mode of the wallet
+func (p *PaperWallet) futuresMode() *futuresWallet {
+	if p.futures == nil {
+		p.futures = &futuresWallet{
+			leverage:        make(map[string]int),
+			marginType:      make(map[string]MarginType),
+			maintenanceRate: DefaultMaintenanceMargin,
+			positions:       make(map[string]*futuresPosition),
+			fundingRates:    make(map[string][]FundingRate),
+			funding:         make(map[string]float64),
+			liquidations:    make(map[string]int),
+		}
+	}
+	return p.futures
+}
+
+// Funding returns the total of funding payments by pair, negative values are paid by the wallet
+func (p *PaperWallet) Funding() map[string]float64 {
+	if p.futures == nil {
+		return nil
+	}
+	return p.futures.funding
+}
+
+// Liquidations returns the number of liquidated positions by pair
+func (p *PaperWallet) Liquidations() map[string]int {
+	if p.futures == nil {
+		return nil
+	}
+	return p.futures.liquidations
+}
+
+func (p *PaperWallet) leverage(pair string) float64 {
+	if leverage := p.futures.leverage[pair]; leverage > 0 {
+		return float64(leverage)
+	}
+	return 1
+}
+
+func (p *PaperWallet) isolated(pair string) bool {
+	return p.futures.marginType[pair] == MarginTypeIsolated
+}
+
+func (p *PaperWallet) pairPosition(pair string) *futuresPosition {
+	position, ok := p.futures.positions[pair]
+	if !ok {
+		position = &futuresPosition{}
+		p.futures.positions[pair] = position
+	}
+	return position
+}
+
+// unrealizedProfit returns the profit of the open position of a pair at the last price
+func (p *PaperWallet) unrealizedProfit(pair string) float64 {
+	position, ok := p.futures.positions[pair]
+	if !ok {
+		return 0
+	}
+	return position.Quantity * (p.lastCandle[pair].Close - position.EntryPrice)
+}
+
+// openingQuantity returns the part of an order that opens or increases the position, the rest reduces it
+func (p *PaperWallet) openingQuantity(side model.SideType, pair string, quantity float64) float64 {
+	position, ok := p.futures.positions[pair]
+	if !ok {
+		return quantity
+	}
+
+	if (side == model.SideTypeBuy && position.Quantity < 0) || (side == model.SideTypeSell && position.Quantity > 0) {
+		return math.Max(quantity-math.Abs(position.Quantity), 0)
+	}
+	return quantity
+}
+
+// usedMargin returns the initial margin of the positions and pending orders
+func (p *PaperWallet) usedMargin() float64 {
+	var margin float64
+	for _, position := range p.futures.positions {
+		margin += position.Margin
+	}
+
+	for _, order := range p.orders {
+		if order.Status != model.OrderStatusTypeNew && order.Status != model.OrderStatusTypePartiallyFilled {
+			continue
+		}
+
+		price := order.Price
+		if price == 0 {
+			price = order.RefPrice
+		}
+		quantity := p.openingQuantity(order.Side, order.Pair, order.Quantity-order.ExecutedQuantity)
+		margin += quantity * price / p.leverage(order.Pair)
+	}
+	return margin
+}
+
+// availableMargin returns the balance available to open positions, including the unrealized profit
+// of the positions in cross margin
+func (p *PaperWallet) availableMargin(quote string) float64 {
+	available := p.assets[quote].Free - p.usedMargin()
+	for pair := range p.futures.positions {
+		if !p.isolated(pair) {
+			available += p.unrealizedProfit(pair)
+		}
+	}
+	return available
+}
+
+// validateMargin checks if the wallet has enough margin to open the position of an order.
+// With fill, the order is executed at the given price.
+func (p *PaperWallet) validateMargin(side model.SideType, pair string, amount, value float64, fill bool) error {
+	_, quote := SplitAssetQuote(pair)
+	opening := p.openingQuantity(side, pair, amount)
+	required := opening * value / p.leverage(pair)
+	available := p.availableMargin(quote)
+
+	// orders that reverse the position use the margin released by the closed position
+	if position, ok := p.futures.positions[pair]; ok && opening > 0 && opening < amount {
+		available += position.Margin
+		if p.isolated(pair) {
+			available += position.Quantity * (value - position.EntryPrice)
+		}
+	}
+
+	if required > 0 && required > available {
+		return &OrderError{
+			Err:      ErrInsufficientFunds,
+			Pair:     pair,
+			Quantity: amount,
+		}
+	}
+
+	if fill {
+		p.fillPosition(side, pair, amount, value)
+	}
+	return nil
+}
+
+// fillPosition updates the position of a pair with an executed quantity, the profit of the reduced position
+// is realized in the wallet balance
+func (p *PaperWallet) fillPosition(side model.SideType, pair string, quantity, price float64) {
+	asset, quote := SplitAssetQuote(pair)
+	position := p.pairPosition(pair)
+
+	direction := 1.0
+	if side == model.SideTypeSell {
+		direction = -1
+	}
+
+	// reduce the opposite position
+	if position.Quantity*direction < 0 {
+		closed := math.Min(quantity, math.Abs(position.Quantity))
+		profit := -direction * closed * (price - position.EntryPrice)
+		log.Infof("PROFIT = %.4f %s", profit, quote)
+
+		p.assets[quote].Free += profit
+		position.Margin -= position.Margin * closed / math.Abs(position.Quantity)
+		position.Quantity += direction * closed
+		quantity -= closed
+	}
+
+	// open or increase the position
+	if quantity > 0 {
+		size := math.Abs(position.Quantity)
+		position.EntryPrice = (size*position.EntryPrice + quantity*price) / (size + quantity)
+		position.Margin += quantity * price / p.leverage(pair)
+		position.Quantity += direction * quantity
+	}
+
+	// tolerance for rounding errors
+	if math.Abs(position.Quantity) < 1e-12 {
+		delete(p.futures.positions, pair)
+		position.Quantity = 0
+	}
+
+	p.assets[asset].Free = position.Quantity
+}
+
+// applyFunding pays or receives the funding of the open position until the candle time,
+// at the opening price of the candle
+func (p *PaperWallet) applyFunding(candle model.Candle) {
+	rates := p.futures.fundingRates[candle.Pair]
+	for len(rates) > 0 && !rates[0].Time.After(candle.Time) {
+		if position, ok := p.futures.positions[candle.Pair]; ok {
+			_, quote := SplitAssetQuote(candle.Pair)
+			payment := -position.Quantity * candle.Open * rates[0].Rate
+			p.assets[quote].Free += payment
+			p.futures.funding[candle.Pair] += payment
+			log.Debugf("[FUNDING] %s = %f %s", candle.Pair, payment, quote)
+		}
+		rates = rates[1:]
+	}
+	p.futures.fundingRates[candle.Pair] = rates
+}
+
+// liquidationPrice returns the price where the margin balance of a position reaches the maintenance margin.
+// In cross margin, the balance includes the wallet balance and the other cross positions at the last price.
+func (p *PaperWallet) liquidationPrice(pair string) float64 {
+	position := p.futures.positions[pair]
+	rate := p.futures.maintenanceRate
+	_, quote := SplitAssetQuote(pair)
+
+	balance := position.Margin
+	if !p.isolated(pair) {
+		balance = p.assets[quote].Free
+		for otherPair, other := range p.futures.positions {
+			switch {
+			case p.isolated(otherPair):
+				balance -= other.Margin
+			case otherPair != pair:
+				balance += p.unrealizedProfit(otherPair) -
+					rate*math.Abs(other.Quantity)*p.lastCandle[otherPair].Close
+			}
+		}
+	}
+
+	size := math.Abs(position.Quantity)
+	if position.Quantity > 0 {
+		return math.Max((size*position.EntryPrice-balance)/(size*(1-rate)), 0)
+	}
+	return (size*position.EntryPrice + balance) / (size * (1 + rate))
+}
+
+// checkLiquidation liquidates the position of the candle pair when the price reaches the liquidation price.
+// The position is closed at the liquidation price, the remaining maintenance margin is charged as the
+// liquidation fee, and the pending orders of the pair are canceled.
+func (p *PaperWallet) checkLiquidation(candle model.Candle) {
+	position, ok := p.futures.positions[candle.Pair]
+	if !ok {
+		return
+	}
+
+	price := p.liquidationPrice(candle.Pair)
+	if (position.Quantity > 0 && candle.Low > price) || (position.Quantity < 0 && candle.High < price) {
+		return
+	}
+
+	asset, quote := SplitAssetQuote(candle.Pair)
+	size := math.Abs(position.Quantity)
+	side := model.SideTypeSell
+	if position.Quantity < 0 {
+		side = model.SideTypeBuy
+	}
+
+	for i, order := range p.orders {
+		if order.Pair == candle.Pair && (order.Status == model.OrderStatusTypeNew ||
+			order.Status == model.OrderStatusTypePartiallyFilled) {
+			p.orders[i].Status = model.OrderStatusTypeCanceled
+			p.orders[i].UpdatedAt = candle.Time
+			p.publishOrder(p.orders[i])
+		}
+	}
+
+	profit := position.Quantity * (price - position.EntryPrice)
+	fee := p.futures.maintenanceRate * size * price
+	p.assets[quote].Free += profit - fee
+	p.assets[asset].Free = 0
+	delete(p.futures.positions, candle.Pair)
+	p.futures.liquidations[candle.Pair]++
+
+	order := model.Order{
+		ExchangeID: p.ID(),
+		CreatedAt:  candle.Time,
+		UpdatedAt:  candle.Time,
+		Pair:       candle.Pair,
+		Side:       side,
+		Type:       model.OrderTypeLiquidation,
+		Status:     model.OrderStatusTypeNew,
+		Quantity:   size,
+		RefPrice:   price,
+	}
+	p.registerFill(&order, size, price, price, candle)
+	p.orders = append(p.orders, order)
+	p.publishOrder(order)
+
+	log.Warnf("[LIQUIDATION] %s %f %s at %f, loss = %f %s", side, size, asset, price, fee-profit, quote)
+}
+
+// futuresAccount returns the positions of the futures mode, the quote balance is split between the available
+// balance and the margin used by the positions and pending orders
+func (p *PaperWallet) futuresAccount() model.Account {
+	margin := p.usedMargin()
+	balances := make([]model.Balance, 0)
+	for asset, info := range p.assets {
+		balance := model.Balance{
+			Asset: asset,
+			Free:  info.Free,
+			Lock:  info.Lock,
+		}
+
+		if asset == p.baseCoin {
+			balance.Free -= margin
+			balance.Lock += margin
+		} else {
+			balance.Leverage = p.leverage(asset + p.baseCoin)
+		}
+		balances = append(balances, balance)
+	}
+
+	return model.Account{
+		Balances: balances,
+	}
+}
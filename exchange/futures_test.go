@@ -0,0 +1,189 @@
+package exchange
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+func TestPaperWallet_Futures(t *testing.T) {
+	t.Run("margin with leverage", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100),
+			WithPaperLeverage("btcusdt", 10, MarginTypeIsolated))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 100, Low: 100, Close: 100})
+
+		_, err := wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 5)
+		require.NoError(t, err)
+
+		account, err := wallet.Account()
+		require.NoError(t, err)
+		asset, quote := account.Balance("BTC", "USDT")
+		require.Equal(t, 5.0, asset.Free)
+		require.Equal(t, 10.0, asset.Leverage)
+		require.Equal(t, 50.0, quote.Free)
+		require.Equal(t, 50.0, quote.Lock)
+
+		// 60 USDT of margin required
+		_, err = wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 6)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+
+		// orders that reduce the position do not require margin
+		_, err = wallet.CreateOrderStop("BTCUSDT", 5, 95)
+		require.NoError(t, err)
+		_, err = wallet.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 4, 100)
+		require.NoError(t, err)
+		_, err = wallet.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 2, 100)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+
+		// profit is realized in the wallet balance
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 110, Low: 105, Close: 110})
+		_, err = wallet.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 5)
+		require.NoError(t, err)
+
+		position, balance, err := wallet.Position("BTCUSDT")
+		require.NoError(t, err)
+		require.Equal(t, 0.0, position)
+		require.Equal(t, 150.0, balance)
+	})
+
+	t.Run("short position", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100),
+			WithPaperLeverage("BTCUSDT", 2, MarginTypeCrossed))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 100, Low: 100, Close: 100, Complete: true})
+
+		_, err := wallet.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 2)
+		require.NoError(t, err)
+		require.Equal(t, -2.0, wallet.assets["BTC"].Free)
+
+		// unrealized profit in the equity
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 90, Low: 90, Close: 90, Complete: true})
+		require.Equal(t, 120.0, wallet.EquityValues()[1].Value)
+
+		// reverse the position: close the short and open a long
+		_, err = wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 3)
+		require.NoError(t, err)
+
+		asset, quote, err := wallet.Position("BTCUSDT")
+		require.NoError(t, err)
+		require.Equal(t, 1.0, asset)
+		require.Equal(t, 120.0, quote)
+		require.Equal(t, 90.0, wallet.futures.positions["BTCUSDT"].EntryPrice)
+		require.Equal(t, 45.0, wallet.futures.positions["BTCUSDT"].Margin)
+	})
+
+	t.Run("isolated liquidation", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100),
+			WithPaperLeverage("BTCUSDT", 10, MarginTypeIsolated))
+		updates, _ := wallet.OrderSubscription(context.Background())
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 100, Low: 100, Close: 100})
+
+		_, err := wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 5)
+		require.NoError(t, err)
+		_, err = wallet.CreateOrderLimit(model.SideTypeSell, "BTCUSDT", 5, 120)
+		require.NoError(t, err)
+
+		// liquidation price: (500 - 50) / (5 * 0.996) = 90.36
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 100, Low: 91, Close: 95})
+		require.Contains(t, wallet.futures.positions, "BTCUSDT")
+
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 95, Low: 90, Close: 92})
+		require.NotContains(t, wallet.futures.positions, "BTCUSDT")
+		require.Equal(t, 1, wallet.Liquidations()["BTCUSDT"])
+		require.InDelta(t, 50.0, wallet.assets["USDT"].Free, 1e-9)
+		require.Equal(t, 0.0, wallet.assets["BTC"].Free)
+
+		canceled := <-updates
+		require.Equal(t, model.OrderStatusTypeCanceled, canceled.Status)
+
+		liquidation := <-updates
+		require.Equal(t, model.OrderTypeLiquidation, liquidation.Type)
+		require.Equal(t, model.OrderStatusTypeFilled, liquidation.Status)
+		require.Equal(t, model.SideTypeSell, liquidation.Side)
+		require.Equal(t, 5.0, liquidation.Quantity)
+		require.InDelta(t, 90.3614, liquidation.Price, 1e-4)
+	})
+
+	t.Run("cross liquidation", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100),
+			WithPaperLeverage("BTCUSDT", 10, MarginTypeCrossed))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 100, Low: 100, Close: 100})
+
+		_, err := wallet.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 5)
+		require.NoError(t, err)
+
+		// the whole wallet balance is used as margin: (500 + 100) / (5 * 1.004) = 119.52
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 115, Low: 100, Close: 110})
+		require.Contains(t, wallet.futures.positions, "BTCUSDT")
+
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 120, Low: 110, Close: 115})
+		require.NotContains(t, wallet.futures.positions, "BTCUSDT")
+		require.InDelta(t, 0, wallet.assets["USDT"].Free, 1e-9)
+
+		order := wallet.orders[len(wallet.orders)-1]
+		require.Equal(t, model.OrderTypeLiquidation, order.Type)
+		require.Equal(t, model.SideTypeBuy, order.Side)
+		require.InDelta(t, 119.5219, order.Price, 1e-4)
+	})
+
+	t.Run("funding", func(t *testing.T) {
+		start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 1000),
+			WithPaperFunding("BTCUSDT", []FundingRate{
+				{Time: start.Add(16 * time.Hour), Rate: -0.002},
+				{Time: start.Add(8 * time.Hour), Rate: 0.001},
+			}))
+		wallet.OnCandle(model.Candle{Time: start, Pair: "BTCUSDT", Open: 100, High: 100, Low: 100, Close: 100})
+
+		_, err := wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 2)
+		require.NoError(t, err)
+
+		// long position pays positive rates, at the open price
+		wallet.OnCandle(model.Candle{Time: start.Add(8 * time.Hour), Pair: "BTCUSDT", Open: 200, High: 200,
+			Low: 200, Close: 200})
+		require.InDelta(t, 999.6, wallet.assets["USDT"].Free, 1e-9)
+
+		// and receives negative rates
+		wallet.OnCandle(model.Candle{Time: start.Add(20 * time.Hour), Pair: "BTCUSDT", Open: 100, High: 100,
+			Low: 100, Close: 100})
+		require.InDelta(t, 1000.0, wallet.assets["USDT"].Free, 1e-9)
+		require.InDelta(t, 0.0, wallet.Funding()["BTCUSDT"], 1e-9)
+		require.Empty(t, wallet.futures.fundingRates["BTCUSDT"])
+	})
+}
+
+func TestLoadFundingRates(t *testing.T) {
+	t.Run("binance format", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "funding.csv")
+		err := os.WriteFile(file, []byte("calc_time,funding_interval_hours,last_funding_rate\n"+
+			"1672531200000,8,0.00010000\n1672560000000,8,-0.00005000\n"), 0600)
+		require.NoError(t, err)
+
+		rates, err := LoadFundingRates(file)
+		require.NoError(t, err)
+		require.Equal(t, []FundingRate{
+			{Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Rate: 0.0001},
+			{Time: time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC), Rate: -0.00005},
+		}, rates)
+	})
+
+	t.Run("without header", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "funding.csv")
+		err := os.WriteFile(file, []byte("1672531200,0.0001\n"), 0600)
+		require.NoError(t, err)
+
+		rates, err := LoadFundingRates(file)
+		require.NoError(t, err)
+		require.Equal(t, []FundingRate{{Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Rate: 0.0001}}, rates)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		_, err := LoadFundingRates("not-found.csv")
+		require.Error(t, err)
+	})
+}
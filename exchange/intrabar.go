@@ -0,0 +1,99 @@
+package exchange
+
+import (
+	"github.com/xhit/go-str2duration/v2"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+// IntrabarModel simulates the price path inside a candle, used by the paper wallet
+// to decide which order is triggered first when a candle reaches multiple prices, eg: stop and limit of an OCO
+type IntrabarModel interface {
+	// Path returns the sequence of prices reached by the candle, from open to close
+	Path(candle model.Candle) []float64
+}
+
+// IntrabarFunc is an adapter to use ordinary functions as IntrabarModel
+type IntrabarFunc func(candle model.Candle) []float64
+
+func (f IntrabarFunc) Path(candle model.Candle) []float64 {
+	return f(candle)
+}
+
+var (
+	// IntrabarOHLC assumes the candle reaches the high before the low
+	IntrabarOHLC IntrabarModel = IntrabarFunc(func(candle model.Candle) []float64 {
+		return []float64{candle.Open, candle.High, candle.Low, candle.Close}
+	})
+
+	// IntrabarOLHC assumes the candle reaches the low before the high
+	IntrabarOLHC IntrabarModel = IntrabarFunc(func(candle model.Candle) []float64 {
+		return []float64{candle.Open, candle.Low, candle.High, candle.Close}
+	})
+
+	// IntrabarDirection chooses the path by candle direction: bullish candles go open-low-high-close
+	// and bearish candles go open-high-low-close
+	IntrabarDirection IntrabarModel = IntrabarFunc(func(candle model.Candle) []float64 {
+		if candle.Close >= candle.Open {
+			return IntrabarOLHC.Path(candle)
+		}
+		return IntrabarOHLC.Path(candle)
+	})
+)
+
+// IntrabarLowerTimeframe drills down into the lower timeframe candles loaded from PairFeed.IntrabarFile.
+// Each lower timeframe candle is expanded with the fallback model, which is also used for candles without data.
+type IntrabarLowerTimeframe struct {
+	feed     *CSVFeed
+	fallback IntrabarModel
+}
+
+// NewIntrabarLowerTimeframe creates an intrabar model from the lower timeframe candles of a CSV feed,
+// if fallback is nil, IntrabarDirection is used.
+func NewIntrabarLowerTimeframe(feed *CSVFeed, fallback IntrabarModel) *IntrabarLowerTimeframe {
+	if fallback == nil {
+		fallback = IntrabarDirection
+	}
+
+	return &IntrabarLowerTimeframe{
+		feed:     feed,
+		fallback: fallback,
+	}
+}
+
+func (i IntrabarLowerTimeframe) Path(candle model.Candle) []float64 {
+	timeframe := candle.Timeframe
+	if !candle.Complete || timeframe == "" {
+		// partial candles of a resampled feed are updated by each source candle
+		timeframe = i.feed.Feeds[candle.Pair].Timeframe
+	}
+
+	duration, err := str2duration.ParseDuration(timeframe)
+	if err != nil {
+		return i.fallback.Path(candle)
+	}
+
+	end := candle.Time.Add(duration)
+	if !candle.Complete {
+		end = candle.UpdatedAt.Add(duration)
+	}
+
+	candles := i.feed.IntrabarCandles(candle.Pair, candle.Time, end)
+	if len(candles) == 0 {
+		return i.fallback.Path(candle)
+	}
+
+	path := make([]float64, 0, len(candles)*4)
+	for _, lowerCandle := range candles {
+		path = append(path, i.fallback.Path(lowerCandle)...)
+	}
+	return path
+}
+
+// WithPaperIntrabar sets the model used to simulate the price path inside a candle.
+// By default, orders are evaluated in creation order, without a price path.
+func WithPaperIntrabar(intrabar IntrabarModel) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.intrabar = intrabar
+	}
+}
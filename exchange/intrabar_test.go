@@ -0,0 +1,81 @@
+package exchange
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+func TestIntrabarModels(t *testing.T) {
+	bullish := model.Candle{Open: 10, High: 15, Low: 5, Close: 12}
+	bearish := model.Candle{Open: 12, High: 15, Low: 5, Close: 10}
+
+	require.Equal(t, []float64{10, 15, 5, 12}, IntrabarOHLC.Path(bullish))
+	require.Equal(t, []float64{10, 5, 15, 12}, IntrabarOLHC.Path(bullish))
+	require.Equal(t, []float64{10, 5, 15, 12}, IntrabarDirection.Path(bullish))
+	require.Equal(t, []float64{12, 15, 5, 10}, IntrabarDirection.Path(bearish))
+}
+
+func TestIntrabarLowerTimeframe(t *testing.T) {
+	dir := t.TempDir()
+	mainFile := filepath.Join(dir, "btc-1h.csv")
+	lowerFile := filepath.Join(dir, "btc-30m.csv")
+
+	// time, open, close, low, high, volume
+	err := os.WriteFile(mainFile, []byte("3600,100,102,90,110,10\n7200,102,105,100,106,10\n"), 0600)
+	require.NoError(t, err)
+	err = os.WriteFile(lowerFile, []byte("3600,100,95,90,101,5\n5400,95,102,94,110,5\n"), 0600)
+	require.NoError(t, err)
+
+	feed, err := NewCSVFeed("1h", PairFeed{
+		Pair:         "BTCUSDT",
+		File:         mainFile,
+		Timeframe:    "1h",
+		IntrabarFile: lowerFile,
+	})
+	require.NoError(t, err)
+
+	intrabar := NewIntrabarLowerTimeframe(feed, nil)
+	candles := feed.CandlePairTimeFrame["BTCUSDT--1h"]
+
+	t.Run("lower timeframe candles", func(t *testing.T) {
+		require.Len(t, feed.IntrabarCandles("BTCUSDT", time.Unix(3600, 0), time.Unix(7200, 0)), 2)
+		require.Equal(t, []float64{100, 101, 90, 95, 95, 94, 110, 102}, intrabar.Path(candles[0]))
+	})
+
+	t.Run("fallback without data", func(t *testing.T) {
+		require.Equal(t, []float64{102, 100, 106, 105}, intrabar.Path(candles[1]))
+	})
+
+	t.Run("paper wallet OCO", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			intrabar IntrabarModel
+			expected float64
+		}{
+			{name: "without path", intrabar: nil, expected: 109},
+			{name: "OHLC path", intrabar: IntrabarOHLC, expected: 109},
+			{name: "OLHC path", intrabar: IntrabarOLHC, expected: 91},
+			{name: "lower timeframe path", intrabar: intrabar, expected: 91},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("BTC", 1),
+					WithPaperAsset("USDT", 0), WithPaperIntrabar(tc.intrabar))
+				wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 100, High: 100, Low: 100})
+
+				_, err := wallet.CreateOrderOCO(model.SideTypeSell, "BTCUSDT", 1, 109, 91, 91)
+				require.NoError(t, err)
+
+				wallet.OnCandle(candles[0])
+				require.Equal(t, tc.expected, wallet.assets["USDT"].Free)
+				require.Equal(t, 0.0, wallet.assets["BTC"].Lock)
+			})
+		}
+	})
+}
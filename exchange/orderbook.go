@@ -0,0 +1,154 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+// orderBookSnapshotLimit is the number of levels of the snapshot used to build the local order book
+const orderBookSnapshotLimit = 1000
+
+type OrderBookConsumer func(model.OrderBook)
+
+type TradeConsumer func(model.TradeTick)
+
+// SubscribeOrderBook subscribes to the order book of a pair. The consumers receive the full book, limited to
+// the given number of levels of each side, after each update. The largest depth of the pair is used for all
+// consumers. The subscription is ignored if the exchange does not implement service.OrderBookFeeder.
+func (d *DataFeedSubscription) SubscribeOrderBook(pair string, depth int, consumer OrderBookConsumer) {
+	if depth > d.OrderBookDepth[pair] {
+		d.OrderBookDepth[pair] = depth
+	}
+	d.OrderBookSubscriptions[pair] = append(d.OrderBookSubscriptions[pair], consumer)
+}
+
+// SubscribeTrades subscribes to the aggregated trades of a pair.
+// The subscription is ignored if the exchange does not implement service.TradeFeeder.
+func (d *DataFeedSubscription) SubscribeTrades(pair string, consumer TradeConsumer) {
+	d.TradeSubscriptions[pair] = append(d.TradeSubscriptions[pair], consumer)
+}
+
+// marketFeeder returns the source of the market data: the exchange, or the feeder of a paper wallet
+func (d *DataFeedSubscription) marketFeeder() any {
+	if wallet, ok := d.exchange.(*PaperWallet); ok {
+		return wallet.feeder
+	}
+	return d.exchange
+}
+
+func (d *DataFeedSubscription) startOrderBooks() {
+	if len(d.OrderBookSubscriptions) == 0 {
+		return
+	}
+
+	feeder, ok := d.marketFeeder().(service.OrderBookFeeder)
+	if !ok {
+		log.Warn("order book subscriptions ignored: exchange does not support order book feeds")
+		return
+	}
+
+	for pair := range d.OrderBookSubscriptions {
+		go d.syncOrderBook(context.Background(), feeder, pair)
+	}
+}
+
+// syncOrderBook keeps a local order book of the pair, from a snapshot and the diffs of the stream.
+// A new snapshot is fetched when the stream starts, or when an update is missing in the sequence.
+func (d *DataFeedSubscription) syncOrderBook(ctx context.Context, feeder service.OrderBookFeeder, pair string) {
+	var (
+		book   model.OrderBook
+		synced bool
+		err    error
+	)
+
+	depth := d.OrderBookDepth[pair]
+	cbook, cerr := feeder.OrderBookSubscription(ctx, pair)
+	for {
+		select {
+		case diff, ok := <-cbook:
+			if !ok {
+				return
+			}
+
+			if diff.Snapshot {
+				book, synced = diff, true
+			} else if !synced || !applyOrderBookDiff(&book, diff) {
+				book, err = feeder.OrderBook(ctx, pair, orderBookSnapshotLimit)
+				if err != nil {
+					log.Errorf("order book snapshot of %s: %s", pair, err)
+					synced = false
+					continue
+				}
+
+				synced = applyOrderBookDiff(&book, diff)
+				if !synced {
+					continue
+				}
+			}
+
+			// diffs older than the snapshot do not change the book
+			if book.UpdateID != diff.UpdateID {
+				continue
+			}
+
+			for _, consumer := range d.OrderBookSubscriptions[pair] {
+				consumer(book.Depth(depth))
+			}
+		case err := <-cerr:
+			if err != nil {
+				log.Error("dataFeedSubscription/orderBook: ", err)
+			}
+		}
+	}
+}
+
+// applyOrderBookDiff applies a diff to the book, if it is the next in the sequence of updates.
+// It returns false if there are missing updates between the book and the diff.
+func applyOrderBookDiff(book *model.OrderBook, diff model.OrderBook) bool {
+	if diff.UpdateID <= book.UpdateID {
+		return true
+	}
+
+	if diff.FirstUpdateID > book.UpdateID+1 {
+		return false
+	}
+
+	book.Apply(diff)
+	return true
+}
+
+func (d *DataFeedSubscription) startTrades() {
+	if len(d.TradeSubscriptions) == 0 {
+		return
+	}
+
+	feeder, ok := d.marketFeeder().(service.TradeFeeder)
+	if !ok {
+		log.Warn("trade subscriptions ignored: exchange does not support trade feeds")
+		return
+	}
+
+	for pair, consumers := range d.TradeSubscriptions {
+		ctrade, cerr := feeder.TradeSubscription(context.Background(), pair)
+		go func(consumers []TradeConsumer) {
+			for {
+				select {
+				case trade, ok := <-ctrade:
+					if !ok {
+						return
+					}
+					for _, consumer := range consumers {
+						consumer(trade)
+					}
+				case err := <-cerr:
+					if err != nil {
+						log.Error("dataFeedSubscription/trades: ", err)
+					}
+				}
+			}
+		}(consumers)
+	}
+}
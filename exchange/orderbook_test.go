@@ -0,0 +1,109 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/testdata/mocks"
+)
+
+type orderBookExchange struct {
+	*mocks.Exchange
+	*mocks.OrderBookFeeder
+	*mocks.TradeFeeder
+}
+
+func TestDataFeedSubscription_OrderBook(t *testing.T) {
+	feeder := mocks.NewOrderBookFeeder(t)
+	dataFeed := NewDataFeed(orderBookExchange{Exchange: mocks.NewExchange(t), OrderBookFeeder: feeder})
+
+	cbook := make(chan model.OrderBook)
+	cerr := make(chan error)
+	feeder.EXPECT().OrderBookSubscription(mock.Anything, "BTCUSDT").Return(cbook, cerr)
+	feeder.EXPECT().OrderBook(mock.Anything, "BTCUSDT", orderBookSnapshotLimit).Return(model.OrderBook{
+		Pair:     "BTCUSDT",
+		UpdateID: 10,
+		Snapshot: true,
+		Bids:     []model.PriceLevel{{Price: 99, Quantity: 1}, {Price: 98, Quantity: 2}, {Price: 97, Quantity: 3}},
+		Asks:     []model.PriceLevel{{Price: 101, Quantity: 1}, {Price: 102, Quantity: 2}},
+	}, nil).Once()
+	feeder.EXPECT().OrderBook(mock.Anything, "BTCUSDT", orderBookSnapshotLimit).Return(model.OrderBook{
+		Pair:     "BTCUSDT",
+		UpdateID: 20,
+		Snapshot: true,
+		Bids:     []model.PriceLevel{{Price: 95, Quantity: 1}},
+		Asks:     []model.PriceLevel{{Price: 96, Quantity: 1}},
+	}, nil).Once()
+
+	books := make(chan model.OrderBook, 10)
+	dataFeed.SubscribeOrderBook("BTCUSDT", 1, func(model.OrderBook) {})
+	dataFeed.SubscribeOrderBook("BTCUSDT", 2, func(book model.OrderBook) {
+		books <- book
+	})
+	dataFeed.Start(false)
+
+	// diff included in the snapshot
+	cbook <- model.OrderBook{Pair: "BTCUSDT", FirstUpdateID: 8, UpdateID: 9}
+
+	cbook <- model.OrderBook{
+		Pair:          "BTCUSDT",
+		FirstUpdateID: 10,
+		UpdateID:      11,
+		Bids:          []model.PriceLevel{{Price: 99, Quantity: 0}, {Price: 100, Quantity: 5}},
+	}
+	book := <-books
+	require.Equal(t, int64(11), book.UpdateID)
+	require.Equal(t, []model.PriceLevel{{Price: 100, Quantity: 5}, {Price: 98, Quantity: 2}}, book.Bids)
+	require.Equal(t, []model.PriceLevel{{Price: 101, Quantity: 1}, {Price: 102, Quantity: 2}}, book.Asks)
+
+	// missing updates, the book is synchronized with a new snapshot
+	cbook <- model.OrderBook{Pair: "BTCUSDT", FirstUpdateID: 15, UpdateID: 16}
+	cbook <- model.OrderBook{
+		Pair:          "BTCUSDT",
+		FirstUpdateID: 21,
+		UpdateID:      22,
+		Asks:          []model.PriceLevel{{Price: 95.5, Quantity: 2}},
+	}
+	book = <-books
+	require.Equal(t, int64(22), book.UpdateID)
+	require.Equal(t, []model.PriceLevel{{Price: 95, Quantity: 1}}, book.Bids)
+	require.Equal(t, []model.PriceLevel{{Price: 95.5, Quantity: 2}, {Price: 96, Quantity: 1}}, book.Asks)
+	require.Empty(t, books)
+
+	close(cbook)
+}
+
+func TestDataFeedSubscription_Trades(t *testing.T) {
+	t.Run("trade feeder", func(t *testing.T) {
+		feeder := mocks.NewTradeFeeder(t)
+		dataFeed := NewDataFeed(orderBookExchange{Exchange: mocks.NewExchange(t), TradeFeeder: feeder})
+
+		ctrade := make(chan model.TradeTick)
+		feeder.EXPECT().TradeSubscription(mock.Anything, "BTCUSDT").Return(ctrade, make(chan error))
+
+		trades := make(chan model.TradeTick, 1)
+		dataFeed.SubscribeTrades("BTCUSDT", func(trade model.TradeTick) {
+			trades <- trade
+		})
+		dataFeed.Start(false)
+
+		trade := model.TradeTick{ID: 1, Pair: "BTCUSDT", Time: time.Now(), Price: 100, Quantity: 1}
+		ctrade <- trade
+		require.Equal(t, trade, <-trades)
+		close(ctrade)
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100),
+			WithDataFeed(mocks.NewFeeder(t)))
+		dataFeed := NewDataFeed(wallet)
+		dataFeed.SubscribeTrades("BTCUSDT", func(model.TradeTick) {})
+		dataFeed.SubscribeOrderBook("BTCUSDT", 10, func(model.OrderBook) {})
+		dataFeed.Start(true)
+	})
+}
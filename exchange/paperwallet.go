@@ -2,14 +2,15 @@ package exchange
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/adshao/go-binance/v2/common"
+	"github.com/samber/lo"
 
 	"github.com/rodrigo-brito/ninjabot/model"
 	"github.com/rodrigo-brito/ninjabot/service"
@@ -44,6 +45,53 @@ type PaperWallet struct {
 	fistCandle    map[string]model.Candle
 	assetValues   map[string][]AssetValue
 	equityValues  []AssetValue
+	benchmark     []AssetValue
+
+	// fill simulation
+	slippageModel SlippageModel
+	volumeLimit   float64
+	slippage      map[string]float64
+	intrabar      IntrabarModel
+
+	// distance of trailing stop orders, by exchange ID
+	trailingDistance map[int64]float64
+
+	// margin positions of the futures mode, nil in spot mode
+	futures *futuresWallet
+
+	// order updates subscriptions, and the queue of synchronous consumers (see OrderUpdates)
+	orderSubscribers []*orderQueue
+	orderUpdates     *orderQueue
+}
+
+// orderQueue is an unbounded queue of order updates, so updates are never dropped by slow consumers
+type orderQueue struct {
+	sync.Mutex
+	orders []model.Order
+	signal chan struct{}
+}
+
+func newOrderQueue() *orderQueue {
+	return &orderQueue{signal: make(chan struct{}, 1)}
+}
+
+func (q *orderQueue) push(order model.Order) {
+	q.Lock()
+	q.orders = append(q.orders, order)
+	q.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (q *orderQueue) pop() []model.Order {
+	q.Lock()
+	defer q.Unlock()
+	orders := q.orders
+	q.orders = nil
+	return orders
 }
 
 func (p *PaperWallet) AssetsInfo(pair string) model.AssetInfo {
@@ -78,9 +126,14 @@ func WithPaperFee(maker, taker float64) PaperWalletOption {
 	}
 }
 
+// WithDataFeed sets the feed of candles. Feeds with the price path of the candles, such as TradeFeed,
+// are also used as intrabar model, unless another model is set with WithPaperIntrabar.
 func WithDataFeed(feeder service.Feeder) PaperWalletOption {
 	return func(wallet *PaperWallet) {
 		wallet.feeder = feeder
+		if intrabar, ok := feeder.(IntrabarModel); ok && wallet.intrabar == nil {
+			wallet.intrabar = intrabar
+		}
 	}
 }
 
@@ -97,6 +150,10 @@ func NewPaperWallet(ctx context.Context, baseCoin string, options ...PaperWallet
 		volume:        make(map[string]float64),
 		assetValues:   make(map[string][]AssetValue),
 		equityValues:  make([]AssetValue, 0),
+		benchmark:     make([]AssetValue, 0),
+		slippage:      make(map[string]float64),
+
+		trailingDistance: make(map[int64]float64),
 	}
 
 	for _, option := range options {
@@ -135,6 +192,28 @@ func (p *PaperWallet) EquityValues() []AssetValue {
 	return p.equityValues
 }
 
+// BenchmarkValues returns the buy-and-hold benchmark equity: the initial portfolio equally divided
+// between the pairs, bought in the first candle of each pair
+func (p *PaperWallet) BenchmarkValues() []AssetValue {
+	return p.benchmark
+}
+
+func (p *PaperWallet) benchmarkValue() float64 {
+	if len(p.fistCandle) == 0 {
+		return p.initialValue
+	}
+
+	var change float64
+	for pair, candle := range p.fistCandle {
+		if candle.Close == 0 {
+			change++
+			continue
+		}
+		change += p.lastCandle[pair].Close / candle.Close
+	}
+	return p.initialValue * change / float64(len(p.fistCandle))
+}
+
 func (p *PaperWallet) MaxDrawdown() (float64, time.Time, time.Time) {
 	if len(p.equityValues) < 1 {
 		return 0, time.Time{}, time.Time{}
@@ -191,7 +270,9 @@ func (p *PaperWallet) Summary() {
 
 		quantity := assetInfo.Free + assetInfo.Lock
 		value := quantity * p.lastCandle[pair].Close
-		if quantity < 0 {
+		if p.futures != nil {
+			value = p.unrealizedProfit(pair)
+		} else if quantity < 0 {
 			totalShort := 2.0*p.avgShortPrice[pair]*quantity - p.lastCandle[pair].Close*quantity
 			value = math.Abs(totalShort)
 		}
@@ -221,6 +302,27 @@ func (p *PaperWallet) Summary() {
 		fmt.Printf("%s         = %.2f %s\n", pair, vol, p.baseCoin)
 	}
 	fmt.Printf("TOTAL           = %.2f %s\n", volume, p.baseCoin)
+	if p.slippageModel != nil {
+		var slippage float64
+		for _, value := range p.slippage {
+			slippage += value
+		}
+		fmt.Println()
+		fmt.Println("------ COSTS ------")
+		fmt.Printf("SLIPPAGE        = %.2f %s\n", slippage, p.baseCoin)
+	}
+	if p.futures != nil {
+		var funding float64
+		for _, value := range p.futures.funding {
+			funding += value
+		}
+		fmt.Println()
+		fmt.Println("----- FUTURES -----")
+		fmt.Printf("FUNDING         = %.2f %s\n", funding, p.baseCoin)
+		for pair, count := range p.futures.liquidations {
+			fmt.Printf("LIQUIDATIONS    = %d %s\n", count, pair)
+		}
+	}
 	fmt.Println("-------------------")
 }
 
@@ -234,6 +336,10 @@ func (p *PaperWallet) validateFunds(side model.SideType, pair string, amount, va
 		p.assets[quote] = &assetInfo{}
 	}
 
+	if p.futures != nil {
+		return p.validateMargin(side, pair, amount, value, fill)
+	}
+
 	funds := p.assets[quote].Free
 	if side == model.SideTypeSell {
 		if p.assets[asset].Free > 0 {
@@ -369,6 +475,55 @@ func (p *PaperWallet) updateAveragePrice(side model.SideType, pair string, amoun
 	}
 }
 
+func isLimitOrder(order model.Order) bool {
+	return order.Type == model.OrderTypeLimit ||
+		order.Type == model.OrderTypeLimitMaker ||
+		order.Type == model.OrderTypeTakeProfit ||
+		order.Type == model.OrderTypeTakeProfitLimit
+}
+
+func isStopOrder(order model.Order) bool {
+	return order.Type == model.OrderTypeStopLossLimit || order.Type == model.OrderTypeStopLoss
+}
+
+// triggerPosition returns the position in the candle price path where the order is triggered, or -1 if the order
+// is not triggered. Without a price path, all triggered orders have the same position.
+func (p *PaperWallet) triggerPosition(order model.Order, candle model.Candle, path []float64) int {
+	// market orders and triggered stop orders with remaining quantity
+	if order.Type == model.OrderTypeMarket || (isStopOrder(order) &&
+		order.Status == model.OrderStatusTypePartiallyFilled) {
+		return 0
+	}
+
+	var triggered func(price float64) bool
+	switch {
+	case order.Side == model.SideTypeBuy:
+		if path == nil {
+			return lo.Ternary(order.Price >= candle.Close, 0, -1)
+		}
+		triggered = func(price float64) bool { return price <= order.Price }
+	case isLimitOrder(order):
+		triggered = func(price float64) bool { return price >= order.Price }
+		if path == nil {
+			return lo.Ternary(triggered(candle.High), 0, -1)
+		}
+	case isStopOrder(order):
+		triggered = func(price float64) bool { return price <= *order.Stop }
+		if path == nil {
+			return lo.Ternary(triggered(candle.Low), 0, -1)
+		}
+	default:
+		return -1
+	}
+
+	for position, price := range path {
+		if triggered(price) {
+			return position
+		}
+	}
+	return -1
+}
+
 func (p *PaperWallet) OnCandle(candle model.Candle) {
 	p.Lock()
 	defer p.Unlock()
@@ -378,8 +533,50 @@ func (p *PaperWallet) OnCandle(candle model.Candle) {
 		p.fistCandle[candle.Pair] = candle
 	}
 
+	if p.futures != nil {
+		p.applyFunding(candle)
+	}
+
+	// orders triggered in the candle, sorted by the position of the trigger in the price path
+	var path []float64
+	if p.intrabar != nil {
+		path = p.intrabar.Path(candle)
+	}
+
+	type triggeredOrder struct {
+		index    int
+		position int
+	}
+
+	triggered := make([]triggeredOrder, 0)
 	for i, order := range p.orders {
-		if order.Pair != candle.Pair || order.Status != model.OrderStatusTypeNew {
+		if order.Pair != candle.Pair || (order.Status != model.OrderStatusTypeNew &&
+			order.Status != model.OrderStatusTypePartiallyFilled) {
+			continue
+		}
+
+		position := -1
+		if order.Type == model.OrderTypeTrailingStop {
+			position = p.trailPosition(&p.orders[i], candle, path)
+		} else {
+			position = p.triggerPosition(order, candle, path)
+		}
+
+		if position >= 0 {
+			triggered = append(triggered, triggeredOrder{index: i, position: position})
+		}
+	}
+
+	sort.SliceStable(triggered, func(i, j int) bool {
+		return triggered[i].position < triggered[j].position
+	})
+
+	for _, trigger := range triggered {
+		i := trigger.index
+		order := p.orders[i]
+
+		// order canceled by other order of the same group
+		if order.Status == model.OrderStatusTypeCanceled {
 			continue
 		}
 
@@ -387,65 +584,56 @@ func (p *PaperWallet) OnCandle(candle model.Candle) {
 			p.volume[candle.Pair] = 0
 		}
 
-		asset, quote := SplitAssetQuote(order.Pair)
-		if order.Side == model.SideTypeBuy && order.Price >= candle.Close {
-			if _, ok := p.assets[asset]; !ok {
-				p.assets[asset] = &assetInfo{}
-			}
-
-			p.volume[candle.Pair] += order.Price * order.Quantity
-			p.orders[i].UpdatedAt = candle.Time
-			p.orders[i].Status = model.OrderStatusTypeFilled
-
-			// update assets size
-			p.updateAveragePrice(order.Side, order.Pair, order.Quantity, order.Price)
-			p.assets[asset].Free = p.assets[asset].Free + order.Quantity
-			p.assets[quote].Lock = p.assets[quote].Lock - order.Price*order.Quantity
-		}
-
-		if order.Side == model.SideTypeSell {
-			var orderPrice float64
-			if (order.Type == model.OrderTypeLimit ||
-				order.Type == model.OrderTypeLimitMaker ||
-				order.Type == model.OrderTypeTakeProfit ||
-				order.Type == model.OrderTypeTakeProfitLimit) &&
-				candle.High >= order.Price {
-				orderPrice = order.Price
-			} else if (order.Type == model.OrderTypeStopLossLimit ||
-				order.Type == model.OrderTypeStopLoss) &&
-				candle.Low <= *order.Stop {
-				orderPrice = *order.Stop
-			} else {
-				continue
-			}
+		quantity := p.fillQuantity(order.Quantity-order.ExecutedQuantity, candle)
 
-			// Cancel other orders from same group
-			if order.GroupID != nil {
-				for j, groupOrder := range p.orders {
-					if groupOrder.GroupID != nil && *groupOrder.GroupID == *order.GroupID &&
-						groupOrder.ExchangeID != order.ExchangeID {
-						p.orders[j].Status = model.OrderStatusTypeCanceled
-						p.orders[j].UpdatedAt = candle.Time
-						break
-					}
-				}
-			}
+		// remaining quantity of market orders limited by the candle volume
+		if order.Type == model.OrderTypeMarket {
+			price := p.executionPrice(order.Side, candle.Close, candle)
+			p.fill(&p.orders[i], quantity, price, order.RefPrice, candle.Close, candle)
+			p.publishOrder(p.orders[i])
+			continue
+		}
 
-			if _, ok := p.assets[quote]; !ok {
-				p.assets[quote] = &assetInfo{}
-			}
+		// triggered trailing stops are executed at market price
+		if order.Type == model.OrderTypeTrailingStop {
+			price := p.executionPrice(order.Side, *order.Stop, candle)
+			p.fill(&p.orders[i], quantity, price, order.Price, *order.Stop, candle)
+			p.publishOrder(p.orders[i])
+			continue
+		}
 
-			orderVolume := order.Quantity * orderPrice
+		if order.Side == model.SideTypeBuy {
+			p.fill(&p.orders[i], quantity, order.Price, order.Price, order.Price, candle)
+			p.publishOrder(p.orders[i])
+			continue
+		}
 
-			p.volume[candle.Pair] += orderVolume
-			p.orders[i].UpdatedAt = candle.Time
-			p.orders[i].Status = model.OrderStatusTypeFilled
+		orderPrice, refPrice := order.Price, order.Price
+		if isStopOrder(order) {
+			// triggered stop orders are executed at market price
+			refPrice = *order.Stop
+			orderPrice = p.executionPrice(order.Side, refPrice, candle)
+		}
 
-			// update assets size
-			p.updateAveragePrice(order.Side, order.Pair, order.Quantity, orderPrice)
-			p.assets[asset].Lock = p.assets[asset].Lock - order.Quantity
-			p.assets[quote].Free = p.assets[quote].Free + order.Quantity*orderPrice
+		// Cancel other orders from same group
+		if order.GroupID != nil && order.Status == model.OrderStatusTypeNew {
+			for j, groupOrder := range p.orders {
+				if groupOrder.GroupID != nil && *groupOrder.GroupID == *order.GroupID &&
+					groupOrder.ExchangeID != order.ExchangeID {
+					p.orders[j].Status = model.OrderStatusTypeCanceled
+					p.orders[j].UpdatedAt = candle.Time
+					p.publishOrder(p.orders[j])
+					break
+				}
+			}
 		}
+
+		p.fill(&p.orders[i], quantity, orderPrice, order.Price, refPrice, candle)
+		p.publishOrder(p.orders[i])
+	}
+
+	if p.futures != nil {
+		p.checkLiquidation(candle)
 	}
 
 	if candle.Complete {
@@ -453,7 +641,9 @@ func (p *PaperWallet) OnCandle(candle model.Candle) {
 		for asset, info := range p.assets {
 			amount := info.Free + info.Lock
 			pair := strings.ToUpper(asset + p.baseCoin)
-			if amount < 0 {
+			if p.futures != nil {
+				total += p.unrealizedProfit(pair)
+			} else if amount < 0 {
 				v := math.Abs(amount)
 				liquid := 2*v*p.avgShortPrice[pair] - v*p.lastCandle[pair].Close
 				total += liquid
@@ -472,10 +662,19 @@ func (p *PaperWallet) OnCandle(candle model.Candle) {
 			Time:  candle.Time,
 			Value: total + baseCoinInfo.Lock + baseCoinInfo.Free,
 		})
+
+		p.benchmark = append(p.benchmark, AssetValue{
+			Time:  candle.Time,
+			Value: p.benchmarkValue(),
+		})
 	}
 }
 
 func (p *PaperWallet) Account() (model.Account, error) {
+	if p.futures != nil {
+		return p.futuresAccount(), nil
+	}
+
 	balances := make([]model.Balance, 0)
 	for pair, info := range p.assets {
 		balances = append(balances, model.Balance{
@@ -623,29 +822,42 @@ func (p *PaperWallet) createOrderMarket(side model.SideType, pair string, size f
 		return model.Order{}, ErrInvalidQuantity
 	}
 
-	err := p.validateFunds(side, pair, size, p.lastCandle[pair].Close, true)
-	if err != nil {
-		return model.Order{}, err
-	}
-
 	if _, ok := p.volume[pair]; !ok {
 		p.volume[pair] = 0
 	}
 
-	p.volume[pair] += p.lastCandle[pair].Close * size
-
+	candle := p.lastCandle[pair]
+	price := p.executionPrice(side, candle.Close, candle)
 	order := model.Order{
 		ExchangeID: p.ID(),
-		CreatedAt:  p.lastCandle[pair].Time,
-		UpdatedAt:  p.lastCandle[pair].Time,
+		CreatedAt:  candle.Time,
+		UpdatedAt:  candle.Time,
 		Pair:       pair,
 		Side:       side,
 		Type:       model.OrderTypeMarket,
-		Status:     model.OrderStatusTypeFilled,
-		Price:      p.lastCandle[pair].Close,
+		Status:     model.OrderStatusTypeNew,
 		Quantity:   size,
+		RefPrice:   candle.Close,
+	}
+
+	// with volume limit, funds are locked at the reference price and the order is filled over the next candles
+	if p.volumeLimit > 0 {
+		err := p.validateFunds(side, pair, size, candle.Close, false)
+		if err != nil {
+			return model.Order{}, err
+		}
+
+		p.fill(&order, p.fillQuantity(size, candle), price, candle.Close, candle.Close, candle)
+		p.orders = append(p.orders, order)
+		return order, nil
+	}
+
+	err := p.validateFunds(side, pair, size, price, true)
+	if err != nil {
+		return model.Order{}, err
 	}
 
+	p.registerFill(&order, size, price, candle.Close, candle)
 	p.orders = append(p.orders, order)
 
 	return order, nil
@@ -668,6 +880,12 @@ func (p *PaperWallet) Cancel(order model.Order) error {
 	for i, o := range p.orders {
 		if o.ExchangeID == order.ExchangeID {
 			p.orders[i].Status = model.OrderStatusTypeCanceled
+			p.publishOrder(p.orders[i])
+
+			// margin of pending orders is not locked in futures mode
+			if p.futures != nil {
+				continue
+			}
 
 			// unlock funds
 			assset, quote := SplitAssetQuote(o.Pair)
@@ -688,13 +906,101 @@ func (p *PaperWallet) Cancel(order model.Order) error {
 	return nil
 }
 
+// OrderSubscription streams the updates of the orders filled or canceled by the wallet,
+// until the context is canceled. Updates are queued while the subscriber is busy, they are never dropped.
+func (p *PaperWallet) OrderSubscription(ctx context.Context) (chan model.Order, chan error) {
+	corder := make(chan model.Order)
+	cerr := make(chan error)
+
+	queue := newOrderQueue()
+	p.Lock()
+	p.orderSubscribers = append(p.orderSubscribers, queue)
+	p.Unlock()
+
+	go func() {
+		defer func() {
+			p.Lock()
+			p.orderSubscribers = lo.Without(p.orderSubscribers, queue)
+			p.Unlock()
+			close(corder)
+			close(cerr)
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-queue.signal:
+				for _, order := range queue.pop() {
+					select {
+					case corder <- order:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return corder, cerr
+}
+
+// OrderUpdates returns the updates of the orders filled or canceled since the last call, in order.
+// It allows backtests to process the fills synchronously after each candle, instead of OrderSubscription.
+// Updates are only queued after the first call.
+func (p *PaperWallet) OrderUpdates() []model.Order {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.orderUpdates == nil {
+		p.orderUpdates = newOrderQueue()
+	}
+	return p.orderUpdates.pop()
+}
+
+// publishOrder queues an order update to the subscribers, it must be called with the wallet locked
+func (p *PaperWallet) publishOrder(order model.Order) {
+	for _, subscriber := range p.orderSubscribers {
+		subscriber.push(order)
+	}
+
+	if p.orderUpdates != nil {
+		p.orderUpdates.push(order)
+	}
+}
+
+// Orders returns the most recent orders of a pair
+func (p *PaperWallet) Orders(pair string, limit int) ([]model.Order, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	orders := lo.Filter(p.orders, func(order model.Order, _ int) bool {
+		return order.Pair == pair
+	})
+	if len(orders) > limit {
+		orders = orders[len(orders)-limit:]
+	}
+	return orders, nil
+}
+
+// PendingOrders returns the orders of a pair not filled or canceled yet
+func (p *PaperWallet) PendingOrders(pair string) ([]model.Order, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	return lo.Filter(p.orders, func(order model.Order, _ int) bool {
+		return order.Pair == pair && (order.Status == model.OrderStatusTypeNew ||
+			order.Status == model.OrderStatusTypePartiallyFilled)
+	}), nil
+}
+
 func (p *PaperWallet) Order(_ string, id int64) (model.Order, error) {
 	for _, order := range p.orders {
 		if order.ExchangeID == id {
 			return order, nil
 		}
 	}
-	return model.Order{}, errors.New("order not found")
+	return model.Order{}, ErrOrderNotFound
 }
 
 func (p *PaperWallet) CandlesByPeriod(ctx context.Context, pair, period string,
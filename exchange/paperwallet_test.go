@@ -598,3 +598,162 @@ func TestUpdateAveragePrice(t *testing.T) {
 	})
 
 }
+
+func TestPaperWallet_Slippage(t *testing.T) {
+	t.Run("fixed slippage", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 1000),
+			WithPaperSlippage(FixedSlippage(100)))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 100, High: 110, Low: 90})
+
+		order, err := wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderStatusTypeFilled, order.Status)
+		require.Equal(t, 101.0, order.Price)
+		require.Equal(t, 1.0, order.ExecutedQuantity)
+		require.Equal(t, 899.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Free)
+
+		order, err = wallet.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 1)
+		require.NoError(t, err)
+		require.Equal(t, 99.0, order.Price)
+		require.Equal(t, 998.0, wallet.assets["USDT"].Free)
+		require.InDelta(t, 2.0, wallet.Slippage()["BTCUSDT"], 1e-9)
+	})
+
+	t.Run("volatility slippage", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 1000),
+			WithPaperSlippage(VolatilitySlippage(0.5)))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 100, High: 110, Low: 90})
+
+		order, err := wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+		require.InDelta(t, 110.0, order.Price, 1e-9)
+	})
+
+	t.Run("triggered stop order", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("BTC", 1),
+			WithPaperAsset("USDT", 0), WithPaperSlippage(FixedSlippage(100)))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 110, High: 110, Low: 110})
+
+		order, err := wallet.CreateOrderStop("BTCUSDT", 1, 100)
+		require.NoError(t, err)
+
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 95, High: 105, Low: 90})
+		order, err = wallet.Order("BTCUSDT", order.ExchangeID)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderStatusTypeFilled, order.Status)
+		require.Equal(t, 99.0, order.Price)
+		require.Equal(t, 99.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["BTC"].Lock)
+	})
+}
+
+func TestPaperWallet_VolumeLimit(t *testing.T) {
+	t.Run("market order", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 1000),
+			WithPaperVolumeLimit(0.1))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 100, High: 100, Low: 100, Volume: 5})
+
+		order, err := wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderStatusTypePartiallyFilled, order.Status)
+		require.Equal(t, 0.5, order.ExecutedQuantity)
+		require.Equal(t, 0.5, wallet.assets["BTC"].Free)
+		require.Equal(t, 900.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 50.0, wallet.assets["USDT"].Lock)
+
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 110, High: 110, Low: 110, Volume: 5})
+		order, err = wallet.Order("BTCUSDT", order.ExchangeID)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderStatusTypeFilled, order.Status)
+		require.Equal(t, 1.0, order.ExecutedQuantity)
+		require.Equal(t, 105.0, order.Price)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 895.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["USDT"].Lock)
+	})
+
+	t.Run("limit order", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("BTC", 1),
+			WithPaperAsset("USDT", 0), WithPaperVolumeLimit(0.1))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 100, High: 100, Low: 100, Volume: 4})
+
+		order, err := wallet.CreateOrderLimit(model.SideTypeSell, "BTCUSDT", 1, 110)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 110, High: 115, Low: 105, Volume: 4})
+		}
+
+		order, err = wallet.Order("BTCUSDT", order.ExchangeID)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderStatusTypeFilled, order.Status)
+		require.Equal(t, 110.0, order.Price)
+		require.InDelta(t, 0.0, wallet.assets["BTC"].Lock, 1e-9)
+		require.InDelta(t, 110.0, wallet.assets["USDT"].Free, 1e-9)
+	})
+}
+
+func TestPaperWallet_BenchmarkValues(t *testing.T) {
+	wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 1000))
+	wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 100, Complete: true})
+	wallet.OnCandle(model.Candle{Pair: "ETHUSDT", Close: 10, Complete: true})
+	wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 150, Complete: false})
+	wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 200, Complete: true})
+	wallet.OnCandle(model.Candle{Pair: "ETHUSDT", Close: 5, Complete: true})
+
+	values := wallet.BenchmarkValues()
+	require.Len(t, values, 4)
+	require.InDelta(t, 1000, values[0].Value, 1e-9)
+	require.InDelta(t, 1000, values[1].Value, 1e-9)
+	require.InDelta(t, 1500, values[2].Value, 1e-9) // BTC x2 and ETH x1
+	require.InDelta(t, 1250, values[3].Value, 1e-9) // BTC x2 and ETH x0.5
+}
+
+func TestPaperWallet_OrderSubscription(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wallet := NewPaperWallet(ctx, "USDT", WithPaperAsset("USDT", 100))
+	orders, _ := wallet.OrderSubscription(ctx)
+
+	wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 10, High: 10, Low: 10})
+	limit, err := wallet.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 8)
+	require.NoError(t, err)
+	other, err := wallet.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 5)
+	require.NoError(t, err)
+
+	wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 7, High: 10, Low: 7})
+	update := <-orders
+	require.Equal(t, limit.ExchangeID, update.ExchangeID)
+	require.Equal(t, model.OrderStatusTypeFilled, update.Status)
+
+	require.NoError(t, wallet.Cancel(other))
+	update = <-orders
+	require.Equal(t, other.ExchangeID, update.ExchangeID)
+	require.Equal(t, model.OrderStatusTypeCanceled, update.Status)
+
+	cancel()
+	_, ok := <-orders
+	require.False(t, ok)
+}
+
+func TestPaperWallet_OrderUpdates(t *testing.T) {
+	wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100))
+	require.Empty(t, wallet.OrderUpdates())
+
+	wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 10, High: 10, Low: 10})
+	limit, err := wallet.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 8)
+	require.NoError(t, err)
+	other, err := wallet.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 5)
+	require.NoError(t, err)
+
+	wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 7, High: 10, Low: 7})
+	require.NoError(t, wallet.Cancel(other))
+
+	updates := wallet.OrderUpdates()
+	require.Len(t, updates, 2)
+	require.Equal(t, limit.ExchangeID, updates[0].ExchangeID)
+	require.Equal(t, model.OrderStatusTypeFilled, updates[0].Status)
+	require.Equal(t, other.ExchangeID, updates[1].ExchangeID)
+	require.Equal(t, model.OrderStatusTypeCanceled, updates[1].Status)
+	require.Empty(t, wallet.OrderUpdates())
+}
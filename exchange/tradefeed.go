@@ -0,0 +1,358 @@
+package exchange
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xhit/go-str2duration/v2"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+// columns of the aggregated trades files from Binance public data, without header:
+// agg_trade_id, price, quantity, first_trade_id, last_trade_id, transact_time, is_buyer_maker, [is_best_match]
+var binanceTradeColumns = map[string]int{
+	"id": 0, "price": 1, "quantity": 2, "time": 5, "buyer_maker": 6,
+}
+
+// columns of the trades files written by download.Recorder, without header
+var recordedTradeColumns = map[string]int{
+	"time": 0, "id": 1, "price": 2, "quantity": 3, "buyer_maker": 4,
+}
+
+// alternative names of the trades headers
+var tradeHeaderAliases = map[string]string{
+	"agg_trade_id":   "id",
+	"transact_time":  "time",
+	"timestamp":      "time",
+	"qty":            "quantity",
+	"is_buyer_maker": "buyer_maker",
+}
+
+type PairTradeFeed struct {
+	Pair string
+	File string
+}
+
+// TradeFeed is a data feed of trade ticks, loaded from CSV files. The candles of any timeframe are built
+// from the trades: each trade, or group of trades in the same millisecond, updates a partial candle, and
+// the candle is complete at the end of its period. Periods without trades have a flat candle with zero volume.
+//
+// The feed is also an IntrabarModel, used by the paper wallet to execute the orders at the trade prices.
+// It is set automatically by WithDataFeed.
+type TradeFeed struct {
+	Feeds map[string]PairTradeFeed
+
+	trades  map[string][]model.TradeTick
+	candles map[string][]model.Candle
+}
+
+// NewTradeFeed creates a new data feed from trades CSV files. The files can have a header with the columns
+// time, id, price, quantity and buyer_maker, as written by download.Recorder, or follow the format of the
+// aggregated trades of Binance public data. Times are in milliseconds, or in microseconds.
+func NewTradeFeed(feeds ...PairTradeFeed) (*TradeFeed, error) {
+	tradeFeed := &TradeFeed{
+		Feeds:   make(map[string]PairTradeFeed),
+		trades:  make(map[string][]model.TradeTick),
+		candles: make(map[string][]model.Candle),
+	}
+
+	for _, feed := range feeds {
+		trades, err := readTrades(feed.File, feed.Pair)
+		if err != nil {
+			return nil, err
+		}
+
+		tradeFeed.Feeds[feed.Pair] = feed
+		tradeFeed.trades[feed.Pair] = trades
+	}
+
+	return tradeFeed, nil
+}
+
+func readTrades(file, pair string) ([]model.TradeTick, error) {
+	csvFile, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer csvFile.Close()
+
+	reader := csv.NewReader(csvFile)
+	reader.FieldsPerRecord = -1
+	lines, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientData, file)
+	}
+
+	columns := binanceTradeColumns
+	if len(lines[0]) == len(recordedTradeColumns) {
+		columns = recordedTradeColumns
+	}
+
+	if _, err := strconv.ParseFloat(lines[0][0], 64); err != nil {
+		columns = make(map[string]int)
+		for index, header := range lines[0] {
+			header = strings.ToLower(strings.TrimSpace(header))
+			if alias, ok := tradeHeaderAliases[header]; ok {
+				header = alias
+			}
+			columns[header] = index
+		}
+		lines = lines[1:]
+
+		for _, column := range []string{"time", "price", "quantity"} {
+			if _, ok := columns[column]; !ok {
+				return nil, fmt.Errorf("missing column %s in %s", column, file)
+			}
+		}
+	}
+
+	trades := make([]model.TradeTick, 0, len(lines))
+	for _, line := range lines {
+		timestamp, err := strconv.ParseInt(line[columns["time"]], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// timestamps in microseconds
+		tradeTime := time.UnixMilli(timestamp).UTC()
+		if timestamp > 1e14 {
+			tradeTime = time.UnixMicro(timestamp).UTC()
+		}
+
+		trade := model.TradeTick{
+			Pair: pair,
+			Time: tradeTime,
+		}
+
+		if index, ok := columns["id"]; ok {
+			trade.ID, err = strconv.ParseInt(line[index], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		trade.Price, err = strconv.ParseFloat(line[columns["price"]], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		trade.Quantity, err = strconv.ParseFloat(line[columns["quantity"]], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if index, ok := columns["buyer_maker"]; ok {
+			trade.BuyerMaker, err = strconv.ParseBool(strings.TrimSpace(line[index]))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		trades = append(trades, trade)
+	}
+
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	return trades, nil
+}
+
+func (t *TradeFeed) feedTimeframeKey(pair, timeframe string) string {
+	return fmt.Sprintf("%s--%s", pair, timeframe)
+}
+
+// Trades returns the trades of a pair not consumed yet
+func (t *TradeFeed) Trades(pair string) []model.TradeTick {
+	return t.trades[pair]
+}
+
+// Path returns the prices of the trades of a partial candle update, in execution order. Complete candles have an
+// empty path, since their trades were replayed by the partial candles.
+func (t *TradeFeed) Path(candle model.Candle) []float64 {
+	path := make([]float64, 0)
+	if candle.Complete {
+		return path
+	}
+
+	trades := t.trades[candle.Pair]
+	first := sort.Search(len(trades), func(i int) bool {
+		return !trades[i].Time.Before(candle.UpdatedAt)
+	})
+
+	for i := first; i < len(trades) && trades[i].Time.Equal(candle.UpdatedAt); i++ {
+		path = append(path, trades[i].Price)
+	}
+	return path
+}
+
+// candleOpenTime returns the open time of the candle period of a timeframe, weeks start on Monday
+func candleOpenTime(t time.Time, timeframe string, duration time.Duration) time.Time {
+	if timeframe == "1w" {
+		day := t.UTC().Truncate(24 * time.Hour)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return t.UTC().Truncate(duration)
+}
+
+// loadTimeframe builds the partial and complete candles of a timeframe from the trades of a pair
+func (t *TradeFeed) loadTimeframe(pair, timeframe string) error {
+	key := t.feedTimeframeKey(pair, timeframe)
+	if _, ok := t.candles[key]; ok {
+		return nil
+	}
+
+	trades, ok := t.trades[pair]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInsufficientData, pair)
+	}
+
+	duration, err := str2duration.ParseDuration(timeframe)
+	if err != nil {
+		return err
+	}
+
+	candles := make([]model.Candle, 0)
+	var current model.Candle
+	for i, trade := range trades {
+		openTime := candleOpenTime(trade.Time, timeframe, duration)
+		if !current.Time.IsZero() && !openTime.Equal(current.Time) {
+			current.Complete = true
+			current.UpdatedAt = current.Time.Add(duration)
+			candles = append(candles, current)
+
+			// flat candles for the periods without trades
+			for next := current.Time.Add(duration); next.Before(openTime); next = next.Add(duration) {
+				candles = append(candles, model.Candle{
+					Pair:      pair,
+					Time:      next,
+					UpdatedAt: next.Add(duration),
+					Open:      current.Close,
+					Close:     current.Close,
+					Low:       current.Close,
+					High:      current.Close,
+					Complete:  true,
+				})
+			}
+			current = model.Candle{}
+		}
+
+		if current.Time.IsZero() {
+			current = model.Candle{
+				Pair: pair,
+				Time: openTime,
+				Open: trade.Price,
+				Low:  trade.Price,
+				High: trade.Price,
+			}
+		}
+
+		current.UpdatedAt = trade.Time
+		current.Close = trade.Price
+		current.Low = math.Min(current.Low, trade.Price)
+		current.High = math.Max(current.High, trade.Price)
+		current.Volume += trade.Quantity
+
+		// trades in the same millisecond are grouped in one update
+		if i == len(trades)-1 || !trades[i+1].Time.Equal(trade.Time) {
+			candles = append(candles, current)
+		}
+	}
+
+	// the period of the last trade is not complete
+	t.candles[key] = candles
+	return nil
+}
+
+func (t *TradeFeed) AssetsInfo(pair string) model.AssetInfo {
+	return CSVFeed{}.AssetsInfo(pair)
+}
+
+func (t *TradeFeed) LastQuote(_ context.Context, _ string) (float64, error) {
+	return 0, errors.New("invalid operation")
+}
+
+// CandlesByPeriod returns the complete candles opened between start and end
+func (t *TradeFeed) CandlesByPeriod(_ context.Context, pair, timeframe string,
+	start, end time.Time) ([]model.Candle, error) {
+	if err := t.loadTimeframe(pair, timeframe); err != nil {
+		return nil, err
+	}
+
+	candles := make([]model.Candle, 0)
+	for _, candle := range t.candles[t.feedTimeframeKey(pair, timeframe)] {
+		if !candle.Complete || candle.Time.Before(start) || candle.Time.After(end) {
+			continue
+		}
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+// CandlesByLimit returns the first complete candles, and removes their trades from the feed
+func (t *TradeFeed) CandlesByLimit(_ context.Context, pair, timeframe string, limit int) ([]model.Candle, error) {
+	if err := t.loadTimeframe(pair, timeframe); err != nil {
+		return nil, err
+	}
+
+	result := make([]model.Candle, 0, limit)
+	for _, candle := range t.candles[t.feedTimeframeKey(pair, timeframe)] {
+		if len(result) == limit {
+			break
+		}
+		if candle.Complete {
+			result = append(result, candle)
+		}
+	}
+
+	if len(result) < limit {
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientData, pair)
+	}
+
+	end := result[len(result)-1].UpdatedAt
+	trades := t.trades[pair]
+	t.trades[pair] = trades[sort.Search(len(trades), func(i int) bool {
+		return !trades[i].Time.Before(end)
+	}):]
+
+	// candles are rebuilt from the remaining trades
+	for key := range t.candles {
+		if strings.HasPrefix(key, pair+"--") {
+			delete(t.candles, key)
+		}
+	}
+
+	return result, nil
+}
+
+// CandlesSubscription replays the partial and complete candles of a timeframe
+func (t *TradeFeed) CandlesSubscription(_ context.Context, pair, timeframe string) (chan model.Candle, chan error) {
+	ccandle := make(chan model.Candle)
+	cerr := make(chan error)
+	err := t.loadTimeframe(pair, timeframe)
+	candles := t.candles[t.feedTimeframeKey(pair, timeframe)]
+	go func() {
+		if err != nil {
+			cerr <- err
+		}
+		for _, candle := range candles {
+			ccandle <- candle
+		}
+		close(ccandle)
+		close(cerr)
+	}()
+	return ccandle, cerr
+}
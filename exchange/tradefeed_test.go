@@ -0,0 +1,172 @@
+package exchange
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+func writeTradesFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "trades.csv")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func TestNewTradeFeed(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("recorder format", func(t *testing.T) {
+		file := writeTradesFile(t, "time,id,price,quantity,buyer_maker\n"+
+			"1672531200500,2,16500,1,true\n1672531200123,1,16500.5,0.25,false\n")
+
+		feed, err := NewTradeFeed(PairTradeFeed{Pair: "BTCUSDT", File: file})
+		require.NoError(t, err)
+		require.Equal(t, []model.TradeTick{
+			{ID: 1, Pair: "BTCUSDT", Time: start.Add(123 * time.Millisecond), Price: 16500.5, Quantity: 0.25},
+			{ID: 2, Pair: "BTCUSDT", Time: start.Add(500 * time.Millisecond), Price: 16500, Quantity: 1,
+				BuyerMaker: true},
+		}, feed.Trades("BTCUSDT"))
+	})
+
+	t.Run("binance format", func(t *testing.T) {
+		file := writeTradesFile(t, "10,16500.5,0.25,100,101,1672531200123000,False,True\n")
+
+		feed, err := NewTradeFeed(PairTradeFeed{Pair: "BTCUSDT", File: file})
+		require.NoError(t, err)
+		require.Equal(t, []model.TradeTick{
+			{ID: 10, Pair: "BTCUSDT", Time: start.Add(123 * time.Millisecond), Price: 16500.5, Quantity: 0.25},
+		}, feed.Trades("BTCUSDT"))
+	})
+
+	t.Run("binance format with header", func(t *testing.T) {
+		file := writeTradesFile(t, "agg_trade_id,price,quantity,first_trade_id,last_trade_id,transact_time,"+
+			"is_buyer_maker\n10,16500.5,0.25,100,101,1672531200123,true\n")
+
+		feed, err := NewTradeFeed(PairTradeFeed{Pair: "BTCUSDT", File: file})
+		require.NoError(t, err)
+		require.Equal(t, []model.TradeTick{
+			{ID: 10, Pair: "BTCUSDT", Time: start.Add(123 * time.Millisecond), Price: 16500.5, Quantity: 0.25,
+				BuyerMaker: true},
+		}, feed.Trades("BTCUSDT"))
+	})
+
+	t.Run("invalid files", func(t *testing.T) {
+		_, err := NewTradeFeed(PairTradeFeed{Pair: "BTCUSDT", File: "not-found.csv"})
+		require.Error(t, err)
+
+		_, err = NewTradeFeed(PairTradeFeed{Pair: "BTCUSDT", File: writeTradesFile(t, "time,id,value\n1,2,3\n")})
+		require.ErrorContains(t, err, "missing column price")
+	})
+}
+
+func newTestTradeFeed(t *testing.T) *TradeFeed {
+	file := writeTradesFile(t, "time,id,price,quantity,buyer_maker\n"+
+		"1672531210000,1,100,1,false\n"+
+		"1672531210000,2,101,1,false\n"+
+		"1672531230000,3,99,1,true\n"+
+		"1672531325000,4,103,2,false\n")
+
+	feed, err := NewTradeFeed(PairTradeFeed{Pair: "BTCUSDT", File: file})
+	require.NoError(t, err)
+	return feed
+}
+
+func TestTradeFeed_Candles(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("subscription", func(t *testing.T) {
+		feed := newTestTradeFeed(t)
+		ccandle, _ := feed.CandlesSubscription(context.Background(), "BTCUSDT", "1m")
+
+		candles := make([]model.Candle, 0)
+		for candle := range ccandle {
+			candles = append(candles, candle)
+		}
+
+		require.Equal(t, []model.Candle{
+			{Pair: "BTCUSDT", Time: start, UpdatedAt: start.Add(10 * time.Second), Open: 100, Close: 101,
+				Low: 100, High: 101, Volume: 2},
+			{Pair: "BTCUSDT", Time: start, UpdatedAt: start.Add(30 * time.Second), Open: 100, Close: 99,
+				Low: 99, High: 101, Volume: 3},
+			{Pair: "BTCUSDT", Time: start, UpdatedAt: start.Add(time.Minute), Open: 100, Close: 99,
+				Low: 99, High: 101, Volume: 3, Complete: true},
+			{Pair: "BTCUSDT", Time: start.Add(time.Minute), UpdatedAt: start.Add(2 * time.Minute), Open: 99,
+				Close: 99, Low: 99, High: 99, Complete: true},
+			{Pair: "BTCUSDT", Time: start.Add(2 * time.Minute), UpdatedAt: start.Add(125 * time.Second),
+				Open: 103, Close: 103, Low: 103, High: 103, Volume: 2},
+		}, candles)
+	})
+
+	t.Run("by period", func(t *testing.T) {
+		feed := newTestTradeFeed(t)
+		candles, err := feed.CandlesByPeriod(context.Background(), "BTCUSDT", "1m", start, start.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, candles, 2)
+		require.True(t, candles[0].Complete)
+		require.True(t, candles[1].Complete)
+
+		_, err = feed.CandlesByPeriod(context.Background(), "ETHUSDT", "1m", start, start.Add(time.Hour))
+		require.ErrorIs(t, err, ErrInsufficientData)
+	})
+
+	t.Run("by limit", func(t *testing.T) {
+		feed := newTestTradeFeed(t)
+		candles, err := feed.CandlesByLimit(context.Background(), "BTCUSDT", "1m", 1)
+		require.NoError(t, err)
+		require.Len(t, candles, 1)
+		require.Equal(t, 99.0, candles[0].Close)
+		require.Len(t, feed.Trades("BTCUSDT"), 1)
+
+		_, err = feed.CandlesByLimit(context.Background(), "BTCUSDT", "1m", 1)
+		require.ErrorIs(t, err, ErrInsufficientData)
+	})
+
+	t.Run("weekly candles", func(t *testing.T) {
+		// 2023-01-01 is a Sunday
+		require.Equal(t, time.Date(2022, 12, 26, 0, 0, 0, 0, time.UTC),
+			candleOpenTime(start.Add(time.Hour), "1w", 7*24*time.Hour))
+		require.Equal(t, time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			candleOpenTime(start.Add(25*time.Hour), "1w", 7*24*time.Hour))
+	})
+}
+
+func TestTradeFeed_PaperWallet(t *testing.T) {
+	feed := newTestTradeFeed(t)
+	wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 1000), WithDataFeed(feed))
+	require.Equal(t, feed, wallet.intrabar)
+
+	ccandle, _ := wallet.CandlesSubscription(context.Background(), "BTCUSDT", "1m")
+	candles := make([]model.Candle, 0)
+	for candle := range ccandle {
+		candles = append(candles, candle)
+	}
+
+	require.Equal(t, []float64{100, 101}, feed.Path(candles[0]))
+	require.Empty(t, feed.Path(candles[2]))
+
+	wallet.OnCandle(candles[0])
+	buy, err := wallet.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 99.5)
+	require.NoError(t, err)
+
+	// filled by the trade at 99
+	wallet.OnCandle(candles[1])
+	require.Equal(t, model.OrderStatusTypeFilled, wallet.orders[0].Status)
+	require.Equal(t, buy.Price, wallet.orders[0].Price)
+
+	// the high of the candle was reached before the order creation
+	sell, err := wallet.CreateOrderLimit(model.SideTypeSell, "BTCUSDT", 1, 100.5)
+	require.NoError(t, err)
+	wallet.OnCandle(candles[2])
+	wallet.OnCandle(candles[3])
+	require.Equal(t, model.OrderStatusTypeNew, wallet.orders[1].Status)
+
+	wallet.OnCandle(candles[4])
+	require.Equal(t, model.OrderStatusTypeFilled, wallet.orders[1].Status)
+	require.Equal(t, sell.Price, wallet.orders[1].Price)
+}
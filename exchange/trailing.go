@@ -0,0 +1,101 @@
+package exchange
+
+import (
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+// CreateOrderTrailingStop creates a trailing stop order, executed at market price when the price moves the given
+// distance against the best price since the creation of the order. The stop price is updated in each candle.
+func (p *PaperWallet) CreateOrderTrailingStop(side model.SideType, pair string, size,
+	distance float64) (model.Order, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if size == 0 || distance <= 0 {
+		return model.Order{}, ErrInvalidQuantity
+	}
+
+	candle := p.lastCandle[pair]
+	stop := candle.Close - distance
+	if side == model.SideTypeBuy {
+		stop = candle.Close + distance
+	}
+
+	err := p.validateFunds(side, pair, size, stop, false)
+	if err != nil {
+		return model.Order{}, err
+	}
+
+	order := model.Order{
+		ExchangeID: p.ID(),
+		CreatedAt:  candle.Time,
+		UpdatedAt:  candle.Time,
+		Pair:       pair,
+		Side:       side,
+		Type:       model.OrderTypeTrailingStop,
+		Status:     model.OrderStatusTypeNew,
+		Price:      stop,
+		Stop:       &stop,
+		Quantity:   size,
+		RefPrice:   candle.Close,
+	}
+	p.orders = append(p.orders, order)
+	p.trailingDistance[order.ExchangeID] = distance
+	return order, nil
+}
+
+// trailPosition moves the stop of a trailing stop order with the candle prices, and returns the position in the
+// price path where the order is triggered, or -1 if the order is not triggered. Without a price path, the adverse
+// price of the candle (low for sell orders) is checked before the stop is moved with the favorable price.
+func (p *PaperWallet) trailPosition(order *model.Order, candle model.Candle, path []float64) int {
+	// triggered orders with remaining quantity
+	if order.Status == model.OrderStatusTypePartiallyFilled {
+		return 0
+	}
+
+	sell := order.Side == model.SideTypeSell
+	distance := p.trailingDistance[order.ExchangeID]
+	move := func(price float64) {
+		stop := price - distance
+		if !sell {
+			stop = price + distance
+		}
+
+		if (sell && stop > *order.Stop) || (!sell && stop < *order.Stop) {
+			// a new pointer, the previous value is shared with the published orders
+			order.Stop = &stop
+		}
+	}
+
+	triggered := func(price float64) bool {
+		if sell {
+			return price <= *order.Stop
+		}
+		return price >= *order.Stop
+	}
+
+	if path == nil {
+		adverse, favorable := candle.Low, candle.High
+		if !sell {
+			adverse, favorable = candle.High, candle.Low
+		}
+
+		if triggered(adverse) {
+			return 0
+		}
+
+		move(favorable)
+		if triggered(candle.Close) {
+			return 0
+		}
+		return -1
+	}
+
+	for position, price := range path {
+		if triggered(price) {
+			return position
+		}
+		move(price)
+	}
+	return -1
+}
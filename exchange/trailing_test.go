@@ -0,0 +1,65 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+func TestPaperWallet_CreateOrderTrailingStop(t *testing.T) {
+	t.Run("sell", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 100, Low: 100, Close: 100})
+		_, err := wallet.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+
+		order, err := wallet.CreateOrderTrailingStop(model.SideTypeSell, "BTCUSDT", 1, 5)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderTypeTrailingStop, order.Type)
+		require.Equal(t, 95.0, *order.Stop)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Lock)
+
+		// stop follows the high of the candle
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 110, Low: 100, Close: 108})
+		require.Equal(t, model.OrderStatusTypeNew, wallet.orders[1].Status)
+		require.Equal(t, 105.0, *wallet.orders[1].Stop)
+		require.Equal(t, 95.0, *order.Stop)
+
+		// stop is not moved down, and the order is executed at the stop price
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", High: 109, Low: 104, Close: 106})
+		require.Equal(t, model.OrderStatusTypeFilled, wallet.orders[1].Status)
+		require.Equal(t, 105.0, wallet.orders[1].Price)
+		require.Equal(t, 105.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 0.0, wallet.assets["BTC"].Lock)
+	})
+
+	t.Run("buy with intrabar path", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100),
+			WithPaperIntrabar(IntrabarOHLC))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Open: 50, High: 50, Low: 50, Close: 50})
+
+		order, err := wallet.CreateOrderTrailingStop(model.SideTypeBuy, "BTCUSDT", 1, 5)
+		require.NoError(t, err)
+		require.Equal(t, 55.0, *order.Stop)
+		require.Equal(t, 55.0, wallet.assets["USDT"].Lock)
+
+		// path: 50, 52, 40, 46. The stop moves to 45 in the low and triggers in the close.
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Open: 50, High: 52, Low: 40, Close: 46})
+		require.Equal(t, model.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.Equal(t, 45.0, wallet.orders[0].Price)
+		require.Equal(t, 55.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["USDT"].Lock)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Free)
+	})
+
+	t.Run("invalid distance", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", WithPaperAsset("USDT", 100))
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Close: 50})
+		_, err := wallet.CreateOrderTrailingStop(model.SideTypeBuy, "BTCUSDT", 1, 0)
+		require.ErrorIs(t, err, ErrInvalidQuantity)
+	})
+}
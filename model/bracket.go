@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+type BracketStatus string
+
+var (
+	// BracketStatusPending waits for the fill of the entry order
+	BracketStatusPending BracketStatus = "PENDING"
+	// BracketStatusActive has the take-profit and stop-loss orders submitted
+	BracketStatusActive BracketStatus = "ACTIVE"
+	// BracketStatusClosed has one of the exits filled
+	BracketStatusClosed BracketStatus = "CLOSED"
+	// BracketStatusCanceled has the entry or the exits canceled before a fill
+	BracketStatusCanceled BracketStatus = "CANCELED"
+	// BracketStatusFailed has the exits rejected by the exchange
+	BracketStatusFailed BracketStatus = "FAILED"
+)
+
+// Bracket is an entry order with attached take-profit and stop-loss exits, submitted when the entry is filled.
+// The orders are referenced by their storage ID. A stop-loss without order is triggered by the order controller.
+type Bracket struct {
+	ID         int64         `db:"id" json:"id" gorm:"primaryKey,autoIncrement"`
+	Strategy   string        `db:"strategy" json:"strategy"`
+	Pair       string        `db:"pair" json:"pair"`
+	Side       SideType      `db:"side" json:"side"`
+	Quantity   float64       `db:"quantity" json:"quantity"`
+	Price      float64       `db:"price" json:"price"`
+	TakeProfit float64       `db:"take_profit" json:"take_profit"`
+	StopLoss   float64       `db:"stop_loss" json:"stop_loss"`
+	Status     BracketStatus `db:"status" json:"status"`
+
+	EntryID      int64 `db:"entry_id" json:"entry_id"`
+	TakeProfitID int64 `db:"take_profit_id" json:"take_profit_id"`
+	StopLossID   int64 `db:"stop_loss_id" json:"stop_loss_id"`
+
+	// exits submitted as an OCO order of the exchange
+	Native bool `db:"native" json:"native"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// ExitSide returns the side of the take-profit and stop-loss orders
+func (b Bracket) ExitSide() SideType {
+	if b.Side == SideTypeBuy {
+		return SideTypeSell
+	}
+	return SideTypeBuy
+}
+
+// Open returns true while the bracket waits for the entry or the exits
+func (b Bracket) Open() bool {
+	return b.Status == BracketStatusPending || b.Status == BracketStatusActive
+}
@@ -54,6 +54,10 @@ type Dataframe struct {
 
 	// Custom user metadata
 	Metadata map[string]Series[float64]
+
+	// Higher holds the dataframes of additional timeframes, indexed by timeframe (eg: "4h").
+	// Only filled for strategies that implement `strategy.MultiTimeframeStrategy`.
+	Higher map[string]*Dataframe
 }
 
 func (df Dataframe) Sample(positions int) Dataframe {
@@ -73,6 +77,7 @@ func (df Dataframe) Sample(positions int) Dataframe {
 		Time:       df.Time[start:],
 		LastUpdate: df.LastUpdate,
 		Metadata:   make(map[string]Series[float64]),
+		Higher:     df.Higher,
 	}
 
 	for key := range df.Metadata {
@@ -93,6 +98,9 @@ type Candle struct {
 	Volume    float64
 	Complete  bool
 
+	// Timeframe of the candle, filled by the data feed subscription
+	Timeframe string
+
 	// Aditional collums from CSV inputs
 	Metadata map[string]float64
 }
@@ -133,6 +141,7 @@ func (c Candle) ToHeikinAshi(ha *HeikinAshi) Candle {
 		Complete:  c.Complete,
 		Time:      c.Time,
 		UpdatedAt: c.UpdatedAt,
+		Timeframe: c.Timeframe,
 	}
 }
 
@@ -20,6 +20,8 @@ var (
 	OrderTypeStopLossLimit   OrderType = "STOP_LOSS_LIMIT"
 	OrderTypeTakeProfit      OrderType = "TAKE_PROFIT"
 	OrderTypeTakeProfitLimit OrderType = "TAKE_PROFIT_LIMIT"
+	OrderTypeTrailingStop    OrderType = "TRAILING_STOP_MARKET"
+	OrderTypeLiquidation     OrderType = "LIQUIDATION"
 
 	OrderStatusTypeNew             OrderStatusType = "NEW"
 	OrderStatusTypePartiallyFilled OrderStatusType = "PARTIALLY_FILLED"
@@ -39,6 +41,10 @@ type Order struct {
 	Status     OrderStatusType `db:"status" json:"status"`
 	Price      float64         `db:"price" json:"price"`
 	Quantity   float64         `db:"quantity" json:"quantity"`
+	Strategy   string          `db:"strategy" json:"strategy"`
+
+	// Partially filled orders
+	ExecutedQuantity float64 `db:"executed_quantity" json:"executed_quantity"`
 
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PriceLevel is the quantity available at a price of the order book
+type PriceLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// OrderBook is a depth snapshot, or a diff with the levels changed between two updates. In diffs, levels with
+// quantity equal to zero are removed from the book. Bids are sorted from the best (highest) price, and asks from
+// the best (lowest) price.
+type OrderBook struct {
+	Pair          string       `json:"pair"`
+	Time          time.Time    `json:"time"`
+	FirstUpdateID int64        `json:"first_update_id"`
+	UpdateID      int64        `json:"update_id"`
+	Snapshot      bool         `json:"snapshot"`
+	Bids          []PriceLevel `json:"bids"`
+	Asks          []PriceLevel `json:"asks"`
+}
+
+// BestBid returns the highest bid, or an empty level if there are no bids
+func (o OrderBook) BestBid() PriceLevel {
+	if len(o.Bids) == 0 {
+		return PriceLevel{}
+	}
+	return o.Bids[0]
+}
+
+// BestAsk returns the lowest ask, or an empty level if there are no asks
+func (o OrderBook) BestAsk() PriceLevel {
+	if len(o.Asks) == 0 {
+		return PriceLevel{}
+	}
+	return o.Asks[0]
+}
+
+// Spread returns the difference between the best ask and the best bid
+func (o OrderBook) Spread() float64 {
+	if len(o.Bids) == 0 || len(o.Asks) == 0 {
+		return 0
+	}
+	return o.Asks[0].Price - o.Bids[0].Price
+}
+
+// MidPrice returns the average of the best bid and the best ask
+func (o OrderBook) MidPrice() float64 {
+	if len(o.Bids) == 0 || len(o.Asks) == 0 {
+		return 0
+	}
+	return (o.Asks[0].Price + o.Bids[0].Price) / 2
+}
+
+// Imbalance returns the difference between the bid and ask quantities of the first levels, relative to
+// the total quantity, from -1 (only asks) to 1 (only bids)
+func (o OrderBook) Imbalance(levels int) float64 {
+	var bids, asks float64
+	for i := 0; i < levels && i < len(o.Bids); i++ {
+		bids += o.Bids[i].Quantity
+	}
+	for i := 0; i < levels && i < len(o.Asks); i++ {
+		asks += o.Asks[i].Quantity
+	}
+
+	if bids+asks == 0 {
+		return 0
+	}
+	return (bids - asks) / (bids + asks)
+}
+
+// Depth returns a copy of the book limited to the first levels of each side
+func (o OrderBook) Depth(levels int) OrderBook {
+	book := o
+	book.Bids = append([]PriceLevel(nil), o.Bids[:min(levels, len(o.Bids))]...)
+	book.Asks = append([]PriceLevel(nil), o.Asks[:min(levels, len(o.Asks))]...)
+	return book
+}
+
+// Apply updates the snapshot with a diff
+func (o *OrderBook) Apply(diff OrderBook) {
+	o.Bids = applyLevels(o.Bids, diff.Bids, func(a, b float64) bool { return a > b })
+	o.Asks = applyLevels(o.Asks, diff.Asks, func(a, b float64) bool { return a < b })
+	o.Time = diff.Time
+	o.UpdateID = diff.UpdateID
+}
+
+func applyLevels(levels, changes []PriceLevel, better func(a, b float64) bool) []PriceLevel {
+	for _, change := range changes {
+		index := sort.Search(len(levels), func(i int) bool {
+			return !better(levels[i].Price, change.Price)
+		})
+
+		found := index < len(levels) && levels[index].Price == change.Price
+		switch {
+		case change.Quantity == 0 && found:
+			levels = append(levels[:index], levels[index+1:]...)
+		case change.Quantity == 0:
+			continue
+		case found:
+			levels[index].Quantity = change.Quantity
+		default:
+			levels = append(levels, PriceLevel{})
+			copy(levels[index+1:], levels[index:])
+			levels[index] = change
+		}
+	}
+	return levels
+}
+
+func (o OrderBook) String() string {
+	bid, ask := o.BestBid(), o.BestAsk()
+	return fmt.Sprintf("[%s] Bid: %f (%f) | Ask: %f (%f) | Spread: %f", o.Pair, bid.Price, bid.Quantity,
+		ask.Price, ask.Quantity, o.Spread())
+}
+
+// TradeTick is an aggregated trade: the fills of a taker order at the same price
+type TradeTick struct {
+	ID         int64     `json:"id"`
+	Pair       string    `json:"pair"`
+	Time       time.Time `json:"time"`
+	Price      float64   `json:"price"`
+	Quantity   float64   `json:"quantity"`
+	BuyerMaker bool      `json:"buyer_maker"`
+}
+
+// Side returns the side of the taker order
+func (t TradeTick) Side() SideType {
+	if t.BuyerMaker {
+		return SideTypeSell
+	}
+	return SideTypeBuy
+}
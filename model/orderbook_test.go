@@ -0,0 +1,63 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderBook(t *testing.T) {
+	book := OrderBook{
+		Pair: "BTCUSDT",
+		Bids: []PriceLevel{{Price: 100, Quantity: 3}, {Price: 99, Quantity: 1}},
+		Asks: []PriceLevel{{Price: 102, Quantity: 1}, {Price: 103, Quantity: 5}},
+	}
+
+	t.Run("prices", func(t *testing.T) {
+		require.Equal(t, PriceLevel{Price: 100, Quantity: 3}, book.BestBid())
+		require.Equal(t, PriceLevel{Price: 102, Quantity: 1}, book.BestAsk())
+		require.Equal(t, 2.0, book.Spread())
+		require.Equal(t, 101.0, book.MidPrice())
+		require.Equal(t, 0.5, book.Imbalance(1))
+		require.Equal(t, -0.2, book.Imbalance(10))
+		require.Equal(t, 0.0, OrderBook{}.Spread())
+		require.Equal(t, 0.0, OrderBook{}.Imbalance(5))
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		depth := book.Depth(1)
+		require.Equal(t, []PriceLevel{{Price: 100, Quantity: 3}}, depth.Bids)
+		require.Equal(t, []PriceLevel{{Price: 102, Quantity: 1}}, depth.Asks)
+
+		depth.Bids[0].Quantity = 10
+		require.Equal(t, 3.0, book.Bids[0].Quantity)
+	})
+
+	t.Run("apply diff", func(t *testing.T) {
+		snapshot := book.Depth(10)
+		snapshot.Apply(OrderBook{
+			UpdateID: 5,
+			Bids: []PriceLevel{
+				{Price: 99, Quantity: 0},
+				{Price: 101, Quantity: 2},
+				{Price: 98, Quantity: 0},
+				{Price: 97, Quantity: 4},
+			},
+			Asks: []PriceLevel{
+				{Price: 103, Quantity: 2},
+				{Price: 104, Quantity: 1},
+			},
+		})
+
+		require.Equal(t, int64(5), snapshot.UpdateID)
+		require.Equal(t, []PriceLevel{{Price: 101, Quantity: 2}, {Price: 100, Quantity: 3}, {Price: 97, Quantity: 4}},
+			snapshot.Bids)
+		require.Equal(t, []PriceLevel{{Price: 102, Quantity: 1}, {Price: 103, Quantity: 2}, {Price: 104, Quantity: 1}},
+			snapshot.Asks)
+	})
+}
+
+func TestTradeTick_Side(t *testing.T) {
+	require.Equal(t, SideTypeBuy, TradeTick{}.Side())
+	require.Equal(t, SideTypeSell, TradeTick{BuyerMaker: true}.Side())
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// StrategyParams are the values of the parameters of a strategy changed while the bot is running,
+// persisted to restore them on restart
+type StrategyParams struct {
+	Strategy  string         `db:"strategy" json:"strategy" gorm:"primaryKey"`
+	Params    map[string]any `db:"params" json:"params" gorm:"serializer:json"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+}
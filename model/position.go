@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Position is the open position of a strategy in a pair, persisted to restore the bot state
+type Position struct {
+	Strategy  string    `db:"strategy" json:"strategy" gorm:"primaryKey"`
+	Pair      string    `db:"pair" json:"pair" gorm:"primaryKey"`
+	Side      SideType  `db:"side" json:"side"`
+	AvgPrice  float64   `db:"avg_price" json:"avg_price"`
+	Quantity  float64   `db:"quantity" json:"quantity"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Trade is the realized result of a position reduced or closed by an order
+type Trade struct {
+	ID            int64         `db:"id" json:"id" gorm:"primaryKey,autoIncrement"`
+	Strategy      string        `db:"strategy" json:"strategy"`
+	Pair          string        `db:"pair" json:"pair"`
+	Side          SideType      `db:"side" json:"side"`
+	ProfitPercent float64       `db:"profit_percent" json:"profit_percent"`
+	ProfitValue   float64       `db:"profit_value" json:"profit_value"`
+	Duration      time.Duration `db:"duration" json:"duration"`
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+}
+
+// EquitySnapshot is the total value of the account in a quote asset at a given time
+type EquitySnapshot struct {
+	ID    int64     `db:"id" json:"id" gorm:"primaryKey,autoIncrement"`
+	Asset string    `db:"asset" json:"asset"`
+	Value float64   `db:"value" json:"value"`
+	Time  time.Time `db:"time" json:"time"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+type TrailingStopStatus string
+
+var (
+	// TrailingStopStatusActive follows the price, waiting for the stop
+	TrailingStopStatusActive TrailingStopStatus = "ACTIVE"
+	// TrailingStopStatusTriggered has the exit order executed
+	TrailingStopStatusTriggered TrailingStopStatus = "TRIGGERED"
+	// TrailingStopStatusCanceled was canceled before the stop
+	TrailingStopStatusCanceled TrailingStopStatus = "CANCELED"
+)
+
+// TrailingStop is an exit that follows the best price since its creation, keeping the stop at a fixed distance.
+// The exit is a market order executed when the price reaches the stop. Trailing stops are managed by the order
+// controller, or by the exchange when supported (Native), referenced by the storage ID of the order.
+type TrailingStop struct {
+	ID       int64              `db:"id" json:"id" gorm:"primaryKey,autoIncrement"`
+	Strategy string             `db:"strategy" json:"strategy"`
+	Pair     string             `db:"pair" json:"pair"`
+	Side     SideType           `db:"side" json:"side"`
+	Quantity float64            `db:"quantity" json:"quantity"`
+	Distance float64            `db:"distance" json:"distance"`
+	Price    float64            `db:"price" json:"price"`
+	Stop     float64            `db:"stop" json:"stop"`
+	Status   TrailingStopStatus `db:"status" json:"status"`
+	OrderID  int64              `db:"order_id" json:"order_id"`
+	Native   bool               `db:"native" json:"native"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Update moves the stop with a new price, and returns true if the price reached the stop.
+// The best price is the highest price for sell stops (long positions), and the lowest for buy stops.
+func (t *TrailingStop) Update(price float64) (moved, triggered bool) {
+	if t.Side == SideTypeSell {
+		if price > t.Price {
+			t.Price = price
+			t.Stop = price - t.Distance
+			moved = true
+		}
+		return moved, price <= t.Stop
+	}
+
+	if price < t.Price {
+		t.Price = price
+		t.Stop = price + t.Distance
+		moved = true
+	}
+	return moved, price >= t.Stop
+}
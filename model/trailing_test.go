@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrailingStop_Update(t *testing.T) {
+	t.Run("sell", func(t *testing.T) {
+		stop := TrailingStop{Side: SideTypeSell, Distance: 5, Price: 100, Stop: 95}
+
+		moved, triggered := stop.Update(110)
+		require.True(t, moved)
+		require.False(t, triggered)
+		require.Equal(t, 105.0, stop.Stop)
+
+		moved, triggered = stop.Update(106)
+		require.False(t, moved)
+		require.False(t, triggered)
+		require.Equal(t, 105.0, stop.Stop)
+
+		_, triggered = stop.Update(105)
+		require.True(t, triggered)
+	})
+
+	t.Run("buy", func(t *testing.T) {
+		stop := TrailingStop{Side: SideTypeBuy, Distance: 5, Price: 100, Stop: 105}
+
+		moved, triggered := stop.Update(90)
+		require.True(t, moved)
+		require.False(t, triggered)
+		require.Equal(t, 95.0, stop.Stop)
+		require.Equal(t, 90.0, stop.Price)
+
+		_, triggered = stop.Update(96)
+		require.True(t, triggered)
+	})
+}
@@ -3,9 +3,13 @@ package ninjabot
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/aybabtme/uniplot/histogram"
 
@@ -13,6 +17,7 @@ import (
 	"github.com/rodrigo-brito/ninjabot/model"
 	"github.com/rodrigo-brito/ninjabot/notification"
 	"github.com/rodrigo-brito/ninjabot/order"
+	"github.com/rodrigo-brito/ninjabot/risk"
 	"github.com/rodrigo-brito/ninjabot/service"
 	"github.com/rodrigo-brito/ninjabot/storage"
 	"github.com/rodrigo-brito/ninjabot/strategy"
@@ -20,7 +25,9 @@ import (
 	"github.com/rodrigo-brito/ninjabot/tools/metrics"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/samber/lo"
 	"github.com/schollz/progressbar/v3"
+	"github.com/xhit/go-str2duration/v2"
 )
 
 const defaultDatabase = "ninjabot.db"
@@ -40,22 +47,44 @@ type CandleSubscriber interface {
 	OnCandle(model.Candle)
 }
 
+// StrategyAllocation defines a strategy executed by the bot, with its own pairs and capital budget
+type StrategyAllocation struct {
+	// Name identifies the strategy in orders and results
+	Name     string
+	Strategy strategy.Strategy
+	Pairs    []string
+	// Capital is the budget of the strategy in the quote asset of its pairs.
+	// A strategy without capital (zero) shares the whole account balance.
+	Capital float64
+}
+
 type NinjaBot struct {
-	storage  storage.Storage
-	settings model.Settings
-	exchange service.Exchange
-	strategy strategy.Strategy
-	notifier service.Notifier
-	telegram service.Telegram
+	storage    storage.Storage
+	settings   model.Settings
+	exchange   service.Exchange
+	strategies []StrategyAllocation
+	notifier   service.Notifier
+	telegram   service.Telegram
 
 	orderController       *order.Controller
 	priorityQueueCandle   *model.PriorityQueue
-	strategiesControllers map[string]*strategy.Controller
+	strategiesControllers map[string][]*strategy.Controller
+	// controllers of each strategy allocation, by name, locked to change the strategy parameters
+	allocationControllers map[string][]*strategy.Controller
+	paramsMtx             sync.Mutex
 	orderFeed             *order.Feed
 	dataFeed              *exchange.DataFeedSubscription
 	paperWallet           *exchange.PaperWallet
+	riskManager           *risk.Manager
+	riskOptions           []risk.Option
+
+	// smallest timeframe of each pair, used to update the wallet and order controller
+	timeframes        map[string]string
+	candleSubscribers []CandleSubscriber
+	orderSubscribers  []OrderSubscriber
 
-	backtest bool
+	backtest     bool
+	hideProgress bool
 }
 
 type Option func(*NinjaBot)
@@ -66,24 +95,29 @@ func NewBot(ctx context.Context, settings model.Settings, exch service.Exchange,
 	bot := &NinjaBot{
 		settings:              settings,
 		exchange:              exch,
-		strategy:              str,
 		orderFeed:             order.NewOrderFeed(),
 		dataFeed:              exchange.NewDataFeed(exch),
-		strategiesControllers: make(map[string]*strategy.Controller),
+		strategiesControllers: make(map[string][]*strategy.Controller),
+		allocationControllers: make(map[string][]*strategy.Controller),
 		priorityQueueCandle:   model.NewPriorityQueue(nil),
+		timeframes:            make(map[string]string),
 	}
 
-	for _, pair := range settings.Pairs {
-		asset, quote := exchange.SplitAssetQuote(pair)
-		if asset == "" || quote == "" {
-			return nil, fmt.Errorf("invalid pair: %s", pair)
-		}
+	if str != nil {
+		bot.strategies = append(bot.strategies, StrategyAllocation{
+			Strategy: str,
+			Pairs:    settings.Pairs,
+		})
 	}
 
 	for _, option := range options {
 		option(bot)
 	}
 
+	if err := bot.validateStrategies(); err != nil {
+		return nil, err
+	}
+
 	var err error
 	if bot.storage == nil {
 		bot.storage, err = storage.FromFile(defaultDatabase)
@@ -93,9 +127,18 @@ func NewBot(ctx context.Context, settings model.Settings, exch service.Exchange,
 	}
 
 	bot.orderController = order.NewController(ctx, exch, bot.storage, bot.orderFeed)
+	bot.orderController.SetBacktest(bot.backtest)
+	if bot.backtest {
+		// equity of backtests is tracked by the paper wallet, snapshots only slow down the optimizations
+		bot.orderController.SetEquityInterval(0)
+	}
+	for _, allocation := range bot.strategies {
+		bot.orderController.StrategyBroker(allocation.Name, allocation.Capital)
+	}
 
 	if settings.Telegram.Enabled {
-		bot.telegram, err = notification.NewTelegram(bot.orderController, settings)
+		bot.telegram, err = notification.NewTelegram(bot.orderController, settings,
+			notification.WithParamsController(bot))
 		if err != nil {
 			return nil, err
 		}
@@ -103,9 +146,87 @@ func NewBot(ctx context.Context, settings model.Settings, exch service.Exchange,
 		WithNotifier(bot.telegram)(bot)
 	}
 
+	if bot.riskOptions != nil {
+		bot.riskManager = risk.New(bot.orderController,
+			append([]risk.Option{risk.WithStopper(bot.orderController)}, bot.riskOptions...)...)
+		if bot.notifier != nil {
+			bot.riskManager.SetNotifier(bot.notifier)
+		}
+	}
+
 	return bot, nil
 }
 
+// validateStrategies checks the pairs of each strategy and defines the timeframe of each pair
+func (n *NinjaBot) validateStrategies() error {
+	if len(n.strategies) == 0 {
+		return errors.New("no strategy defined")
+	}
+
+	names := make(map[string]bool)
+	for _, allocation := range n.strategies {
+		if names[allocation.Name] {
+			return fmt.Errorf("duplicated strategy: %s", allocation.Name)
+		}
+		names[allocation.Name] = true
+
+		timeframe, err := str2duration.ParseDuration(allocation.Strategy.Timeframe())
+		if err != nil {
+			return fmt.Errorf("invalid timeframe for strategy %s: %w", allocation.Name, err)
+		}
+
+		quotes := make(map[string]bool)
+		for _, pair := range allocation.Pairs {
+			asset, quote := exchange.SplitAssetQuote(pair)
+			if asset == "" || quote == "" {
+				return fmt.Errorf("invalid pair: %s", pair)
+			}
+			quotes[quote] = true
+
+			// the wallet and order controller are updated with the smallest timeframe of the pair
+			if current, ok := n.timeframes[pair]; ok {
+				if duration, _ := str2duration.ParseDuration(current); duration <= timeframe {
+					continue
+				}
+			}
+			n.timeframes[pair] = allocation.Strategy.Timeframe()
+		}
+
+		if allocation.Capital > 0 && len(quotes) > 1 {
+			return fmt.Errorf("strategy %s with capital must use pairs with the same quote asset", allocation.Name)
+		}
+	}
+
+	return nil
+}
+
+// pairs returns the pairs of all strategies
+func (n *NinjaBot) pairs() []string {
+	pairs := make([]string, 0, len(n.timeframes))
+	for _, allocation := range n.strategies {
+		for _, pair := range allocation.Pairs {
+			if !lo.Contains(pairs, pair) {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs
+}
+
+// WithStrategy registers an additional strategy, executed in the given pairs with a capital budget.
+// Orders are tagged with the strategy name and the results are reported per strategy in `Summary`.
+// A capital equal to zero shares the whole account balance with other strategies.
+func WithStrategy(name string, str strategy.Strategy, pairs []string, capital float64) Option {
+	return func(bot *NinjaBot) {
+		bot.strategies = append(bot.strategies, StrategyAllocation{
+			Name:     name,
+			Strategy: str,
+			Pairs:    pairs,
+			Capital:  capital,
+		})
+	}
+}
+
 // WithBacktest sets the bot to run in backtest mode, it is required for backtesting environments
 // Backtest mode optimize the input read for CSV and deal with race conditions
 func WithBacktest(wallet *exchange.PaperWallet) Option {
@@ -116,6 +237,13 @@ func WithBacktest(wallet *exchange.PaperWallet) Option {
 	}
 }
 
+// WithProgressBar shows or hides the progress bar of backtests, enabled by default
+func WithProgressBar(show bool) Option {
+	return func(bot *NinjaBot) {
+		bot.hideProgress = !show
+	}
+}
+
 // WithStorage sets the storage for the bot, by default it uses a local file called ninjabot.db
 func WithStorage(storage storage.Storage) Option {
 	return func(bot *NinjaBot) {
@@ -139,6 +267,14 @@ func WithNotifier(notifier service.Notifier) Option {
 	}
 }
 
+// WithRiskManager validates the orders of all strategies with a risk manager, with the given limits.
+// The order controller is stopped when the drawdown kill-switch is activated.
+func WithRiskManager(options ...risk.Option) Option {
+	return func(bot *NinjaBot) {
+		bot.riskOptions = append([]risk.Option{}, options...)
+	}
+}
+
 // WithCandleSubscription subscribes a given struct to the candle feed
 func WithCandleSubscription(subscriber CandleSubscriber) Option {
 	return func(bot *NinjaBot) {
@@ -153,12 +289,10 @@ func WithPaperWallet(wallet *exchange.PaperWallet) Option {
 	}
 }
 
+// SubscribeCandle subscribes to the candles of all pairs, in the smallest timeframe of each pair.
+// Subscriptions are registered when the bot starts.
 func (n *NinjaBot) SubscribeCandle(subscriptions ...CandleSubscriber) {
-	for _, pair := range n.settings.Pairs {
-		for _, subscription := range subscriptions {
-			n.dataFeed.Subscribe(pair, n.strategy.Timeframe(), subscription.OnCandle, false)
-		}
-	}
+	n.candleSubscribers = append(n.candleSubscribers, subscriptions...)
 }
 
 func WithOrderSubscription(subscriber OrderSubscriber) Option {
@@ -167,21 +301,22 @@ func WithOrderSubscription(subscriber OrderSubscriber) Option {
 	}
 }
 
+// SubscribeOrder subscribes to the orders of all pairs. Subscriptions are registered when the bot starts.
 func (n *NinjaBot) SubscribeOrder(subscriptions ...OrderSubscriber) {
-	for _, pair := range n.settings.Pairs {
-		for _, subscription := range subscriptions {
-			n.orderFeed.Subscribe(pair, subscription.OnOrder, false)
-		}
-	}
+	n.orderSubscribers = append(n.orderSubscribers, subscriptions...)
 }
 
 func (n *NinjaBot) Controller() *order.Controller {
 	return n.orderController
 }
 
-// Summary function displays all trades, accuracy and some bot metrics in stdout
-// To access the raw data, you may access `bot.Controller().Results`
-func (n *NinjaBot) Summary() {
+// RiskManager returns the risk manager of the bot, or nil if not defined
+func (n *NinjaBot) RiskManager() *risk.Manager {
+	return n.riskManager
+}
+
+// resultsTable renders a table with the results of each pair
+func resultsTable(results map[string]*order.Summary) string {
 	var (
 		total  float64
 		wins   int
@@ -197,8 +332,7 @@ func (n *NinjaBot) Summary() {
 	avgPayoff := 0.0
 	avgProfitFactor := 0.0
 
-	returns := make([]float64, 0)
-	for _, summary := range n.orderController.Results {
+	for _, summary := range results {
 		avgPayoff += summary.Payoff() * float64(len(summary.Win())+len(summary.Lose()))
 		avgProfitFactor += summary.ProfitFactor() * float64(len(summary.Win())+len(summary.Lose()))
 		table.Append([]string{
@@ -218,9 +352,6 @@ func (n *NinjaBot) Summary() {
 		wins += len(summary.Win())
 		loses += len(summary.Lose())
 		volume += summary.Volume
-
-		returns = append(returns, summary.WinPercent()...)
-		returns = append(returns, summary.LosePercent()...)
 	}
 
 	table.SetFooter([]string{
@@ -231,13 +362,42 @@ func (n *NinjaBot) Summary() {
 		fmt.Sprintf("%.1f %%", float64(wins)/float64(wins+loses)*100),
 		fmt.Sprintf("%.3f", avgPayoff/float64(wins+loses)),
 		fmt.Sprintf("%.3f", avgProfitFactor/float64(wins+loses)),
-		fmt.Sprintf("%.1f", sqn/float64(len(n.orderController.Results))),
+		fmt.Sprintf("%.1f", sqn/float64(len(results))),
 		fmt.Sprintf("%.2f", total),
 		fmt.Sprintf("%.2f", volume),
 	})
 	table.Render()
 
-	fmt.Println(buffer.String())
+	return buffer.String()
+}
+
+// Summary function displays all trades, accuracy and some bot metrics in stdout
+// To access the raw data, you may access `bot.Controller().Results`
+func (n *NinjaBot) Summary() {
+	if len(n.strategies) > 1 {
+		for _, allocation := range n.strategies {
+			name := allocation.Name
+			if name == "" {
+				name = "default"
+			}
+
+			fmt.Printf("------ STRATEGY: %s -------\n", name)
+			if allocation.Capital > 0 {
+				fmt.Printf("CAPITAL = %.2f\n", allocation.Capital)
+			}
+			fmt.Println(resultsTable(n.orderController.StrategyResults[allocation.Name]))
+		}
+		fmt.Println("------ ALL STRATEGIES -------")
+	}
+
+	fmt.Println(resultsTable(n.orderController.Results))
+
+	returns := make([]float64, 0)
+	for _, summary := range n.orderController.Results {
+		returns = append(returns, summary.WinPercent()...)
+		returns = append(returns, summary.LosePercent()...)
+	}
+
 	fmt.Println("------ RETURN -------")
 	totalReturn := 0.0
 	returnsPercent := make([]float64, len(returns))
@@ -267,13 +427,38 @@ func (n *NinjaBot) Summary() {
 
 	fmt.Println()
 
+	performance := n.performance()
+	fmt.Println("------ PERFORMANCE -------")
+	if n.paperWallet != nil {
+		fmt.Printf("CAGR:            %.2f%%\n", performance.CAGR*100)
+		fmt.Printf("SHARPE RATIO:    %.2f\n", performance.SharpeRatio)
+		fmt.Printf("SORTINO RATIO:   %.2f\n", performance.SortinoRatio)
+		fmt.Printf("CALMAR RATIO:    %.2f\n", performance.CalmarRatio)
+		fmt.Printf("ULCER INDEX:     %.2f\n", performance.UlcerIndex)
+		fmt.Printf("RECOVERY FACTOR: %.2f\n", performance.RecoveryFactor)
+		fmt.Printf("EXPOSURE:        %.2f%%\n", performance.Exposure*100)
+	}
+	fmt.Printf("AVG. DURATION:   %s\n", performance.AverageDuration)
+	fmt.Printf("MAX CONS. LOSS:  %d\n", performance.MaxConsecutiveLosses)
+	fmt.Println()
+
+	if benchmark := n.benchmark(); benchmark != nil {
+		fmt.Println("------ BENCHMARK (B&H) ------")
+		fmt.Printf("RETURN:            %.2f%%\n", benchmark.Return*100)
+		fmt.Printf("ALPHA:             %.2f%%\n", benchmark.Alpha*100)
+		fmt.Printf("BETA:              %.2f\n", benchmark.Beta)
+		fmt.Printf("CORRELATION:       %.2f\n", benchmark.Correlation)
+		fmt.Printf("INFORMATION RATIO: %.2f\n", benchmark.InformationRatio)
+		fmt.Println()
+	}
+
 	if n.paperWallet != nil {
 		n.paperWallet.Summary()
 	}
 
 }
 
-func (n NinjaBot) SaveReturns(outputDir string) error {
+func (n *NinjaBot) SaveReturns(outputDir string) error {
 	for _, summary := range n.orderController.Results {
 		outputFile := fmt.Sprintf("%s/%s.csv", outputDir, summary.Pair)
 		if err := summary.SaveReturns(outputFile); err != nil {
@@ -288,14 +473,39 @@ func (n *NinjaBot) onCandle(candle model.Candle) {
 }
 
 func (n *NinjaBot) processCandle(candle model.Candle) {
-	if n.paperWallet != nil {
-		n.paperWallet.OnCandle(candle)
+	// wallet and order controller are updated only with the smallest timeframe of the pair
+	if candle.Timeframe == "" || candle.Timeframe == n.timeframes[candle.Pair] {
+		if n.paperWallet != nil {
+			n.paperWallet.OnCandle(candle)
+		}
+
+		if candle.Complete {
+			n.orderController.OnCandle(candle)
+		} else {
+			n.orderController.OnPartialCandle(candle)
+		}
+
+		if n.riskManager != nil {
+			n.riskManager.OnCandle(candle)
+		}
 	}
 
-	n.strategiesControllers[candle.Pair].OnPartialCandle(candle)
-	if candle.Complete {
-		n.strategiesControllers[candle.Pair].OnCandle(candle)
-		n.orderController.OnCandle(candle)
+	n.updateStrategies(candle)
+}
+
+// updateStrategies sends the candle to the strategies of the pair
+func (n *NinjaBot) updateStrategies(candle model.Candle) {
+	for _, controller := range n.strategiesControllers[candle.Pair] {
+		if candle.Timeframe != "" && candle.Timeframe != controller.Timeframe() {
+			// candles from additional timeframes, used by multi-timeframe strategies
+			controller.OnHigherCandle(candle)
+			continue
+		}
+
+		controller.OnPartialCandle(candle)
+		if candle.Complete {
+			controller.OnCandle(candle)
+		}
 	}
 }
 
@@ -311,19 +521,16 @@ func (n *NinjaBot) processCandles() {
 func (n *NinjaBot) backtestCandles() {
 	log.Info("[SETUP] Starting backtesting")
 
-	progressBar := progressbar.Default(int64(n.priorityQueueCandle.Len()))
+	var progressBar *progressbar.ProgressBar
+	if n.hideProgress {
+		progressBar = progressbar.DefaultSilent(int64(n.priorityQueueCandle.Len()))
+	} else {
+		progressBar = progressbar.Default(int64(n.priorityQueueCandle.Len()))
+	}
 	for n.priorityQueueCandle.Len() > 0 {
 		item := n.priorityQueueCandle.Pop()
 
-		candle := item.(model.Candle)
-		if n.paperWallet != nil {
-			n.paperWallet.OnCandle(candle)
-		}
-
-		n.strategiesControllers[candle.Pair].OnPartialCandle(candle)
-		if candle.Complete {
-			n.strategiesControllers[candle.Pair].OnCandle(candle)
-		}
+		n.processCandle(item.(model.Candle))
 
 		if err := progressBar.Add(1); err != nil {
 			log.Warnf("update progressbar fail: %v", err)
@@ -338,41 +545,152 @@ func (n *NinjaBot) preload(ctx context.Context, pair string) error {
 		return nil
 	}
 
-	candles, err := n.exchange.CandlesByLimit(ctx, pair, n.strategy.Timeframe(), n.strategy.WarmupPeriod())
-	if err != nil {
-		return err
+	// warmup period required by each timeframe of the pair, considering all strategies
+	warmups := make(map[string]int)
+	for _, controller := range n.strategiesControllers[pair] {
+		timeframes := map[string]int{controller.Timeframe(): controller.WarmupPeriod()}
+		for timeframe, warmup := range controller.Timeframes() {
+			timeframes[timeframe] = warmup
+		}
+
+		for timeframe, warmup := range timeframes {
+			if warmup > warmups[timeframe] {
+				warmups[timeframe] = warmup
+			}
+		}
 	}
 
-	for _, candle := range candles {
-		n.processCandle(candle)
+	type preloadCandle struct {
+		model.Candle
+		closeTime time.Time
+		duration  time.Duration
+	}
+
+	preloadCandles := make([]preloadCandle, 0)
+	for timeframe, warmup := range warmups {
+		duration, err := str2duration.ParseDuration(timeframe)
+		if err != nil {
+			return err
+		}
+
+		candles, err := n.exchange.CandlesByLimit(ctx, pair, timeframe, warmup)
+		if err != nil {
+			return err
+		}
+
+		for _, candle := range candles {
+			candle.Timeframe = timeframe
+			preloadCandles = append(preloadCandles, preloadCandle{
+				Candle:    candle,
+				closeTime: candle.Time.Add(duration),
+				duration:  duration,
+			})
+		}
+
+		if timeframe == n.timeframes[pair] {
+			defer n.dataFeed.Preload(pair, timeframe, candles)
+		}
 	}
 
-	n.dataFeed.Preload(pair, n.strategy.Timeframe(), candles)
+	// candles are processed in the order of closing, larger timeframes first,
+	// to keep the additional timeframes aligned with the main timeframe of each strategy
+	sort.SliceStable(preloadCandles, func(i, j int) bool {
+		if preloadCandles[i].closeTime.Equal(preloadCandles[j].closeTime) {
+			return preloadCandles[i].duration > preloadCandles[j].duration
+		}
+		return preloadCandles[i].closeTime.Before(preloadCandles[j].closeTime)
+	})
+
+	// historical candles only warm up the strategies, the order controller and the risk manager
+	// must not act with stale prices on the state restored from previous executions, e.g. trailing stops
+	for _, candle := range preloadCandles {
+		if n.paperWallet != nil && candle.Timeframe == n.timeframes[pair] {
+			n.paperWallet.OnCandle(candle.Candle)
+		}
+		n.updateStrategies(candle.Candle)
+	}
 
 	return nil
 }
 
 // Run will initialize the strategy controller, order controller, preload data and start the bot
 func (n *NinjaBot) Run(ctx context.Context) error {
-	for _, pair := range n.settings.Pairs {
-		// setup and subscribe strategy to data feed (candles)
-		n.strategiesControllers[pair] = strategy.NewStrategyController(pair, n.strategy, n.orderController)
+	// restore the positions and trades of previous executions
+	if !n.backtest {
+		if err := n.orderController.Restore(); err != nil {
+			return err
+		}
 
+		if err := n.restoreStrategyParams(); err != nil {
+			return err
+		}
+	}
+
+	for _, pair := range n.pairs() {
+		for _, subscription := range n.orderSubscribers {
+			n.orderFeed.Subscribe(pair, subscription.OnOrder, false)
+		}
+
+		for _, subscription := range n.candleSubscribers {
+			n.dataFeed.Subscribe(pair, n.timeframes[pair], subscription.OnCandle, false)
+		}
+	}
+
+	for _, allocation := range n.strategies {
+		var broker service.Broker = n.orderController.StrategyBroker(allocation.Name, allocation.Capital)
+		if n.riskManager != nil {
+			broker = n.riskManager.Wrap(broker)
+		}
+		for _, pair := range allocation.Pairs {
+			// setup strategy controller for each pair
+			controller := strategy.NewStrategyController(pair, allocation.Strategy, broker)
+			n.strategiesControllers[pair] = append(n.strategiesControllers[pair], controller)
+			n.allocationControllers[allocation.Name] = append(n.allocationControllers[allocation.Name], controller)
+		}
+	}
+
+	subscriptions := make(map[string]bool)
+	for _, pair := range n.pairs() {
 		// preload candles for warmup period
 		err := n.preload(ctx, pair)
 		if err != nil {
 			return err
 		}
 
-		// link to ninja bot controller
-		n.dataFeed.Subscribe(pair, n.strategy.Timeframe(), n.onCandle, false)
-
-		// start strategy controller
-		n.strategiesControllers[pair].Start()
+		for _, controller := range n.strategiesControllers[pair] {
+			// link to ninja bot controller, each pair and timeframe is subscribed only once
+			timeframes := []string{controller.Timeframe()}
+			for timeframe := range controller.Timeframes() {
+				timeframes = append(timeframes, timeframe)
+			}
+
+			for _, timeframe := range timeframes {
+				if key := pair + "--" + timeframe; !subscriptions[key] {
+					subscriptions[key] = true
+					n.dataFeed.Subscribe(pair, timeframe, n.onCandle, false)
+				}
+			}
+
+			// order book updates are dispatched directly to the strategy, out of the candles queue
+			if depth := controller.OrderBookDepth(); depth > 0 {
+				n.dataFeed.SubscribeOrderBook(pair, depth, controller.OnOrderBook)
+			}
+
+			// start strategy controller
+			controller.Start()
+		}
 	}
 
 	// start order feed and controller
 	n.orderFeed.Start()
+
+	// compare the restored state with the exchange, e.g. orders filled while the bot was offline
+	if !n.backtest {
+		if _, err := n.orderController.Reconcile(n.pairs()); err != nil {
+			return err
+		}
+	}
+
 	n.orderController.Start()
 	defer n.orderController.Stop()
 	if n.telegram != nil {
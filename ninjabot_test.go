@@ -2,7 +2,11 @@ package ninjabot
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/rodrigo-brito/ninjabot/strategy"
 
@@ -11,8 +15,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
 	"github.com/rodrigo-brito/ninjabot/service"
 	"github.com/rodrigo-brito/ninjabot/storage"
+	"github.com/rodrigo-brito/ninjabot/testdata/mocks"
 )
 
 type fakeStrategy struct{}
@@ -25,6 +31,14 @@ func (e fakeStrategy) WarmupPeriod() int {
 	return 10
 }
 
+func (e fakeStrategy) Parameters() []strategy.Param {
+	return []strategy.Param{{Name: "ema", Type: strategy.ParamInt, Default: 9}}
+}
+
+func (e fakeStrategy) ParamValues() strategy.Params {
+	return strategy.Params{"ema": 9}
+}
+
 func (e fakeStrategy) Indicators(df *Dataframe) []strategy.ChartIndicator {
 	df.Metadata["ema9"] = talib.Ema(df.Close, 9)
 	return nil
@@ -102,6 +116,11 @@ func TestMarketOrder(t *testing.T) {
 	require.Equal(t, assets, 0.0)
 	require.InDelta(t, quote, 22930.9622, 0.001)
 
+	// equity snapshots are not persisted in backtests
+	snapshots, err := storage.EquitySnapshots()
+	require.NoError(t, err)
+	require.Empty(t, snapshots)
+
 	results := bot.orderController.Results["BTCUSDT"]
 	require.InDelta(t, 5340.224, results.Profit(), 0.001)
 	require.Len(t, results.Win(), 5)
@@ -114,3 +133,430 @@ func TestMarketOrder(t *testing.T) {
 
 	bot.Summary()
 }
+
+type fakeMultiTimeframeStrategy struct {
+	calls     int
+	lookAhead bool
+}
+
+func (e fakeMultiTimeframeStrategy) Timeframe() string {
+	return "1h"
+}
+
+func (e fakeMultiTimeframeStrategy) WarmupPeriod() int {
+	return 10
+}
+
+func (e fakeMultiTimeframeStrategy) Timeframes() map[string]int {
+	return map[string]int{"4h": 5, "1d": 2}
+}
+
+func (e fakeMultiTimeframeStrategy) Indicators(_ *Dataframe) []strategy.ChartIndicator {
+	return nil
+}
+
+func (e *fakeMultiTimeframeStrategy) OnCandle(df *Dataframe, _ service.Broker) {
+	e.calls++
+	closeTime := df.Time[len(df.Time)-1].Add(time.Hour)
+	for timeframe, duration := range map[string]time.Duration{"4h": 4 * time.Hour, "1d": 24 * time.Hour} {
+		higher := df.Higher[timeframe]
+		if len(higher.Close) < e.Timeframes()[timeframe] ||
+			higher.Time[len(higher.Time)-1].Add(duration).After(closeTime) {
+			e.lookAhead = true
+		}
+	}
+}
+
+func TestMultiTimeframeStrategy(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	strategy := new(fakeMultiTimeframeStrategy)
+	csvFeed, err := exchange.NewCSVFeed(
+		strategy.Timeframe(),
+		exchange.PairFeed{
+			Pair:      "BTCUSDT",
+			File:      "testdata/btc-1h.csv",
+			Timeframe: "1h",
+		},
+	)
+	require.NoError(t, err)
+
+	paperWallet := exchange.NewPaperWallet(
+		ctx,
+		"USDT",
+		exchange.WithPaperAsset("USDT", 10000),
+		exchange.WithDataFeed(csvFeed),
+	)
+
+	bot, err := NewBot(ctx, Settings{Pairs: []string{"BTCUSDT"}},
+		paperWallet,
+		strategy,
+		WithStorage(storage),
+		WithBacktest(paperWallet),
+		WithLogLevel(log.ErrorLevel),
+	)
+	require.NoError(t, err)
+	require.NoError(t, bot.Run(ctx))
+
+	require.Greater(t, strategy.calls, 4000)
+	require.False(t, strategy.lookAhead)
+	require.Len(t, paperWallet.EquityValues(), len(csvFeed.CandlePairTimeFrame["BTCUSDT--1h"]))
+}
+
+type fakeHighFrequencyStrategy struct {
+	partialCalls int
+	calls        int
+	orders       int
+}
+
+func (e fakeHighFrequencyStrategy) Timeframe() string {
+	return "1m"
+}
+
+func (e fakeHighFrequencyStrategy) WarmupPeriod() int {
+	return 5
+}
+
+func (e fakeHighFrequencyStrategy) Indicators(_ *Dataframe) []strategy.ChartIndicator {
+	return nil
+}
+
+func (e *fakeHighFrequencyStrategy) OnCandle(_ *Dataframe, _ service.Broker) {
+	e.calls++
+}
+
+func (e *fakeHighFrequencyStrategy) OnPartialCandle(df *Dataframe, broker service.Broker) {
+	e.partialCalls++
+	if e.orders == 0 {
+		_, err := broker.CreateOrderLimit(SideTypeBuy, df.Pair, 1, df.Close.Last(0)-1)
+		if err != nil {
+			log.Fatal(err)
+		}
+		e.orders++
+	}
+}
+
+func TestHighFrequencyStrategy_TradeFeed(t *testing.T) {
+	ctx := context.Background()
+
+	// one trade every 10 seconds, for 3 hours
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	content := "time,id,price,quantity,buyer_maker\n"
+	for i := 0; i < 3*60*6; i++ {
+		tradeTime := start.Add(time.Duration(i) * 10 * time.Second)
+		content += fmt.Sprintf("%d,%d,%d,1,false\n", tradeTime.UnixMilli(), i, 100+i%7)
+	}
+	file := filepath.Join(t.TempDir(), "trades.csv")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	tradeFeed, err := exchange.NewTradeFeed(exchange.PairTradeFeed{Pair: "BTCUSDT", File: file})
+	require.NoError(t, err)
+
+	paperWallet := exchange.NewPaperWallet(
+		ctx,
+		"USDT",
+		exchange.WithPaperAsset("USDT", 10000),
+		exchange.WithDataFeed(tradeFeed),
+	)
+
+	strategy := new(fakeHighFrequencyStrategy)
+	bot, err := NewBot(ctx, Settings{Pairs: []string{"BTCUSDT"}},
+		paperWallet,
+		strategy,
+		WithStorage(storage),
+		WithBacktest(paperWallet),
+		WithLogLevel(log.ErrorLevel),
+	)
+	require.NoError(t, err)
+	require.NoError(t, bot.Run(ctx))
+
+	// 179 complete candles, the partial candles are received after the warmup period
+	require.Equal(t, 175, strategy.calls)
+	require.Equal(t, 175*6, strategy.partialCalls)
+
+	// the limit order is filled by the first trade at the order price
+	orders, err := storage.Orders()
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	require.Equal(t, OrderStatusTypeFilled, orders[0].Status)
+	require.Equal(t, 101.0, orders[0].Price)
+}
+
+func TestMultipleStrategies(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	csvFeed, err := exchange.NewCSVFeed(
+		"1d",
+		exchange.PairFeed{
+			Pair:      "BTCUSDT",
+			File:      "testdata/btc-1h.csv",
+			Timeframe: "1h",
+		},
+		exchange.PairFeed{
+			Pair:      "ETHUSDT",
+			File:      "testdata/eth-1h.csv",
+			Timeframe: "1h",
+		},
+	)
+	require.NoError(t, err)
+
+	paperWallet := exchange.NewPaperWallet(
+		ctx,
+		"USDT",
+		exchange.WithPaperAsset("USDT", 10000),
+		exchange.WithDataFeed(csvFeed),
+	)
+
+	bot, err := NewBot(ctx, Settings{},
+		paperWallet,
+		nil,
+		WithStrategy("btc", new(fakeStrategy), []string{"BTCUSDT"}, 2000),
+		WithStrategy("eth", new(fakeStrategy), []string{"ETHUSDT"}, 8000),
+		WithStorage(storage),
+		WithBacktest(paperWallet),
+		WithLogLevel(log.ErrorLevel),
+	)
+	require.NoError(t, err)
+	require.NoError(t, bot.Run(ctx))
+
+	orders, err := storage.Orders()
+	require.NoError(t, err)
+	require.NotEmpty(t, orders)
+
+	firstOrders := make(map[string]float64)
+	for _, order := range orders {
+		require.Contains(t, []string{"btc", "eth"}, order.Strategy)
+		require.Equal(t, map[string]string{"btc": "BTCUSDT", "eth": "ETHUSDT"}[order.Strategy], order.Pair)
+		if _, ok := firstOrders[order.Strategy]; !ok {
+			firstOrders[order.Strategy] = order.Price * order.Quantity
+		}
+	}
+
+	// strategies buy with 50% of their own budget
+	require.InDelta(t, 1000, firstOrders["btc"], 0.001)
+	require.InDelta(t, 4000, firstOrders["eth"], 0.001)
+
+	require.Len(t, bot.orderController.StrategyResults["btc"], 1)
+	require.Len(t, bot.orderController.StrategyResults["eth"], 1)
+	require.InDelta(t, bot.orderController.Results["BTCUSDT"].Profit(),
+		bot.orderController.StrategyResults["btc"]["BTCUSDT"].Profit(), 0.001)
+
+	bot.Summary()
+
+	t.Run("invalid strategies", func(t *testing.T) {
+		_, err := NewBot(ctx, Settings{}, paperWallet, nil, WithStorage(storage))
+		require.Error(t, err)
+
+		_, err = NewBot(ctx, Settings{}, paperWallet, nil, WithStorage(storage),
+			WithStrategy("a", new(fakeStrategy), []string{"BTCUSDT", "ETHBTC"}, 1000))
+		require.Error(t, err)
+
+		_, err = NewBot(ctx, Settings{}, paperWallet, nil, WithStorage(storage),
+			WithStrategy("a", new(fakeStrategy), []string{"BTCUSDT"}, 0),
+			WithStrategy("a", new(fakeStrategy), []string{"ETHUSDT"}, 0))
+		require.Error(t, err)
+	})
+}
+
+// fakeBracketStrategy enters with limit orders below the close price, protected by take-profit and stop-loss exits
+type fakeBracketStrategy struct{}
+
+func (e fakeBracketStrategy) Timeframe() string {
+	return "1h"
+}
+
+func (e fakeBracketStrategy) WarmupPeriod() int {
+	return 1
+}
+
+func (e fakeBracketStrategy) Indicators(_ *Dataframe) []strategy.ChartIndicator {
+	return nil
+}
+
+func (e *fakeBracketStrategy) OnCandle(df *Dataframe, broker service.Broker) {
+	// one entry per day, at most
+	if df.LastUpdate.Hour() != 0 {
+		return
+	}
+
+	assetPosition, _, err := broker.Position(df.Pair)
+	if err != nil || assetPosition > 0 {
+		return
+	}
+
+	closePrice := df.Close.Last(0)
+	_, err = broker.(service.BracketBroker).CreateOrderBracket(SideTypeBuy, df.Pair, 0.1,
+		closePrice*0.995, closePrice*1.02, closePrice*0.98)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func TestBracketBacktest(t *testing.T) {
+	ctx := context.Background()
+
+	backtest := func() (*NinjaBot, storage.Storage) {
+		storage, err := storage.FromMemory()
+		require.NoError(t, err)
+
+		csvFeed, err := exchange.NewCSVFeed("1h", exchange.PairFeed{
+			Pair:      "BTCUSDT",
+			File:      "testdata/btc-1h.csv",
+			Timeframe: "1h",
+		})
+		require.NoError(t, err)
+
+		paperWallet := exchange.NewPaperWallet(ctx, "USDT",
+			exchange.WithPaperAsset("USDT", 10000),
+			exchange.WithDataFeed(csvFeed),
+		)
+
+		bot, err := NewBot(ctx, Settings{Pairs: []string{"BTCUSDT"}},
+			paperWallet,
+			new(fakeBracketStrategy),
+			WithStorage(storage),
+			WithBacktest(paperWallet),
+			WithLogLevel(log.ErrorLevel),
+		)
+		require.NoError(t, err)
+		require.NoError(t, bot.Run(ctx))
+		return bot, storage
+	}
+
+	bot, repo := backtest()
+	brackets, err := repo.Brackets()
+	require.NoError(t, err)
+
+	closed := 0
+	for _, bracket := range brackets {
+		require.NotEqual(t, model.BracketStatusFailed, bracket.Status)
+		if bracket.Status == model.BracketStatusClosed {
+			closed++
+		}
+
+		// exits are submitted on the candle that fills the entry
+		if bracket.Status != model.BracketStatusPending && bracket.Status != model.BracketStatusCanceled {
+			require.NotZero(t, bracket.TakeProfitID)
+			require.NotZero(t, bracket.StopLossID)
+		}
+	}
+	require.NotZero(t, closed)
+
+	results := bot.orderController.Results["BTCUSDT"]
+	require.Len(t, results.Trades, closed)
+
+	// fills are processed synchronously with the candles, the results are the same in every execution
+	other, _ := backtest()
+	otherResults := other.orderController.Results["BTCUSDT"]
+	require.Equal(t, len(results.Trades), len(otherResults.Trades))
+	require.Equal(t, results.Profit(), otherResults.Profit())
+}
+
+// restart restores the state of the storage and preloads the warmup candles of a live bot,
+// with falling prices that cross the stops saved by previous executions
+func restart(t *testing.T, repo storage.Storage) (*NinjaBot, *mocks.Exchange) {
+	ctx := context.Background()
+	exch := mocks.NewExchange(t)
+
+	bot, err := NewBot(ctx, Settings{Pairs: []string{"BTCUSDT"}},
+		exch,
+		new(fakeStrategy),
+		WithStorage(repo),
+		WithLogLevel(log.ErrorLevel),
+	)
+	require.NoError(t, err)
+	require.NoError(t, bot.orderController.Restore())
+
+	candles := make([]model.Candle, 0)
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < new(fakeStrategy).WarmupPeriod(); i++ {
+		price := 100 - float64(i)*2
+		candles = append(candles, model.Candle{
+			Pair:      "BTCUSDT",
+			Time:      start.AddDate(0, 0, i),
+			UpdatedAt: start.AddDate(0, 0, i+1).Add(-time.Minute),
+			Open:      price,
+			Close:     price,
+			High:      price + 1,
+			Low:       price - 1,
+			Complete:  true,
+		})
+	}
+	exch.On("CandlesByLimit", ctx, "BTCUSDT", "1d", 10).Return(candles, nil)
+
+	controller := strategy.NewStrategyController("BTCUSDT", new(fakeStrategy), bot.orderController)
+	bot.strategiesControllers["BTCUSDT"] = append(bot.strategiesControllers["BTCUSDT"], controller)
+	require.NoError(t, bot.preload(ctx, "BTCUSDT"))
+	return bot, exch
+}
+
+func TestRestartPreload(t *testing.T) {
+	t.Run("trailing stop", func(t *testing.T) {
+		repo, err := storage.FromMemory()
+		require.NoError(t, err)
+
+		stop := &model.TrailingStop{
+			Pair:     "BTCUSDT",
+			Side:     model.SideTypeSell,
+			Quantity: 1,
+			Distance: 5,
+			Price:    100,
+			Stop:     95,
+			Status:   model.TrailingStopStatusActive,
+		}
+		require.NoError(t, repo.SaveTrailingStop(stop))
+
+		bot, exch := restart(t, repo)
+
+		// warmup candles do not move or trigger the restored stop
+		exch.AssertNotCalled(t, "CreateOrderMarket", model.SideTypeSell, "BTCUSDT", 1.0)
+		stops := bot.orderController.TrailingStops()
+		require.Len(t, stops, 1)
+		require.Equal(t, 95.0, stops[0].Stop)
+		require.Equal(t, model.TrailingStopStatusActive, stops[0].Status)
+	})
+	t.Run("short bracket", func(t *testing.T) {
+		repo, err := storage.FromMemory()
+		require.NoError(t, err)
+
+		bracket := &model.Bracket{
+			Pair:       "BTCUSDT",
+			Side:       model.SideTypeSell,
+			Quantity:   1,
+			Price:      90,
+			TakeProfit: 80,
+			StopLoss:   95,
+			Status:     model.BracketStatusActive,
+			EntryID:    1,
+		}
+		require.NoError(t, repo.SaveBracket(bracket))
+
+		bot, exch := restart(t, repo)
+
+		// the stop-loss without order is not checked with the high of the warmup candles
+		exch.AssertNotCalled(t, "CreateOrderMarket", model.SideTypeBuy, "BTCUSDT", 1.0)
+		brackets := bot.orderController.Brackets()
+		require.Len(t, brackets, 1)
+		require.Equal(t, model.BracketStatusActive, brackets[0].Status)
+	})
+	t.Run("equity snapshots", func(t *testing.T) {
+		repo, err := storage.FromMemory()
+		require.NoError(t, err)
+
+		restart(t, repo)
+
+		// the equity of the account is not valued with historical prices
+		snapshots, err := repo.EquitySnapshots()
+		require.NoError(t, err)
+		require.Empty(t, snapshots)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -23,14 +24,22 @@ var (
 )
 
 type telegram struct {
-	settings        model.Settings
-	orderController *order.Controller
-	defaultMenu     *tb.ReplyMarkup
-	client          *tb.Bot
+	settings         model.Settings
+	orderController  *order.Controller
+	paramsController service.ParamsController
+	defaultMenu      *tb.ReplyMarkup
+	client           *tb.Bot
 }
 
 type Option func(telegram *telegram)
 
+// WithParamsController enables the command /set, to change the parameters of the strategies
+func WithParamsController(controller service.ParamsController) Option {
+	return func(telegram *telegram) {
+		telegram.paramsController = controller
+	}
+}
+
 func NewTelegram(controller *order.Controller, settings model.Settings, options ...Option) (service.Telegram, error) {
 	menu := &tb.ReplyMarkup{ResizeReplyKeyboard: true}
 	poller := &tb.LongPoller{Timeout: 10 * time.Second}
@@ -79,6 +88,7 @@ func NewTelegram(controller *order.Controller, settings model.Settings, options
 		{Text: "/profit", Description: "Summary of last trade results"},
 		{Text: "/buy", Description: "open a buy order"},
 		{Text: "/sell", Description: "open a sell order"},
+		{Text: "/set", Description: "change the parameters of a strategy"},
 	})
 	if err != nil {
 		return nil, err
@@ -108,6 +118,7 @@ func NewTelegram(controller *order.Controller, settings model.Settings, options
 	client.Handle("/profit", bot.ProfitHandle)
 	client.Handle("/buy", bot.BuyHandle)
 	client.Handle("/sell", bot.SellHandle)
+	client.Handle("/set", bot.SetHandle)
 
 	return bot, nil
 }
@@ -314,6 +325,90 @@ func (t telegram) SellHandle(m *tb.Message) {
 	log.Info("[TELEGRAM]: SELL ORDER CREATED: ", order)
 }
 
+// SetHandle changes the parameters of a strategy, eg: /set emacross ema=9 sma=21.
+// The strategy name is optional for the default strategy, and without parameters the current values are listed.
+func (t telegram) SetHandle(m *tb.Message) {
+	if t.paramsController == nil {
+		_, err := t.client.Send(m.Sender, "Parameters cannot be changed.")
+		if err != nil {
+			log.Error(err)
+		}
+		return
+	}
+
+	name, params, err := parseSetCommand(m.Text)
+	if err != nil {
+		_, err := t.client.Send(m.Sender, "Invalid command.\nExamples of usage:\n`/set ema=9`\n\n`/set emacross ema=9 sma=21`")
+		if err != nil {
+			log.Error(err)
+		}
+		return
+	}
+
+	title := "*PARAMETERS*\n"
+	var values map[string]any
+	if len(params) == 0 {
+		values, err = t.paramsController.StrategyParams(name)
+	} else {
+		title = "*PARAMETERS UPDATED*\n"
+		values, err = t.paramsController.SetStrategyParams(name, params)
+	}
+	if err != nil {
+		log.Error(err)
+		t.OnError(err)
+		return
+	}
+
+	if len(params) > 0 {
+		log.Infof("[TELEGRAM]: PARAMETERS CHANGED: %s %v", name, params)
+	}
+
+	_, err = t.client.Send(m.Sender, title+formatParams(values))
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// parseSetCommand returns the strategy name and the parameters of a command /set [strategy] [name=value ...]
+func parseSetCommand(text string) (string, map[string]any, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", nil, errors.New("empty command")
+	}
+	fields = fields[1:]
+
+	var name string
+	if len(fields) > 0 && !strings.Contains(fields[0], "=") {
+		name = fields[0]
+		fields = fields[1:]
+	}
+
+	params := make(map[string]any, len(fields))
+	for _, field := range fields {
+		param, value, ok := strings.Cut(field, "=")
+		if !ok || param == "" || value == "" {
+			return "", nil, fmt.Errorf("invalid parameter %q, expected name=value", field)
+		}
+		params[param] = value
+	}
+
+	return name, params, nil
+}
+
+func formatParams(params map[string]any) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("`%s` = %v", name, params[name]))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (t telegram) StatusHandle(m *tb.Message) {
 	status := t.orderController.Status()
 	_, err := t.client.Send(m.Sender, fmt.Sprintf("Status: `%s`", status))
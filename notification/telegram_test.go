@@ -0,0 +1,31 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSetCommand(t *testing.T) {
+	name, params, err := parseSetCommand("/set ema=9 sma=21")
+	require.NoError(t, err)
+	require.Empty(t, name)
+	require.Equal(t, map[string]any{"ema": "9", "sma": "21"}, params)
+
+	name, params, err = parseSetCommand("/set emacross  ema=9")
+	require.NoError(t, err)
+	require.Equal(t, "emacross", name)
+	require.Equal(t, map[string]any{"ema": "9"}, params)
+
+	name, params, err = parseSetCommand("/set emacross")
+	require.NoError(t, err)
+	require.Equal(t, "emacross", name)
+	require.Empty(t, params)
+
+	for _, command := range []string{"", "/set emacross ema", "/set ema=", "/set =9"} {
+		_, _, err = parseSetCommand(command)
+		require.Error(t, err, command)
+	}
+
+	require.Equal(t, "`ema` = 9\n`sma` = 21", formatParams(map[string]any{"sma": 21, "ema": 9}))
+}
@@ -0,0 +1,429 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rodrigo-brito/ninjabot"
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/order"
+	"github.com/rodrigo-brito/ninjabot/storage"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+// Metric used to rank the optimization results
+type Metric string
+
+const (
+	MetricProfit       Metric = "profit"
+	MetricSQN          Metric = "sqn"
+	MetricProfitFactor Metric = "profit_factor"
+	MetricMaxDrawdown  Metric = "max_drawdown"
+)
+
+var ErrInvalidMetric = errors.New("invalid metric")
+
+// Params are the values of the strategy parameters in a backtest
+type Params map[string]float64
+
+// StrategyFactory creates a new strategy instance configured with the given parameters
+type StrategyFactory func(params Params) (strategy.Strategy, error)
+
+// Parameter defines the search space of a strategy parameter, from Min to Max (inclusive) with a Step
+type Parameter struct {
+	Name string
+	Min  float64
+	Max  float64
+	Step float64
+}
+
+// Values returns all values of the parameter in the search space
+func (p Parameter) Values() []float64 {
+	if p.Step <= 0 || p.Max <= p.Min {
+		return []float64{p.Min}
+	}
+
+	values := make([]float64, 0)
+	steps := int(math.Floor((p.Max-p.Min)/p.Step + 1e-9))
+	for i := 0; i <= steps; i++ {
+		// round to avoid floating point accumulation, eg: 0.1 + 0.2
+		value := math.Round((p.Min+float64(i)*p.Step)*1e9) / 1e9
+		values = append(values, value)
+	}
+	return values
+}
+
+// Result of a backtest with a set of parameters
+type Result struct {
+	Params       Params
+	Trades       int
+	Win          int
+	Lose         int
+	Profit       float64
+	Return       float64
+	SQN          float64
+	ProfitFactor float64
+	MaxDrawdown  float64
+	Err          error
+}
+
+// Score returns the value of the metric, higher is better
+func (r Result) Score(metric Metric) float64 {
+	switch metric {
+	case MetricSQN:
+		return r.SQN
+	case MetricProfitFactor:
+		return r.ProfitFactor
+	case MetricMaxDrawdown:
+		// drawdown is a negative value, closer to zero is better
+		return r.MaxDrawdown
+	default:
+		return r.Profit
+	}
+}
+
+type Optimizer struct {
+	feed       *exchange.CSVFeed
+	settings   ninjabot.Settings
+	factory    StrategyFactory
+	parameters []Parameter
+	metric     Metric
+	workers    int
+	samples    int
+	seed       int64
+
+	baseCoin      string
+	walletOptions []exchange.PaperWalletOption
+}
+
+type Option func(*Optimizer)
+
+// WithMetric defines the metric used to rank the results, default: profit
+func WithMetric(metric Metric) Option {
+	return func(o *Optimizer) {
+		o.metric = metric
+	}
+}
+
+// WithWorkers defines the number of backtests executed in parallel
+func WithWorkers(workers int) Option {
+	return func(o *Optimizer) {
+		o.workers = workers
+	}
+}
+
+// WithRandomSearch evaluates a number of random samples of the search space, instead of all combinations
+func WithRandomSearch(samples int, seed int64) Option {
+	return func(o *Optimizer) {
+		o.samples = samples
+		o.seed = seed
+	}
+}
+
+// WithPaperWallet defines the base coin and the options of the paper wallet created for each backtest,
+// default: 10.000 USDT
+func WithPaperWallet(baseCoin string, options ...exchange.PaperWalletOption) Option {
+	return func(o *Optimizer) {
+		o.baseCoin = baseCoin
+		o.walletOptions = options
+	}
+}
+
+// New creates an optimizer that runs backtests of the strategy over the CSV feed, for each set of parameters
+func New(feed *exchange.CSVFeed, settings ninjabot.Settings, factory StrategyFactory, parameters []Parameter,
+	options ...Option) *Optimizer {
+
+	optimizer := &Optimizer{
+		feed:       feed,
+		settings:   settings,
+		factory:    factory,
+		parameters: parameters,
+		metric:     MetricProfit,
+		workers:    1,
+		baseCoin:   "USDT",
+		walletOptions: []exchange.PaperWalletOption{
+			exchange.WithPaperAsset("USDT", 10000),
+		},
+	}
+
+	for _, option := range options {
+		option(optimizer)
+	}
+
+	return optimizer
+}
+
+// Grid returns all combinations of the parameters values
+func (o *Optimizer) Grid() []Params {
+	grid := []Params{{}}
+	for _, parameter := range o.parameters {
+		combinations := make([]Params, 0, len(grid))
+		for _, params := range grid {
+			for _, value := range parameter.Values() {
+				combination := make(Params, len(params)+1)
+				for name, v := range params {
+					combination[name] = v
+				}
+				combination[parameter.Name] = value
+				combinations = append(combinations, combination)
+			}
+		}
+		grid = combinations
+	}
+	return grid
+}
+
+// Random returns random samples of the parameters values
+func (o *Optimizer) Random(samples int) []Params {
+	random := rand.New(rand.NewSource(o.seed)) //nolint:gosec
+	result := make([]Params, 0, samples)
+	for i := 0; i < samples; i++ {
+		params := make(Params, len(o.parameters))
+		for _, parameter := range o.parameters {
+			values := parameter.Values()
+			params[parameter.Name] = values[random.Intn(len(values))]
+		}
+		result = append(result, params)
+	}
+	return result
+}
+
+// Run executes the backtests in parallel and returns the results sorted by the metric, from best to worst.
+// Runs with errors are placed at the end.
+func (o *Optimizer) Run(ctx context.Context) ([]Result, error) {
+	switch o.metric {
+	case MetricProfit, MetricSQN, MetricProfitFactor, MetricMaxDrawdown:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMetric, o.metric)
+	}
+
+	candidates := o.Grid()
+	if o.samples > 0 {
+		candidates = o.Random(o.samples)
+	}
+
+	workers := o.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]Result, len(candidates))
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = o.Backtest(ctx, candidates[index])
+			}
+		}()
+	}
+
+	for index := range candidates {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Err != nil || results[j].Err != nil {
+			return results[i].Err == nil && results[j].Err != nil
+		}
+		return results[i].Score(o.metric) > results[j].Score(o.metric)
+	})
+
+	return results, nil
+}
+
+// Backtest runs a single backtest with isolated wallet, storage and data feed
+func (o *Optimizer) Backtest(ctx context.Context, params Params) Result {
+	result, _ := o.backtest(ctx, o.feed, params)
+	return result
+}
+
+func (o *Optimizer) backtest(ctx context.Context, csvFeed *exchange.CSVFeed,
+	params Params) (Result, []exchange.AssetValue) {
+
+	result := Result{Params: params}
+
+	str, err := o.factory(params)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+
+	db, err := storage.FromMemory()
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+
+	feed := csvFeed.Clone()
+	walletOptions := make([]exchange.PaperWalletOption, 0, len(o.walletOptions)+1)
+	walletOptions = append(walletOptions, o.walletOptions...)
+	walletOptions = append(walletOptions, exchange.WithDataFeed(feed))
+	wallet := exchange.NewPaperWallet(ctx, o.baseCoin, walletOptions...)
+	bot, err := ninjabot.NewBot(ctx, o.settings, wallet, str,
+		ninjabot.WithBacktest(wallet),
+		ninjabot.WithStorage(db),
+		ninjabot.WithProgressBar(false),
+	)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+
+	err = bot.Run(ctx)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+
+	// aggregate the results of all pairs
+	var total order.Summary
+	for _, summary := range bot.Controller().Results {
+		total.WinLong = append(total.WinLong, summary.WinLong...)
+		total.WinShort = append(total.WinShort, summary.WinShort...)
+		total.LoseLong = append(total.LoseLong, summary.LoseLong...)
+		total.LoseShort = append(total.LoseShort, summary.LoseShort...)
+		total.WinLongPercent = append(total.WinLongPercent, summary.WinLongPercent...)
+		total.WinShortPercent = append(total.WinShortPercent, summary.WinShortPercent...)
+		total.LoseLongPercent = append(total.LoseLongPercent, summary.LoseLongPercent...)
+		total.LoseShortPercent = append(total.LoseShortPercent, summary.LoseShortPercent...)
+	}
+
+	result.Win = len(total.Win())
+	result.Lose = len(total.Lose())
+	result.Trades = result.Win + result.Lose
+	result.Profit = total.Profit()
+	result.ProfitFactor = profitFactor(total)
+	if sqn := total.SQN(); !math.IsNaN(sqn) && !math.IsInf(sqn, 0) {
+		result.SQN = sqn
+	}
+	result.MaxDrawdown, _, _ = wallet.MaxDrawdown()
+
+	equity := wallet.EquityValues()
+	if len(equity) > 0 && equity[0].Value > 0 {
+		result.Return = equity[len(equity)-1].Value/equity[0].Value - 1
+	}
+
+	return result, equity
+}
+
+// profitFactor returns the profit factor of the trades, infinite when there are only winning trades,
+// to rank them before the results with losses
+func profitFactor(summary order.Summary) float64 {
+	if len(summary.Lose()) == 0 && summary.Profit() > 0 {
+		return math.Inf(1)
+	}
+	return summary.ProfitFactor()
+}
+
+// WriteCSV writes the results in CSV format, with a column for each parameter
+func WriteCSV(writer io.Writer, parameters []Parameter, results []Result) error {
+	csvWriter := csv.NewWriter(writer)
+
+	header := make([]string, 0, len(parameters)+9)
+	for _, parameter := range parameters {
+		header = append(header, parameter.Name)
+	}
+	header = append(header, "trades", "win", "lose", "profit", "return", "sqn", "profit_factor", "max_drawdown", "error")
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		line := make([]string, 0, len(header))
+		for _, parameter := range parameters {
+			line = append(line, strconv.FormatFloat(result.Params[parameter.Name], 'f', -1, 64))
+		}
+
+		var errMessage string
+		if result.Err != nil {
+			errMessage = result.Err.Error()
+		}
+
+		line = append(line,
+			strconv.Itoa(result.Trades),
+			strconv.Itoa(result.Win),
+			strconv.Itoa(result.Lose),
+			strconv.FormatFloat(result.Profit, 'f', 4, 64),
+			strconv.FormatFloat(result.Return, 'f', 4, 64),
+			strconv.FormatFloat(result.SQN, 'f', 4, 64),
+			strconv.FormatFloat(result.ProfitFactor, 'f', 4, 64),
+			strconv.FormatFloat(result.MaxDrawdown, 'f', 4, 64),
+			errMessage,
+		)
+
+		if err := csvWriter.Write(line); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// ParseParameter parses a parameter definition in the format name=min:max:step, eg: ema=5:20:1.
+// A single value (name=value) defines a fixed parameter.
+func ParseParameter(definition string) (Parameter, error) {
+	name, space, ok := strings.Cut(definition, "=")
+	if !ok || name == "" {
+		return Parameter{}, fmt.Errorf("invalid parameter %q, expected name=min:max:step", definition)
+	}
+
+	parts := strings.Split(space, ":")
+	if len(parts) != 1 && len(parts) != 3 {
+		return Parameter{}, fmt.Errorf("invalid parameter %q, expected name=min:max:step", definition)
+	}
+
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return Parameter{}, fmt.Errorf("invalid parameter %q: %w", definition, err)
+		}
+		values[i] = value
+	}
+
+	if len(values) == 1 {
+		return Parameter{Name: name, Min: values[0], Max: values[0]}, nil
+	}
+
+	if values[1] < values[0] || values[2] <= 0 {
+		return Parameter{}, fmt.Errorf("invalid parameter %q, expected min <= max and step > 0", definition)
+	}
+
+	return Parameter{Name: name, Min: values[0], Max: values[1], Step: values[2]}, nil
+}
+
+// ParametersOf returns the search space of the numeric parameters declared by a strategy,
+// see strategy.ParametrizedStrategy. Parameters without range are not included.
+func ParametersOf(declarations []strategy.Param) []Parameter {
+	parameters := make([]Parameter, 0, len(declarations))
+	for _, param := range declarations {
+		if param.Type != strategy.ParamInt && param.Type != strategy.ParamFloat || param.Max <= param.Min {
+			continue
+		}
+
+		step := param.Step
+		if step <= 0 && param.Type == strategy.ParamInt {
+			step = 1
+		} else if step <= 0 {
+			// float parameters without step are evaluated in 10 steps of the range
+			step = (param.Max - param.Min) / 10
+		}
+		parameters = append(parameters, Parameter{Name: param.Name, Min: param.Min, Max: param.Max, Step: step})
+	}
+	return parameters
+}
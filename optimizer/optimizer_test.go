@@ -0,0 +1,135 @@
+package optimizer
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot"
+	"github.com/rodrigo-brito/ninjabot/examples/strategies"
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/order"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+func TestParameter_Values(t *testing.T) {
+	require.Equal(t, []float64{5, 10, 15}, Parameter{Name: "a", Min: 5, Max: 15, Step: 5}.Values())
+	require.Equal(t, []float64{0.1, 0.2, 0.3}, Parameter{Name: "a", Min: 0.1, Max: 0.3, Step: 0.1}.Values())
+	require.Equal(t, []float64{5}, Parameter{Name: "a", Min: 5, Max: 5}.Values())
+}
+
+func TestParseParameter(t *testing.T) {
+	parameter, err := ParseParameter("ema=5:20:5")
+	require.NoError(t, err)
+	require.Equal(t, Parameter{Name: "ema", Min: 5, Max: 20, Step: 5}, parameter)
+
+	parameter, err = ParseParameter("ema=8")
+	require.NoError(t, err)
+	require.Equal(t, Parameter{Name: "ema", Min: 8, Max: 8}, parameter)
+
+	for _, definition := range []string{"ema", "=1", "ema=a:b:c", "ema=1:2", "ema=5:1:1", "ema=1:5:0"} {
+		_, err = ParseParameter(definition)
+		require.Error(t, err, definition)
+	}
+}
+
+func TestParametersOf(t *testing.T) {
+	require.Equal(t, []Parameter{
+		{Name: "ema", Min: 2, Max: 50, Step: 1},
+		{Name: "sma", Min: 2, Max: 200, Step: 1},
+	}, ParametersOf(strategies.CrossEMA{}.Parameters()))
+
+	require.Equal(t, []Parameter{{Name: "period", Min: 5, Max: 10, Step: 1}}, ParametersOf([]strategy.Param{
+		{Name: "period", Type: strategy.ParamInt, Min: 5, Max: 10},
+		{Name: "ratio", Type: strategy.ParamFloat},
+		{Name: "mode", Type: strategy.ParamString, Options: []string{"a", "b"}},
+	}))
+
+	// float parameters without step are not pinned to the minimum
+	parameters := ParametersOf([]strategy.Param{{Name: "ratio", Type: strategy.ParamFloat, Min: 1, Max: 2}})
+	require.Len(t, parameters, 1)
+	require.InDelta(t, 0.1, parameters[0].Step, 1e-9)
+	require.Len(t, parameters[0].Values(), 11)
+}
+
+func TestResult_Score(t *testing.T) {
+	withLosses := order.Summary{WinLong: []float64{30}, WinLongPercent: []float64{0.3},
+		LoseLong: []float64{-10}, LoseLongPercent: []float64{-0.1}}
+	withoutLosses := order.Summary{WinLong: []float64{10}, WinLongPercent: []float64{0.1}}
+	require.InDelta(t, 3.0, profitFactor(withLosses), 1e-9)
+	require.Zero(t, profitFactor(order.Summary{}))
+
+	// results with only winning trades are ranked first
+	best := Result{ProfitFactor: profitFactor(withoutLosses)}
+	other := Result{ProfitFactor: profitFactor(withLosses)}
+	require.Greater(t, best.Score(MetricProfitFactor), other.Score(MetricProfitFactor))
+}
+
+func TestOptimizer_Search(t *testing.T) {
+	parameters := []Parameter{
+		{Name: "a", Min: 1, Max: 2, Step: 1},
+		{Name: "b", Min: 10, Max: 30, Step: 10},
+	}
+	opt := New(nil, ninjabot.Settings{}, nil, parameters, WithRandomSearch(5, 42))
+
+	grid := opt.Grid()
+	require.Len(t, grid, 6)
+	require.Contains(t, grid, Params{"a": 2, "b": 30})
+
+	random := opt.Random(5)
+	require.Len(t, random, 5)
+	require.Equal(t, random, opt.Random(5))
+	for _, params := range random {
+		require.Contains(t, grid, params)
+	}
+}
+
+func TestOptimizer_Run(t *testing.T) {
+	feed, err := exchange.NewCSVFeed("4h", exchange.PairFeed{
+		Pair:      "BTCUSDT",
+		File:      "../testdata/btc-1h.csv",
+		Timeframe: "1h",
+	})
+	require.NoError(t, err)
+
+	factory := func(params Params) (strategy.Strategy, error) {
+		return &strategies.CrossEMA{EMAPeriod: int(params["ema"]), SMAPeriod: int(params["sma"])}, nil
+	}
+
+	parameters := []Parameter{
+		{Name: "ema", Min: 8, Max: 9, Step: 1},
+		{Name: "sma", Min: 21, Max: 21},
+	}
+
+	settings := ninjabot.Settings{Pairs: []string{"BTCUSDT"}}
+	parallel, err := New(feed, settings, factory, parameters, WithWorkers(2)).Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, parallel, 2)
+	require.GreaterOrEqual(t, parallel[0].Profit, parallel[1].Profit)
+
+	sequential, err := New(feed, settings, factory, parameters).Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, sequential, parallel)
+
+	for _, result := range parallel {
+		require.NoError(t, result.Err)
+		require.Greater(t, result.Trades, 0)
+		require.Equal(t, result.Trades, result.Win+result.Lose)
+	}
+
+	byDrawdown, err := New(feed, settings, factory, parameters, WithMetric(MetricMaxDrawdown)).Run(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, byDrawdown[0].MaxDrawdown, byDrawdown[1].MaxDrawdown)
+
+	_, err = New(feed, settings, factory, parameters, WithMetric("invalid")).Run(context.Background())
+	require.ErrorIs(t, err, ErrInvalidMetric)
+
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, WriteCSV(buffer, parameters, parallel))
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "ema,sma,trades,win,lose,profit,return,sqn,profit_factor,max_drawdown,error", lines[0])
+}
@@ -0,0 +1,241 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/samber/lo"
+	"github.com/xhit/go-str2duration/v2"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+const year = 365 * 24 * time.Hour
+
+var ErrInsufficientPeriod = errors.New("insufficient period for walk-forward windows")
+
+// WalkForwardWindow is the result of the optimization in an in-sample period
+// and the evaluation of the best parameters in the following out-of-sample period
+type WalkForwardWindow struct {
+	InSampleStart  time.Time
+	InSampleEnd    time.Time
+	OutSampleStart time.Time
+	OutSampleEnd   time.Time
+
+	// InSample is the best result of the optimization
+	InSample  Result
+	OutSample Result
+	Equity    []exchange.AssetValue
+}
+
+type WalkForwardReport struct {
+	Metric  Metric
+	Windows []WalkForwardWindow
+	// Equity is the stitched equity of out-of-sample periods, each window starts with the final value of the previous
+	Equity []exchange.AssetValue
+	// Efficiency is the annualized out-of-sample return divided by the annualized in-sample return, with the
+	// returns compounded over the windows. It is NaN (N/A) when the in-sample return is not positive.
+	Efficiency float64
+}
+
+// WalkForward splits the feed history in rolling windows. The parameters are optimized in each in-sample period
+// and evaluated in the following out-of-sample period, then the windows move forward by the out-of-sample period.
+// The warmup period of the strategy is consumed from the beginning of each in-sample period, while the
+// out-of-sample periods are preceded by the warmup candles, measuring the equity only from their start.
+func (o *Optimizer) WalkForward(ctx context.Context, inSample, outSample time.Duration) (*WalkForwardReport, error) {
+	if inSample <= 0 || outSample <= 0 {
+		return nil, fmt.Errorf("%w: in-sample and out-of-sample must be positive", ErrInsufficientPeriod)
+	}
+
+	start, end := o.feed.Period()
+	report := &WalkForwardReport{Metric: o.metric}
+
+	inSampleReturn, outSampleReturn := 1.0, 1.0
+	for windowStart := start; !windowStart.Add(inSample + outSample).After(end); windowStart = windowStart.Add(outSample) {
+		window := WalkForwardWindow{
+			InSampleStart:  windowStart,
+			InSampleEnd:    windowStart.Add(inSample),
+			OutSampleStart: windowStart.Add(inSample),
+			OutSampleEnd:   windowStart.Add(inSample + outSample),
+		}
+
+		optimizer := *o
+		optimizer.feed = o.feed.Between(window.InSampleStart, window.InSampleEnd)
+		results, err := optimizer.Run(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(results) == 0 {
+			return nil, errors.New("empty parameters space")
+		}
+
+		if results[0].Err != nil {
+			return nil, fmt.Errorf("optimization of window %s failed: %w",
+				window.InSampleStart.Format(time.DateOnly), results[0].Err)
+		}
+
+		window.InSample = results[0]
+		warmup, err := o.warmup(window.InSample.Params)
+		if err != nil {
+			return nil, err
+		}
+
+		window.OutSample, window.Equity = o.backtest(ctx,
+			o.feed.Between(window.OutSampleStart.Add(-warmup), window.OutSampleEnd), window.InSample.Params)
+		if window.OutSample.Err != nil {
+			return nil, window.OutSample.Err
+		}
+
+		window.Equity = lo.Filter(window.Equity, func(value exchange.AssetValue, _ int) bool {
+			return !value.Time.Before(window.OutSampleStart)
+		})
+		window.OutSample.Return = 0
+		if len(window.Equity) > 0 && window.Equity[0].Value > 0 {
+			window.OutSample.Return = window.Equity[len(window.Equity)-1].Value/window.Equity[0].Value - 1
+		}
+
+		inSampleReturn *= 1 + window.InSample.Return
+		outSampleReturn *= 1 + window.OutSample.Return
+		report.Windows = append(report.Windows, window)
+	}
+
+	if len(report.Windows) == 0 {
+		return nil, ErrInsufficientPeriod
+	}
+
+	// compounded returns annualized by the total time of each sample type
+	inSampleYears := float64(inSample) * float64(len(report.Windows)) / float64(year)
+	outSampleYears := float64(outSample) * float64(len(report.Windows)) / float64(year)
+	report.Efficiency = math.NaN()
+	if inSampleReturn > 1 {
+		report.Efficiency = (math.Pow(outSampleReturn, 1/outSampleYears) - 1) /
+			(math.Pow(inSampleReturn, 1/inSampleYears) - 1)
+	}
+
+	report.Equity = stitchEquity(report.Windows)
+	return report, nil
+}
+
+// warmup returns the period consumed by the warmup of the strategy, considering all its timeframes
+func (o *Optimizer) warmup(params Params) (time.Duration, error) {
+	str, err := o.factory(params)
+	if err != nil {
+		return 0, err
+	}
+
+	timeframes := map[string]int{str.Timeframe(): str.WarmupPeriod()}
+	if multiTimeframe, ok := str.(strategy.MultiTimeframeStrategy); ok {
+		for timeframe, warmup := range multiTimeframe.Timeframes() {
+			timeframes[timeframe] = warmup
+		}
+	}
+
+	var warmup time.Duration
+	for timeframe, candles := range timeframes {
+		duration, err := str2duration.ParseDuration(timeframe)
+		if err != nil {
+			return 0, err
+		}
+
+		if period := time.Duration(candles) * duration; period > warmup {
+			warmup = period
+		}
+	}
+	return warmup, nil
+}
+
+// stitchEquity joins the out-of-sample equity curves, scaling each curve by the final value of the previous one
+func stitchEquity(windows []WalkForwardWindow) []exchange.AssetValue {
+	equity := make([]exchange.AssetValue, 0)
+	scale := 1.0
+	for _, window := range windows {
+		if len(window.Equity) == 0 || window.Equity[0].Value == 0 {
+			continue
+		}
+
+		if len(equity) > 0 {
+			scale = equity[len(equity)-1].Value / window.Equity[0].Value
+		}
+
+		for _, value := range window.Equity {
+			equity = append(equity, exchange.AssetValue{
+				Time:  value.Time,
+				Value: value.Value * scale,
+			})
+		}
+	}
+	return equity
+}
+
+func (r WalkForwardReport) String() string {
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+	table.SetHeader([]string{"In-Sample", "Out-of-Sample", "Params", "IS Return", "OOS Return",
+		"IS " + string(r.Metric), "OOS " + string(r.Metric)})
+	table.SetFooterAlignment(tablewriter.ALIGN_RIGHT)
+
+	var outSampleReturn float64
+	if len(r.Equity) > 0 && r.Equity[0].Value > 0 {
+		outSampleReturn = r.Equity[len(r.Equity)-1].Value/r.Equity[0].Value - 1
+	}
+
+	for _, window := range r.Windows {
+		params := make([]string, 0, len(window.InSample.Params))
+		for name, value := range window.InSample.Params {
+			params = append(params, fmt.Sprintf("%s=%s", name, strconv.FormatFloat(value, 'f', -1, 64)))
+		}
+		sort.Strings(params)
+
+		table.Append([]string{
+			fmt.Sprintf("%s ~ %s", window.InSampleStart.Format(time.DateOnly), window.InSampleEnd.Format(time.DateOnly)),
+			fmt.Sprintf("%s ~ %s", window.OutSampleStart.Format(time.DateOnly), window.OutSampleEnd.Format(time.DateOnly)),
+			strings.Join(params, " "),
+			fmt.Sprintf("%.2f %%", window.InSample.Return*100),
+			fmt.Sprintf("%.2f %%", window.OutSample.Return*100),
+			fmt.Sprintf("%.3f", window.InSample.Score(r.Metric)),
+			fmt.Sprintf("%.3f", window.OutSample.Score(r.Metric)),
+		})
+	}
+
+	efficiency := "N/A"
+	if !math.IsNaN(r.Efficiency) {
+		efficiency = fmt.Sprintf("%.2f", r.Efficiency)
+	}
+
+	table.SetFooter([]string{"", "", "OOS TOTAL", "", fmt.Sprintf("%.2f %%", outSampleReturn*100),
+		"EFFICIENCY", efficiency})
+	table.Render()
+	return tableString.String()
+}
+
+// WriteEquityCSV writes the stitched out-of-sample equity curve in CSV format (time, value)
+func (r WalkForwardReport) WriteEquityCSV(writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write([]string{"time", "equity"}); err != nil {
+		return err
+	}
+
+	for _, value := range r.Equity {
+		err := csvWriter.Write([]string{
+			strconv.FormatInt(value.Time.Unix(), 10),
+			strconv.FormatFloat(value.Value, 'f', 4, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,71 @@
+package optimizer
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot"
+	"github.com/rodrigo-brito/ninjabot/examples/strategies"
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+func TestOptimizer_WalkForward(t *testing.T) {
+	feed, err := exchange.NewCSVFeed("4h", exchange.PairFeed{
+		Pair:      "BTCUSDT",
+		File:      "../testdata/btc-1h.csv",
+		Timeframe: "1h",
+	})
+	require.NoError(t, err)
+
+	factory := func(params Params) (strategy.Strategy, error) {
+		return &strategies.CrossEMA{EMAPeriod: int(params["ema"]), SMAPeriod: 21}, nil
+	}
+
+	opt := New(feed, ninjabot.Settings{Pairs: []string{"BTCUSDT"}}, factory, []Parameter{
+		{Name: "ema", Min: 6, Max: 10, Step: 2},
+	}, WithWorkers(2))
+
+	report, err := opt.WalkForward(context.Background(), 60*24*time.Hour, 30*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, report.Windows, 4)
+
+	for i, window := range report.Windows {
+		require.Equal(t, window.InSampleEnd, window.OutSampleStart)
+		require.Equal(t, 30*24*time.Hour, window.OutSampleEnd.Sub(window.OutSampleStart))
+		require.NotEmpty(t, window.Equity)
+		require.False(t, window.Equity[0].Time.Before(window.OutSampleStart))
+		require.Less(t, window.Equity[len(window.Equity)-1].Time, window.OutSampleEnd)
+		require.InDelta(t, window.Equity[len(window.Equity)-1].Value/window.Equity[0].Value-1,
+			window.OutSample.Return, 1e-9)
+		if i > 0 {
+			require.Equal(t, report.Windows[i-1].OutSampleEnd, window.OutSampleStart)
+		}
+	}
+
+	// stitched equity is continuous between windows
+	first := report.Windows[0]
+	second := report.Windows[1]
+	require.Equal(t, first.Equity[len(first.Equity)-1].Value, report.Equity[len(first.Equity)].Value)
+	require.InDelta(t, second.Equity[1].Value/second.Equity[0].Value,
+		report.Equity[len(first.Equity)+1].Value/report.Equity[len(first.Equity)].Value, 1e-9)
+
+	require.NotZero(t, report.Efficiency)
+	require.Contains(t, report.String(), "EFFICIENCY")
+
+	// efficiency is not available without in-sample profits
+	require.Contains(t, WalkForwardReport{Efficiency: math.NaN()}.String(), "N/A")
+
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, report.WriteEquityCSV(buffer))
+	require.Len(t, strings.Split(strings.TrimSpace(buffer.String()), "\n"), len(report.Equity)+1)
+
+	_, err = opt.WalkForward(context.Background(), 365*24*time.Hour, 30*24*time.Hour)
+	require.ErrorIs(t, err, ErrInsufficientPeriod)
+}
@@ -0,0 +1,315 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/storage"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+var ErrInvalidBracket = errors.New("invalid bracket: take-profit and stop-loss must be on opposite sides of the entry")
+
+// CreateOrderBracket creates an entry order with attached take-profit and stop-loss exits. A price equal to zero
+// creates a market entry, otherwise a limit entry. The exits are submitted when the entry is filled, as an OCO
+// order when supported by the exchange. Otherwise, the controller emulates the OCO behavior: the exits are
+// independent orders and the sibling is canceled when one of them is filled. Stop-loss orders of short positions
+// are not supported by the exchanges, they are triggered by the controller with market orders.
+func (c *Controller) CreateOrderBracket(side model.SideType, pair string, size, price, takeProfit,
+	stopLoss float64) (model.Bracket, error) {
+	return c.createOrderBracket("", side, pair, size, price, takeProfit, stopLoss)
+}
+
+func (c *Controller) createOrderBracket(strategy string, side model.SideType, pair string, size, price, takeProfit,
+	stopLoss float64) (model.Bracket, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	entryPrice := price
+	if entryPrice == 0 {
+		entryPrice = c.lastPrice[pair]
+	}
+
+	if (side == model.SideTypeBuy && (takeProfit <= entryPrice || stopLoss >= entryPrice)) ||
+		(side == model.SideTypeSell && (takeProfit >= entryPrice || stopLoss <= entryPrice)) {
+		return model.Bracket{}, &exchange.OrderError{
+			Err:      ErrInvalidBracket,
+			Pair:     pair,
+			Quantity: size,
+		}
+	}
+
+	log.Infof("[ORDER] Creating BRACKET %s order for %s", side, pair)
+	var (
+		entry model.Order
+		err   error
+	)
+	if price > 0 {
+		entry, err = c.exchange.CreateOrderLimit(side, pair, size, price)
+	} else {
+		entry, err = c.exchange.CreateOrderMarket(side, pair, size)
+	}
+	if err != nil {
+		c.notifyError(err)
+		return model.Bracket{}, err
+	}
+
+	entry.Strategy = strategy
+	err = c.storage.CreateOrder(&entry)
+	if err != nil {
+		c.notifyError(err)
+		return model.Bracket{}, err
+	}
+
+	bracket := &model.Bracket{
+		Strategy:   strategy,
+		Pair:       pair,
+		Side:       side,
+		Quantity:   size,
+		Price:      price,
+		TakeProfit: takeProfit,
+		StopLoss:   stopLoss,
+		Status:     model.BracketStatusPending,
+		EntryID:    entry.ID,
+		CreatedAt:  entry.CreatedAt,
+		UpdatedAt:  entry.UpdatedAt,
+	}
+	err = c.storage.SaveBracket(bracket)
+	if err != nil {
+		c.notifyError(err)
+		return model.Bracket{}, err
+	}
+	c.brackets[bracket.ID] = bracket
+
+	// market entries are filled immediately, submitting the exits
+	c.processTrade(&entry)
+	go c.orderFeed.Publish(entry, true)
+	log.Infof("[ORDER CREATED] %s", entry)
+	return *bracket, nil
+}
+
+// Brackets returns the open bracket orders
+func (c *Controller) Brackets() []model.Bracket {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	brackets := make([]model.Bracket, 0, len(c.brackets))
+	for _, bracket := range c.brackets {
+		brackets = append(brackets, *bracket)
+	}
+	return brackets
+}
+
+// saveBracket persists the bracket state, brackets are released from memory when closed
+func (c *Controller) saveBracket(bracket *model.Bracket, status model.BracketStatus, order *model.Order) {
+	bracket.Status = status
+	bracket.UpdatedAt = order.UpdatedAt
+	if !bracket.Open() {
+		delete(c.brackets, bracket.ID)
+		log.Infof("[BRACKET %s] %s %s | ID: %d", bracket.Status, bracket.Side, bracket.Pair, bracket.ID)
+	}
+
+	if err := c.storage.SaveBracket(bracket); err != nil {
+		c.notifyError(err)
+	}
+}
+
+// updateBrackets updates the brackets with a new state of one of their orders
+func (c *Controller) updateBrackets(order *model.Order) {
+	if order.ID == 0 {
+		return
+	}
+
+	for _, bracket := range c.brackets {
+		switch order.ID {
+		case bracket.EntryID:
+			c.onBracketEntry(bracket, order)
+		case bracket.TakeProfitID:
+			c.onBracketExit(bracket, order, bracket.StopLossID)
+		case bracket.StopLossID:
+			c.onBracketExit(bracket, order, bracket.TakeProfitID)
+		}
+	}
+}
+
+func (c *Controller) onBracketEntry(bracket *model.Bracket, entry *model.Order) {
+	if bracket.Status != model.BracketStatusPending {
+		return
+	}
+
+	switch entry.Status {
+	case model.OrderStatusTypeFilled:
+		c.submitExits(bracket, entry, entry.Quantity)
+	case model.OrderStatusTypeCanceled, model.OrderStatusTypeRejected, model.OrderStatusTypeExpired:
+		// protect the quantity executed before the cancellation
+		if entry.ExecutedQuantity > 0 {
+			c.submitExits(bracket, entry, entry.ExecutedQuantity)
+			return
+		}
+		c.saveBracket(bracket, model.BracketStatusCanceled, entry)
+	}
+}
+
+// submitExits creates the take-profit and stop-loss orders of a filled entry
+func (c *Controller) submitExits(bracket *model.Bracket, entry *model.Order, quantity float64) {
+	bracket.Quantity = quantity
+	side := bracket.ExitSide()
+
+	if side == model.SideTypeSell {
+		orders, err := c.exchange.CreateOrderOCO(side, bracket.Pair, quantity, bracket.TakeProfit, bracket.StopLoss,
+			bracket.StopLoss)
+		switch {
+		case err == nil:
+			bracket.Native = true
+			for i := range orders {
+				if !c.createExit(bracket, &orders[i]) {
+					return
+				}
+			}
+			c.saveBracket(bracket, model.BracketStatusActive, entry)
+			return
+		case !errors.Is(err, exchange.ErrOCONotSupported):
+			c.notifyError(fmt.Errorf("bracket %d: %w", bracket.ID, err))
+			c.saveBracket(bracket, model.BracketStatusFailed, entry)
+			return
+		}
+	}
+
+	takeProfit, err := c.exchange.CreateOrderLimit(side, bracket.Pair, quantity, bracket.TakeProfit)
+	if err != nil {
+		c.notifyError(fmt.Errorf("bracket %d: %w", bracket.ID, err))
+		c.saveBracket(bracket, model.BracketStatusFailed, entry)
+		return
+	}
+
+	if !c.createExit(bracket, &takeProfit) {
+		return
+	}
+
+	// stop orders are only available for sell orders, buy stops are triggered by the controller
+	if side == model.SideTypeSell {
+		stopLoss, err := c.exchange.CreateOrderStop(bracket.Pair, quantity, bracket.StopLoss)
+		if err != nil {
+			c.notifyError(fmt.Errorf("bracket %d: %w", bracket.ID, err))
+			_ = c.cancel(takeProfit)
+			c.saveBracket(bracket, model.BracketStatusFailed, entry)
+			return
+		}
+
+		if !c.createExit(bracket, &stopLoss) {
+			return
+		}
+	}
+
+	c.saveBracket(bracket, model.BracketStatusActive, entry)
+}
+
+// createExit registers an exit order of the bracket
+func (c *Controller) createExit(bracket *model.Bracket, order *model.Order) bool {
+	order.Strategy = bracket.Strategy
+	err := c.storage.CreateOrder(order)
+	if err != nil {
+		c.notifyError(err)
+		c.saveBracket(bracket, model.BracketStatusFailed, order)
+		return false
+	}
+
+	if order.Type == model.OrderTypeStopLoss || order.Type == model.OrderTypeStopLossLimit ||
+		order.Type == model.OrderTypeMarket {
+		bracket.StopLossID = order.ID
+	} else {
+		bracket.TakeProfitID = order.ID
+	}
+
+	go c.orderFeed.Publish(*order, true)
+	log.Infof("[ORDER CREATED] %s", order)
+	return true
+}
+
+func (c *Controller) onBracketExit(bracket *model.Bracket, exit *model.Order, siblingID int64) {
+	if bracket.Status != model.BracketStatusActive {
+		return
+	}
+
+	switch exit.Status {
+	case model.OrderStatusTypeFilled:
+		// the exchange cancels the sibling of OCO orders
+		if !bracket.Native {
+			c.cancelSibling(siblingID)
+		}
+		c.saveBracket(bracket, model.BracketStatusClosed, exit)
+	case model.OrderStatusTypeCanceled, model.OrderStatusTypeRejected, model.OrderStatusTypeExpired:
+		sibling := c.pendingOrder(siblingID)
+		if bracket.Native && sibling != nil {
+			// wait the update of the sibling, it may be filled
+			return
+		}
+
+		if sibling != nil {
+			_ = c.cancel(*sibling)
+		}
+		c.saveBracket(bracket, model.BracketStatusCanceled, exit)
+	}
+}
+
+// pendingOrder returns a stored order, if it is still pending
+func (c *Controller) pendingOrder(id int64) *model.Order {
+	if id == 0 {
+		return nil
+	}
+
+	orders, err := c.storage.Orders(storage.WithID(id))
+	if err != nil {
+		c.notifyError(err)
+		return nil
+	}
+
+	if len(orders) == 0 || !isPending(orders[0].Status) {
+		return nil
+	}
+	return orders[0]
+}
+
+func (c *Controller) cancelSibling(id int64) {
+	if sibling := c.pendingOrder(id); sibling != nil {
+		if err := c.cancel(*sibling); err != nil {
+			c.notifyError(err)
+		}
+	}
+}
+
+// triggerBrackets executes the stop-loss of brackets without stop orders, when the adverse extreme of the
+// candle (high for short positions, low for long positions) reaches the stop. Only live candles are sent to the
+// controller, the restored brackets are not triggered by the warmup candles of a restart.
+func (c *Controller) triggerBrackets(candle model.Candle) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, bracket := range c.brackets {
+		if bracket.Pair != candle.Pair || bracket.Status != model.BracketStatusActive || bracket.StopLossID != 0 {
+			continue
+		}
+
+		side := bracket.ExitSide()
+		if (side == model.SideTypeBuy && candle.High < bracket.StopLoss) ||
+			(side == model.SideTypeSell && candle.Low > bracket.StopLoss) {
+			continue
+		}
+
+		log.Infof("[BRACKET] Stop-loss triggered for %s at %f", bracket.Pair, bracket.StopLoss)
+		order, err := c.exchange.CreateOrderMarket(side, bracket.Pair, bracket.Quantity)
+		if err != nil {
+			c.notifyError(fmt.Errorf("bracket %d: %w", bracket.ID, err))
+			continue
+		}
+
+		if !c.createExit(bracket, &order) {
+			continue
+		}
+
+		// closes the bracket and cancels the take-profit
+		c.processTrade(&order)
+	}
+}
@@ -0,0 +1,211 @@
+package order
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/storage"
+)
+
+// exchange without OCO orders, e.g. Binance Futures
+type noOCOExchange struct {
+	*exchange.PaperWallet
+}
+
+func (noOCOExchange) CreateOrderOCO(_ model.SideType, _ string, _, _, _, _ float64) ([]model.Order, error) {
+	return nil, exchange.ErrOCONotSupported
+}
+
+func storedOrder(t *testing.T, repo storage.Storage, id int64) *model.Order {
+	t.Helper()
+	orders, err := repo.Orders(storage.WithID(id))
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	return orders[0]
+}
+
+func TestController_CreateOrderBracket(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func(native bool) (*exchange.PaperWallet, *Controller, storage.Storage) {
+		repo, err := storage.FromMemory()
+		require.NoError(t, err)
+		wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 1000))
+		var controller *Controller
+		if native {
+			controller = NewController(ctx, wallet, repo, NewOrderFeed())
+		} else {
+			controller = NewController(ctx, noOCOExchange{wallet}, repo, NewOrderFeed())
+		}
+
+		candle := model.Candle{Pair: "BTCUSDT", Time: start, High: 100, Low: 100, Close: 100, Complete: true}
+		wallet.OnCandle(candle)
+		controller.OnCandle(candle)
+		return wallet, controller, repo
+	}
+
+	t.Run("invalid prices", func(t *testing.T) {
+		_, controller, _ := setup(true)
+		_, err := controller.CreateOrderBracket(model.SideTypeBuy, "BTCUSDT", 1, 0, 90, 110)
+		require.ErrorIs(t, err, ErrInvalidBracket)
+
+		_, err = controller.CreateOrderBracket(model.SideTypeSell, "BTCUSDT", 1, 0, 110, 90)
+		require.ErrorIs(t, err, ErrInvalidBracket)
+	})
+
+	t.Run("native oco", func(t *testing.T) {
+		wallet, controller, repo := setup(true)
+		bracket, err := controller.CreateOrderBracket(model.SideTypeBuy, "BTCUSDT", 1, 0, 110, 90)
+		require.NoError(t, err)
+
+		brackets := controller.Brackets()
+		require.Len(t, brackets, 1)
+		require.Equal(t, model.BracketStatusActive, brackets[0].Status)
+		require.True(t, brackets[0].Native)
+		require.Equal(t, model.OrderTypeLimitMaker, storedOrder(t, repo, brackets[0].TakeProfitID).Type)
+		require.Equal(t, model.OrderTypeStopLoss, storedOrder(t, repo, brackets[0].StopLossID).Type)
+
+		// take-profit reached
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), High: 111, Low: 100, Close: 110})
+		controller.updateOrders()
+
+		require.Empty(t, controller.Brackets())
+		require.Equal(t, model.OrderStatusTypeFilled, storedOrder(t, repo, brackets[0].TakeProfitID).Status)
+		require.Equal(t, model.OrderStatusTypeCanceled, storedOrder(t, repo, brackets[0].StopLossID).Status)
+
+		stored, err := repo.Brackets()
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		require.Equal(t, bracket.ID, stored[0].ID)
+		require.Equal(t, model.BracketStatusClosed, stored[0].Status)
+		require.InDelta(t, 10.0, controller.Results["BTCUSDT"].Profit(), 1e-9)
+	})
+
+	t.Run("emulated oco", func(t *testing.T) {
+		wallet, controller, repo := setup(false)
+
+		// limit entry, exits are submitted after the fill
+		_, err := controller.CreateOrderBracket(model.SideTypeBuy, "BTCUSDT", 1, 95, 110, 90)
+		require.NoError(t, err)
+		brackets := controller.Brackets()
+		require.Len(t, brackets, 1)
+		require.Equal(t, model.BracketStatusPending, brackets[0].Status)
+		require.Zero(t, brackets[0].TakeProfitID)
+
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), High: 100, Low: 95, Close: 95})
+		controller.updateOrders()
+
+		brackets = controller.Brackets()
+		require.Len(t, brackets, 1)
+		require.Equal(t, model.BracketStatusActive, brackets[0].Status)
+		require.False(t, brackets[0].Native)
+		require.Equal(t, model.OrderTypeLimit, storedOrder(t, repo, brackets[0].TakeProfitID).Type)
+		require.Equal(t, model.OrderTypeStopLossLimit, storedOrder(t, repo, brackets[0].StopLossID).Type)
+
+		// stop-loss reached, take-profit is canceled by the controller
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Time: start.Add(2 * time.Hour), High: 95, Low: 89, Close: 89})
+		controller.updateOrders()
+
+		require.Empty(t, controller.Brackets())
+		require.Equal(t, model.OrderStatusTypeFilled, storedOrder(t, repo, brackets[0].StopLossID).Status)
+		require.Equal(t, model.OrderStatusTypePendingCancel,
+			storedOrder(t, repo, brackets[0].TakeProfitID).Status)
+
+		takeProfit, err := wallet.Order("BTCUSDT", storedOrder(t, repo, brackets[0].TakeProfitID).ExchangeID)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderStatusTypeCanceled, takeProfit.Status)
+	})
+
+	t.Run("short with stop triggered by the controller", func(t *testing.T) {
+		wallet, controller, repo := setup(true)
+		_, err := controller.CreateOrderBracket(model.SideTypeSell, "BTCUSDT", 1, 0, 90, 110)
+		require.NoError(t, err)
+
+		brackets := controller.Brackets()
+		require.Len(t, brackets, 1)
+		require.Equal(t, model.BracketStatusActive, brackets[0].Status)
+		require.Zero(t, brackets[0].StopLossID)
+
+		candle := model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), High: 111, Low: 100, Close: 111,
+			Complete: true}
+		wallet.OnCandle(candle)
+		controller.OnCandle(candle)
+
+		require.Empty(t, controller.Brackets())
+		stored, err := repo.Brackets()
+		require.NoError(t, err)
+		require.Equal(t, model.BracketStatusClosed, stored[0].Status)
+
+		stopLoss := storedOrder(t, repo, stored[0].StopLossID)
+		require.Equal(t, model.OrderTypeMarket, stopLoss.Type)
+		require.Equal(t, model.SideTypeBuy, stopLoss.Side)
+		require.Equal(t, model.OrderStatusTypeFilled, stopLoss.Status)
+		require.Equal(t, model.OrderStatusTypePendingCancel,
+			storedOrder(t, repo, brackets[0].TakeProfitID).Status)
+
+		takeProfit, err := wallet.Order("BTCUSDT", storedOrder(t, repo, stored[0].TakeProfitID).ExchangeID)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderStatusTypeCanceled, takeProfit.Status)
+		require.Len(t, controller.Results["BTCUSDT"].Trades, 1)
+	})
+
+	t.Run("short with stop triggered by the high of a partial candle", func(t *testing.T) {
+		wallet, controller, repo := setup(true)
+		_, err := controller.CreateOrderBracket(model.SideTypeSell, "BTCUSDT", 1, 0, 90, 110)
+		require.NoError(t, err)
+
+		candle := model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), High: 109, Low: 100, Close: 105}
+		wallet.OnCandle(candle)
+		controller.OnPartialCandle(candle)
+		require.Len(t, controller.Brackets(), 1)
+
+		candle.High = 112
+		wallet.OnCandle(candle)
+		controller.OnPartialCandle(candle)
+		require.Empty(t, controller.Brackets())
+
+		stored, err := repo.Brackets()
+		require.NoError(t, err)
+		require.Equal(t, model.BracketStatusClosed, stored[0].Status)
+		require.Equal(t, model.OrderStatusTypeFilled, storedOrder(t, repo, stored[0].StopLossID).Status)
+	})
+
+	t.Run("canceled entry", func(t *testing.T) {
+		_, controller, repo := setup(true)
+		bracket, err := controller.CreateOrderBracket(model.SideTypeBuy, "BTCUSDT", 1, 95, 110, 90)
+		require.NoError(t, err)
+
+		require.NoError(t, controller.Cancel(*storedOrder(t, repo, bracket.EntryID)))
+		controller.updateOrders()
+
+		require.Empty(t, controller.Brackets())
+		stored, err := repo.Brackets()
+		require.NoError(t, err)
+		require.Equal(t, model.BracketStatusCanceled, stored[0].Status)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		wallet, controller, repo := setup(true)
+		bracket, err := controller.CreateOrderBracket(model.SideTypeBuy, "BTCUSDT", 1, 95, 110, 90)
+		require.NoError(t, err)
+
+		// entry filled while the bot was offline
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), High: 100, Low: 95, Close: 95})
+
+		restored := NewController(ctx, wallet, repo, NewOrderFeed())
+		require.NoError(t, restored.Restore())
+		require.Len(t, restored.Brackets(), 1)
+
+		restored.updateOrders()
+		brackets := restored.Brackets()
+		require.Len(t, brackets, 1)
+		require.Equal(t, bracket.ID, brackets[0].ID)
+		require.Equal(t, model.BracketStatusActive, brackets[0].Status)
+	})
+}
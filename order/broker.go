@@ -0,0 +1,325 @@
+package order
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/storage"
+)
+
+var ErrBudgetExceeded = errors.New("strategy budget exceeded")
+
+// StrategyBroker is the broker handed to a single strategy. It tags the orders with the strategy name
+// and, when a capital budget is defined, limits the strategy to a virtual balance funded by the budget.
+type StrategyBroker struct {
+	mtx        sync.Mutex
+	controller *Controller
+	name       string
+	capital    float64
+	balances   map[string]float64
+	funded     map[string]bool
+
+	// quote reserved by the pending buy orders, by order ID. It is loaded from the storage on the first use,
+	// then updated with the orders saved by the controller, see orderStorage
+	pending map[int64]lockedOrder
+}
+
+// lockedOrder is the quote value reserved by a pending buy order
+type lockedOrder struct {
+	quote string
+	value float64
+}
+
+// orderStorage notifies the strategy brokers of the orders saved by the controller,
+// to track the quote reserved by their pending orders without listing all orders on each validation
+type orderStorage struct {
+	storage.Storage
+	controller *Controller
+}
+
+func (s orderStorage) CreateOrder(order *model.Order) error {
+	if err := s.Storage.CreateOrder(order); err != nil {
+		return err
+	}
+	s.controller.onOrderSaved(*order)
+	return nil
+}
+
+func (s orderStorage) UpdateOrder(order *model.Order) error {
+	if err := s.Storage.UpdateOrder(order); err != nil {
+		return err
+	}
+	s.controller.onOrderSaved(*order)
+	return nil
+}
+
+func (c *Controller) onOrderSaved(order model.Order) {
+	if broker, ok := c.brokers[order.Strategy]; ok {
+		broker.onOrder(order)
+	}
+}
+
+// StrategyBroker returns the broker of a given strategy. The capital is the budget of the strategy,
+// in the quote asset of its pairs. A capital equal to zero shares the whole account balance.
+func (c *Controller) StrategyBroker(name string, capital float64) *StrategyBroker {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if broker, ok := c.brokers[name]; ok {
+		return broker
+	}
+
+	broker := &StrategyBroker{
+		controller: c,
+		name:       name,
+		capital:    capital,
+		balances:   make(map[string]float64),
+		funded:     make(map[string]bool),
+	}
+	c.brokers[name] = broker
+	return broker
+}
+
+// Name returns the strategy name used to tag the orders
+func (b *StrategyBroker) Name() string {
+	return b.name
+}
+
+// Capital returns the budget allocated to the strategy
+func (b *StrategyBroker) Capital() float64 {
+	return b.capital
+}
+
+// fund initializes the virtual balance of the quote asset with the strategy budget
+func (b *StrategyBroker) fund(pair string) (asset, quote string) {
+	asset, quote = exchange.SplitAssetQuote(pair)
+	if !b.funded[quote] {
+		b.balances[quote] += b.capital
+		b.funded[quote] = true
+	}
+	return asset, quote
+}
+
+// lockedQuote returns the quote value reserved by pending buy orders of the strategy
+func (b *StrategyBroker) lockedQuote(quote string) (float64, error) {
+	if b.pending == nil {
+		orders, err := b.controller.storage.Orders(
+			storage.WithStrategy(b.name),
+			storage.WithStatusIn(model.OrderStatusTypeNew, model.OrderStatusTypePartiallyFilled),
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		b.pending = make(map[int64]lockedOrder)
+		for _, order := range orders {
+			b.track(*order)
+		}
+	}
+
+	var locked float64
+	for _, order := range b.pending {
+		if order.quote == quote {
+			locked += order.value
+		}
+	}
+	return locked, nil
+}
+
+// onOrder updates the quote reserved by an order saved by the controller
+func (b *StrategyBroker) onOrder(order model.Order) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	// not loaded yet, the order is read from the storage on the first use
+	if b.pending == nil {
+		return
+	}
+	b.track(order)
+}
+
+func (b *StrategyBroker) track(order model.Order) {
+	if order.Side != model.SideTypeBuy || (order.Status != model.OrderStatusTypeNew &&
+		order.Status != model.OrderStatusTypePartiallyFilled) {
+		delete(b.pending, order.ID)
+		return
+	}
+
+	_, quote := exchange.SplitAssetQuote(order.Pair)
+	b.pending[order.ID] = lockedOrder{quote: quote, value: order.Price * order.Quantity}
+}
+
+// validate checks if the strategy has enough budget to execute an order
+func (b *StrategyBroker) validate(side model.SideType, pair string, size, price float64) error {
+	if b.capital <= 0 {
+		return nil
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	asset, quote := b.fund(pair)
+	locked, err := b.lockedQuote(quote)
+	if err != nil {
+		return err
+	}
+
+	cost := size * price
+	if side == model.SideTypeSell {
+		// selling more than the strategy position requires margin from the budget
+		cost = math.Max(size-math.Max(b.balances[asset], 0), 0) * price
+	}
+
+	// tolerance for rounding errors when the size is calculated from the available balance
+	available := b.balances[quote] - locked
+	if cost > 0 && cost-available > math.Abs(available)*1e-9 {
+		return &exchange.OrderError{
+			Err:      ErrBudgetExceeded,
+			Pair:     pair,
+			Quantity: size,
+		}
+	}
+
+	return nil
+}
+
+// onTrade updates the virtual balance of the strategy with a filled order
+func (b *StrategyBroker) onTrade(order model.Order) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	price := order.Price
+	if order.Type == model.OrderTypeStopLoss || order.Type == model.OrderTypeStopLossLimit {
+		price = *order.Stop
+	}
+
+	asset, quote := b.fund(order.Pair)
+	if order.Side == model.SideTypeBuy {
+		b.balances[asset] += order.Quantity
+		b.balances[quote] -= order.Quantity * price
+	} else {
+		b.balances[asset] -= order.Quantity
+		b.balances[quote] += order.Quantity * price
+	}
+}
+
+// lastPrice returns the last known price of a pair
+func (b *StrategyBroker) lastPrice(pair string) (float64, error) {
+	if price, ok := b.controller.lastPrice[pair]; ok && price > 0 {
+		return price, nil
+	}
+	return b.controller.LastQuote(pair)
+}
+
+func (b *StrategyBroker) Account() (model.Account, error) {
+	return b.controller.Account()
+}
+
+// Position returns the virtual position of the strategy when a budget is defined,
+// otherwise it returns the position of the account
+func (b *StrategyBroker) Position(pair string) (asset, quote float64, err error) {
+	if b.capital <= 0 {
+		return b.controller.Position(pair)
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	assetTick, quoteTick := b.fund(pair)
+	locked, err := b.lockedQuote(quoteTick)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return b.balances[assetTick], b.balances[quoteTick] - locked, nil
+}
+
+func (b *StrategyBroker) Order(pair string, id int64) (model.Order, error) {
+	return b.controller.Order(pair, id)
+}
+
+func (b *StrategyBroker) CreateOrderOCO(side model.SideType, pair string, size, price, stop,
+	stopLimit float64) ([]model.Order, error) {
+	if err := b.validate(side, pair, size, price); err != nil {
+		return nil, err
+	}
+	return b.controller.createOrderOCO(b.name, side, pair, size, price, stop, stopLimit)
+}
+
+func (b *StrategyBroker) CreateOrderLimit(side model.SideType, pair string, size, limit float64) (model.Order, error) {
+	if err := b.validate(side, pair, size, limit); err != nil {
+		return model.Order{}, err
+	}
+	return b.controller.createOrderLimit(b.name, side, pair, size, limit)
+}
+
+func (b *StrategyBroker) CreateOrderMarket(side model.SideType, pair string, size float64) (model.Order, error) {
+	if b.capital > 0 {
+		price, err := b.lastPrice(pair)
+		if err != nil {
+			return model.Order{}, err
+		}
+
+		if err := b.validate(side, pair, size, price); err != nil {
+			return model.Order{}, err
+		}
+	}
+	return b.controller.createOrderMarket(b.name, side, pair, size)
+}
+
+func (b *StrategyBroker) CreateOrderMarketQuote(side model.SideType, pair string,
+	amount float64) (model.Order, error) {
+	if b.capital > 0 {
+		price, err := b.lastPrice(pair)
+		if err != nil {
+			return model.Order{}, err
+		}
+
+		if err := b.validate(side, pair, amount/price, price); err != nil {
+			return model.Order{}, err
+		}
+	}
+	return b.controller.createOrderMarketQuote(b.name, side, pair, amount)
+}
+
+func (b *StrategyBroker) CreateOrderStop(pair string, size float64, limit float64) (model.Order, error) {
+	if err := b.validate(model.SideTypeSell, pair, size, limit); err != nil {
+		return model.Order{}, err
+	}
+	return b.controller.createOrderStop(b.name, pair, size, limit)
+}
+
+func (b *StrategyBroker) CreateOrderBracket(side model.SideType, pair string, size, price, takeProfit,
+	stopLoss float64) (model.Bracket, error) {
+	if b.capital > 0 {
+		entryPrice := price
+		if entryPrice == 0 {
+			var err error
+			entryPrice, err = b.lastPrice(pair)
+			if err != nil {
+				return model.Bracket{}, err
+			}
+		}
+
+		if err := b.validate(side, pair, size, entryPrice); err != nil {
+			return model.Bracket{}, err
+		}
+	}
+	return b.controller.createOrderBracket(b.name, side, pair, size, price, takeProfit, stopLoss)
+}
+
+func (b *StrategyBroker) CreateTrailingStop(side model.SideType, pair string, size,
+	distance float64) (model.TrailingStop, error) {
+	return b.controller.createTrailingStop(b.name, side, pair, size, distance)
+}
+
+func (b *StrategyBroker) CancelTrailingStop(stop model.TrailingStop) error {
+	return b.controller.CancelTrailingStop(stop)
+}
+
+func (b *StrategyBroker) Cancel(order model.Order) error {
+	return b.controller.Cancel(order)
+}
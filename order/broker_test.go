@@ -0,0 +1,94 @@
+package order
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/storage"
+)
+
+func TestStrategyBroker(t *testing.T) {
+	repo, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 10000))
+	controller := NewController(ctx, wallet, repo, NewOrderFeed())
+
+	broker := controller.StrategyBroker("test", 1000)
+	require.Same(t, broker, controller.StrategyBroker("test", 1000))
+
+	candle := model.Candle{Pair: "BTCUSDT", Close: 100, Complete: true}
+	wallet.OnCandle(candle)
+	controller.OnCandle(candle)
+
+	asset, quote, err := broker.Position("BTCUSDT")
+	require.NoError(t, err)
+	require.Equal(t, 0.0, asset)
+	require.Equal(t, 1000.0, quote)
+
+	t.Run("budget exceeded", func(t *testing.T) {
+		_, err := broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 11)
+		require.ErrorIs(t, err, ErrBudgetExceeded)
+
+		_, err = broker.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 20, 60)
+		require.ErrorIs(t, err, ErrBudgetExceeded)
+	})
+
+	t.Run("virtual position", func(t *testing.T) {
+		order, err := broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 5)
+		require.NoError(t, err)
+		require.Equal(t, "test", order.Strategy)
+
+		asset, quote, err := broker.Position("BTCUSDT")
+		require.NoError(t, err)
+		require.Equal(t, 5.0, asset)
+		require.Equal(t, 500.0, quote)
+
+		// pending orders reserve the budget
+		_, err = broker.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 4, 50)
+		require.NoError(t, err)
+		_, quote, err = broker.Position("BTCUSDT")
+		require.NoError(t, err)
+		require.Equal(t, 300.0, quote)
+
+		// canceled orders release the budget, tracked without listing the stored orders
+		canceled, err := broker.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 2, 50)
+		require.NoError(t, err)
+		require.Len(t, broker.pending, 2)
+		require.NoError(t, broker.Cancel(canceled))
+		require.Len(t, broker.pending, 1)
+		_, quote, err = broker.Position("BTCUSDT")
+		require.NoError(t, err)
+		require.Equal(t, 300.0, quote)
+
+		_, err = broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 4)
+		require.ErrorIs(t, err, ErrBudgetExceeded)
+
+		// the account position is not affected by the strategy budget
+		asset, quote, err = controller.Position("BTCUSDT")
+		require.NoError(t, err)
+		require.Equal(t, 5.0, asset)
+		require.Equal(t, 9500.0, quote)
+	})
+
+	t.Run("results by strategy", func(t *testing.T) {
+		candle := model.Candle{Pair: "BTCUSDT", Close: 200, Complete: true}
+		wallet.OnCandle(candle)
+		controller.OnCandle(candle)
+
+		_, err := broker.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 5)
+		require.NoError(t, err)
+
+		require.Equal(t, []float64{500}, controller.StrategyResults["test"]["BTCUSDT"].WinLong)
+		require.Equal(t, []float64{500}, controller.Results["BTCUSDT"].WinLong)
+
+		asset, quote, err := broker.Position("BTCUSDT")
+		require.NoError(t, err)
+		require.Equal(t, 0.0, asset)
+		require.Equal(t, 1300.0, quote)
+	})
+}
@@ -19,7 +19,8 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-type summary struct {
+type Summary struct {
+	Strategy         string
 	Pair             string
 	WinLong          []float64
 	WinLongPercent   []float64
@@ -30,25 +31,26 @@ type summary struct {
 	LoseShort        []float64
 	LoseShortPercent []float64
 	Volume           float64
+	Trades           []Result
 }
 
-func (s summary) Win() []float64 {
+func (s Summary) Win() []float64 {
 	return append(s.WinLong, s.WinShort...)
 }
 
-func (s summary) WinPercent() []float64 {
+func (s Summary) WinPercent() []float64 {
 	return append(s.WinLongPercent, s.WinShortPercent...)
 }
 
-func (s summary) Lose() []float64 {
+func (s Summary) Lose() []float64 {
 	return append(s.LoseLong, s.LoseShort...)
 }
 
-func (s summary) LosePercent() []float64 {
+func (s Summary) LosePercent() []float64 {
 	return append(s.LoseLongPercent, s.LoseShortPercent...)
 }
 
-func (s summary) Profit() float64 {
+func (s Summary) Profit() float64 {
 	profit := 0.0
 	for _, value := range append(s.Win(), s.Lose()...) {
 		profit += value
@@ -56,7 +58,7 @@ func (s summary) Profit() float64 {
 	return profit
 }
 
-func (s summary) SQN() float64 {
+func (s Summary) SQN() float64 {
 	total := float64(len(s.Win()) + len(s.Lose()))
 	avgProfit := s.Profit() / total
 	stdDev := 0.0
@@ -67,7 +69,7 @@ func (s summary) SQN() float64 {
 	return math.Sqrt(total) * (s.Profit() / total) / stdDev
 }
 
-func (s summary) Payoff() float64 {
+func (s Summary) Payoff() float64 {
 	avgWin := 0.0
 	avgLose := 0.0
 
@@ -86,7 +88,7 @@ func (s summary) Payoff() float64 {
 	return (avgWin / float64(len(s.Win()))) / math.Abs(avgLose/float64(len(s.Lose())))
 }
 
-func (s summary) ProfitFactor() float64 {
+func (s Summary) ProfitFactor() float64 {
 	if len(s.Lose()) == 0 {
 		return 0
 	}
@@ -102,7 +104,7 @@ func (s summary) ProfitFactor() float64 {
 	return profit / math.Abs(loss)
 }
 
-func (s summary) WinPercentage() float64 {
+func (s Summary) WinPercentage() float64 {
 	if len(s.Win())+len(s.Lose()) == 0 {
 		return 0
 	}
@@ -110,7 +112,7 @@ func (s summary) WinPercentage() float64 {
 	return float64(len(s.Win())) / float64(len(s.Win())+len(s.Lose())) * 100
 }
 
-func (s summary) String() string {
+func (s Summary) String() string {
 	tableString := &strings.Builder{}
 	table := tablewriter.NewWriter(tableString)
 	_, quote := exchange.SplitAssetQuote(s.Pair)
@@ -131,7 +133,7 @@ func (s summary) String() string {
 	return tableString.String()
 }
 
-func (s summary) SaveReturns(filename string) error {
+func (s Summary) SaveReturns(filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -154,6 +156,28 @@ func (s summary) SaveReturns(filename string) error {
 	return nil
 }
 
+// add registers a trade result as a win or loss
+func (s *Summary) add(result *Result) {
+	s.Trades = append(s.Trades, *result)
+	if result.ProfitPercent >= 0 {
+		if result.Side == model.SideTypeBuy {
+			s.WinLong = append(s.WinLong, result.ProfitValue)
+			s.WinLongPercent = append(s.WinLongPercent, result.ProfitPercent)
+		} else {
+			s.WinShort = append(s.WinShort, result.ProfitValue)
+			s.WinShortPercent = append(s.WinShortPercent, result.ProfitPercent)
+		}
+	} else {
+		if result.Side == model.SideTypeBuy {
+			s.LoseLong = append(s.LoseLong, result.ProfitValue)
+			s.LoseLongPercent = append(s.LoseLongPercent, result.ProfitPercent)
+		} else {
+			s.LoseShort = append(s.LoseShort, result.ProfitValue)
+			s.LoseShortPercent = append(s.LoseShortPercent, result.ProfitPercent)
+		}
+	}
+}
+
 type Status string
 
 const (
@@ -163,6 +187,7 @@ const (
 )
 
 type Result struct {
+	Strategy      string
 	Pair          string
 	ProfitPercent float64
 	ProfitValue   float64
@@ -179,8 +204,10 @@ type Position struct {
 }
 
 func (p *Position) Update(order *model.Order) (result *Result, finished bool) {
+	// filled orders report the average execution price, stop price is used as fallback
 	price := order.Price
-	if order.Type == model.OrderTypeStopLoss || order.Type == model.OrderTypeStopLossLimit {
+	if price == 0 && order.Stop != nil &&
+		(order.Type == model.OrderTypeStopLoss || order.Type == model.OrderTypeStopLossLimit) {
 		price = *order.Stop
 	}
 
@@ -204,6 +231,7 @@ func (p *Position) Update(order *model.Order) (result *Result, finished bool) {
 		order.ProfitValue = (price - p.AvgPrice) * quantity
 
 		result = &Result{
+			Strategy:      order.Strategy,
 			CreatedAt:     order.CreatedAt,
 			Pair:          order.Pair,
 			Duration:      order.CreatedAt.Sub(p.CreatedAt),
@@ -225,75 +253,195 @@ type Controller struct {
 	storage        storage.Storage
 	orderFeed      *Feed
 	notifier       service.Notifier
-	Results        map[string]*summary
+	Results        map[string]*Summary
 	lastPrice      map[string]float64
 	tickerInterval time.Duration
-	finish         chan bool
-	status         Status
+	// polling interval when the exchange streams the order updates
+	reconcileInterval time.Duration
+	finish            chan bool
+	status            Status
+	// order updates processed synchronously on each candle, see SetBacktest
+	backtest bool
+
+	// results and brokers of each strategy, indexed by strategy name
+	StrategyResults map[string]map[string]*Summary
+	brokers         map[string]*StrategyBroker
 
 	position map[string]*Position
+
+	// open bracket orders, indexed by bracket ID
+	brackets map[int64]*model.Bracket
+
+	// active trailing stops, indexed by trailing stop ID
+	trailingStops map[int64]*model.TrailingStop
+
+	// equity snapshots, registered in the storage at most once per interval
+	equityInterval time.Duration
+	lastEquity     time.Time
 }
 
 func NewController(ctx context.Context, exchange service.Exchange, storage storage.Storage,
 	orderFeed *Feed) *Controller {
 
-	return &Controller{
-		ctx:            ctx,
-		storage:        storage,
-		exchange:       exchange,
-		orderFeed:      orderFeed,
-		lastPrice:      make(map[string]float64),
-		Results:        make(map[string]*summary),
-		tickerInterval: time.Second,
-		finish:         make(chan bool),
-		position:       make(map[string]*Position),
+	controller := &Controller{
+		ctx:               ctx,
+		exchange:          exchange,
+		orderFeed:         orderFeed,
+		lastPrice:         make(map[string]float64),
+		Results:           make(map[string]*Summary),
+		tickerInterval:    time.Second,
+		reconcileInterval: time.Minute,
+		finish:            make(chan bool),
+		position:          make(map[string]*Position),
+		brackets:          make(map[int64]*model.Bracket),
+		trailingStops:     make(map[int64]*model.TrailingStop),
+		equityInterval:    time.Hour,
+
+		StrategyResults: make(map[string]map[string]*Summary),
+		brokers:         make(map[string]*StrategyBroker),
 	}
+	controller.storage = orderStorage{Storage: storage, controller: controller}
+	return controller
+}
+
+// positionKey identifies the position of a strategy in a pair.
+// Orders created without a strategy are identified only by the pair.
+func positionKey(strategy, pair string) string {
+	if strategy == "" {
+		return pair
+	}
+	return strategy + "--" + pair
 }
 
 func (c *Controller) SetNotifier(notifier service.Notifier) {
 	c.notifier = notifier
 }
 
+// SetEquityInterval defines the minimum interval between equity snapshots, zero disables the snapshots
+func (c *Controller) SetEquityInterval(interval time.Duration) {
+	c.equityInterval = interval
+}
+
+// SetBacktest processes the order updates synchronously on each candle, instead of polling or streaming
+// them in background, so the fills of backtests are deterministic
+func (c *Controller) SetBacktest(backtest bool) {
+	c.backtest = backtest
+}
+
+// OnPartialCandle updates the last price and the trailing stops with the candles in progress
+func (c *Controller) OnPartialCandle(candle model.Candle) {
+	c.lastPrice[candle.Pair] = candle.Close
+	if c.backtest {
+		c.pullOrders()
+	}
+	c.triggerBrackets(candle)
+	c.trailStops(candle)
+}
+
 func (c *Controller) OnCandle(candle model.Candle) {
 	c.lastPrice[candle.Pair] = candle.Close
+	if c.backtest {
+		c.pullOrders()
+	}
+	c.triggerBrackets(candle)
+	c.trailStops(candle)
+
+	if c.equityInterval > 0 && candle.Complete && !candle.Time.Before(c.lastEquity.Add(c.equityInterval)) {
+		c.lastEquity = candle.Time
+		c.saveEquity(candle.Time)
+	}
+}
+
+// saveEquity registers the account equity of each quote asset, valued with the last price of the pairs
+func (c *Controller) saveEquity(t time.Time) {
+	account, err := c.exchange.Account()
+	if err != nil {
+		c.notifyError(err)
+		return
+	}
+
+	equity := make(map[string]float64)
+	for pair, price := range c.lastPrice {
+		asset, quote := exchange.SplitAssetQuote(pair)
+		assetBalance, quoteBalance := account.Balance(asset, quote)
+		if _, ok := equity[quote]; !ok {
+			equity[quote] = quoteBalance.Free + quoteBalance.Lock
+		}
+		equity[quote] += (assetBalance.Free + assetBalance.Lock) * price
+	}
+
+	for quote, value := range equity {
+		err := c.storage.CreateEquitySnapshot(&model.EquitySnapshot{
+			Asset: quote,
+			Value: value,
+			Time:  t,
+		})
+		if err != nil {
+			c.notifyError(err)
+		}
+	}
+}
+
+// savePosition persists the position of a strategy in a pair, closed positions are removed from the storage
+func (c *Controller) savePosition(o *model.Order, position *Position) {
+	var err error
+	if position == nil {
+		err = c.storage.DeletePosition(o.Strategy, o.Pair)
+	} else {
+		err = c.storage.SavePosition(&model.Position{
+			Strategy:  o.Strategy,
+			Pair:      o.Pair,
+			Side:      position.Side,
+			AvgPrice:  position.AvgPrice,
+			Quantity:  position.Quantity,
+			CreatedAt: position.CreatedAt,
+			UpdatedAt: o.UpdatedAt,
+		})
+	}
+
+	if err != nil {
+		c.notifyError(err)
+	}
 }
 
 func (c *Controller) updatePosition(o *model.Order) {
 	// get filled orders before the current order
-	position, ok := c.position[o.Pair]
+	key := positionKey(o.Strategy, o.Pair)
+	position, ok := c.position[key]
 	if !ok {
-		c.position[o.Pair] = &Position{
+		c.position[key] = &Position{
 			AvgPrice:  o.Price,
 			Quantity:  o.Quantity,
 			CreatedAt: o.CreatedAt,
 			Side:      o.Side,
 		}
+		c.savePosition(o, c.position[key])
 		return
 	}
 
 	result, closed := position.Update(o)
 	if closed {
-		delete(c.position, o.Pair)
+		delete(c.position, key)
+		c.savePosition(o, nil)
+	} else {
+		c.savePosition(o, position)
 	}
 
 	if result != nil {
-		// TODO: replace by a slice of Result
-		if result.ProfitPercent >= 0 {
-			if result.Side == model.SideTypeBuy {
-				c.Results[o.Pair].WinLong = append(c.Results[o.Pair].WinLong, result.ProfitValue)
-				c.Results[o.Pair].WinLongPercent = append(c.Results[o.Pair].WinLongPercent, result.ProfitPercent)
-			} else {
-				c.Results[o.Pair].WinShort = append(c.Results[o.Pair].WinShort, result.ProfitValue)
-				c.Results[o.Pair].WinShortPercent = append(c.Results[o.Pair].WinShortPercent, result.ProfitPercent)
-			}
-		} else {
-			if result.Side == model.SideTypeBuy {
-				c.Results[o.Pair].LoseLong = append(c.Results[o.Pair].LoseLong, result.ProfitValue)
-				c.Results[o.Pair].LoseLongPercent = append(c.Results[o.Pair].LoseLongPercent, result.ProfitPercent)
-			} else {
-				c.Results[o.Pair].LoseShort = append(c.Results[o.Pair].LoseShort, result.ProfitValue)
-				c.Results[o.Pair].LoseShortPercent = append(c.Results[o.Pair].LoseShortPercent, result.ProfitPercent)
-			}
+		c.Results[o.Pair].add(result)
+		c.StrategyResults[o.Strategy][o.Pair].add(result)
+
+		err := c.storage.CreateTrade(&model.Trade{
+			Strategy:      result.Strategy,
+			Pair:          result.Pair,
+			Side:          result.Side,
+			ProfitPercent: result.ProfitPercent,
+			ProfitValue:   result.ProfitValue,
+			Duration:      result.Duration,
+			CreatedAt:     result.CreatedAt,
+		})
+		if err != nil {
+			c.notifyError(err)
 		}
 
 		_, quote := exchange.SplitAssetQuote(o.Pair)
@@ -321,23 +469,71 @@ func (c *Controller) notifyError(err error) {
 	}
 }
 
+// initResults initializes the results of a pair and its strategy, if needed
+func (c *Controller) initResults(strategy, pair string) {
+	if _, ok := c.Results[pair]; !ok {
+		c.Results[pair] = &Summary{Pair: pair}
+	}
+
+	if _, ok := c.StrategyResults[strategy]; !ok {
+		c.StrategyResults[strategy] = make(map[string]*Summary)
+	}
+
+	if _, ok := c.StrategyResults[strategy][pair]; !ok {
+		c.StrategyResults[strategy][pair] = &Summary{Strategy: strategy, Pair: pair}
+	}
+}
+
 func (c *Controller) processTrade(order *model.Order) {
 	if order.Status != model.OrderStatusTypeFilled {
+		c.updateBrackets(order)
+		c.updateTrailingStops(order)
 		return
 	}
 
-	// initializer results map if needed
-	if _, ok := c.Results[order.Pair]; !ok {
-		c.Results[order.Pair] = &summary{Pair: order.Pair}
-	}
-
 	// register order volume
+	c.initResults(order.Strategy, order.Pair)
 	c.Results[order.Pair].Volume += order.Price * order.Quantity
+	c.StrategyResults[order.Strategy][order.Pair].Volume += order.Price * order.Quantity
 
 	// update position size / avg price
 	c.updatePosition(order)
+
+	// update virtual balance of the strategy
+	if broker, ok := c.brokers[order.Strategy]; ok {
+		broker.onTrade(*order)
+	}
+
+	// submit or cancel the orders of brackets and trailing stops
+	c.updateBrackets(order)
+	c.updateTrailingStops(order)
 }
 
+// updateOrder registers the new state of a stored order, returns false if the order was not changed
+func (c *Controller) updateOrder(order *model.Order, excOrder *model.Order) bool {
+	// no status change
+	if excOrder.Status == order.Status {
+		return false
+	}
+
+	excOrder.ID = order.ID
+	excOrder.Strategy = order.Strategy
+	if excOrder.CreatedAt.IsZero() {
+		excOrder.CreatedAt = order.CreatedAt
+	}
+
+	err := c.storage.UpdateOrder(excOrder)
+	if err != nil {
+		c.notifyError(err)
+		return false
+	}
+
+	log.Infof("[ORDER %s] %s", excOrder.Status, excOrder)
+	return true
+}
+
+// updateOrders checks the status of pending orders in the exchange. With an order stream, it is only used to
+// reconcile updates that may have been lost.
 func (c *Controller) updateOrders() {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
@@ -350,7 +546,6 @@ func (c *Controller) updateOrders() {
 	))
 	if err != nil {
 		c.notifyError(err)
-		c.mtx.Unlock()
 		return
 	}
 
@@ -363,26 +558,188 @@ func (c *Controller) updateOrders() {
 			continue
 		}
 
-		// no status change
-		if excOrder.Status == order.Status {
+		if c.updateOrder(order, &excOrder) {
+			updatedOrders = append(updatedOrders, excOrder)
+		}
+	}
+
+	for _, processOrder := range updatedOrders {
+		c.processTrade(&processOrder)
+		c.orderFeed.Publish(processOrder, false)
+	}
+}
+
+// pullOrders processes the order updates queued by the exchange, or polls the pending orders without a queue
+func (c *Controller) pullOrders() {
+	puller, ok := c.exchange.(service.OrderPuller)
+	if !ok {
+		c.updateOrders()
+		return
+	}
+
+	for _, update := range puller.OrderUpdates() {
+		c.onOrderUpdate(update)
+	}
+}
+
+// onOrderUpdate processes an order update pushed by the exchange stream
+func (c *Controller) onOrderUpdate(update model.Order) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	orders, err := c.storage.Orders(storage.WithExchangeID(update.ExchangeID), storage.WithPair(update.Pair))
+	if err != nil {
+		c.notifyError(err)
+		return
+	}
+
+	// orders created outside the bot, or not registered yet, are ignored
+	if len(orders) == 0 {
+		if update.Type == model.OrderTypeLiquidation && update.Status == model.OrderStatusTypeFilled {
+			c.onLiquidation(update)
+		}
+		return
+	}
+
+	// final states or updates older than the stored state, e.g. already reconciled by polling
+	order := orders[0]
+	if !isPending(order.Status) || update.UpdatedAt.Before(order.UpdatedAt) {
+		return
+	}
+
+	if c.updateOrder(order, &update) {
+		c.processTrade(&update)
+		c.orderFeed.Publish(update, false)
+	}
+}
+
+// onLiquidation registers a liquidation of the exchange, closing the positions of the strategies in the pair
+func (c *Controller) onLiquidation(update model.Order) {
+	for key, position := range c.position {
+		strategy, ok := strings.CutSuffix(key, "--"+update.Pair)
+		if key == update.Pair {
+			strategy, ok = "", true
+		}
+
+		if !ok || position.Side == update.Side {
 			continue
 		}
 
-		excOrder.ID = order.ID
-		err = c.storage.UpdateOrder(&excOrder)
+		order := update
+		order.Strategy = strategy
+		order.Quantity = math.Min(position.Quantity, update.Quantity)
+		order.ExecutedQuantity = order.Quantity
+		err := c.storage.CreateOrder(&order)
 		if err != nil {
 			c.notifyError(err)
 			continue
 		}
 
-		log.Infof("[ORDER %s] %s", excOrder.Status, excOrder)
-		updatedOrders = append(updatedOrders, excOrder)
+		c.notify(fmt.Sprintf("[LIQUIDATION] %s %s | Quantity: %f, Price: %f", order.Side, order.Pair,
+			order.Quantity, order.Price))
+		c.processTrade(&order)
+		c.orderFeed.Publish(order, false)
 	}
+}
 
-	for _, processOrder := range updatedOrders {
-		c.processTrade(&processOrder)
-		c.orderFeed.Publish(processOrder, false)
+func isPending(status model.OrderStatusType) bool {
+	return status == model.OrderStatusTypeNew ||
+		status == model.OrderStatusTypePartiallyFilled ||
+		status == model.OrderStatusTypePendingCancel
+}
+
+// Restore loads the open positions, closed trades, open brackets, active trailing stops and the time of the
+// last equity snapshot from the storage, and replays the filled orders to recover the volume and the budget
+// of the strategies.
+// It must be called before starting the controller.
+func (c *Controller) Restore() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	positions, err := c.storage.Positions()
+	if err != nil {
+		return err
+	}
+
+	for _, position := range positions {
+		c.position[positionKey(position.Strategy, position.Pair)] = &Position{
+			Side:      position.Side,
+			AvgPrice:  position.AvgPrice,
+			Quantity:  position.Quantity,
+			CreatedAt: position.CreatedAt,
+		}
+	}
+
+	trades, err := c.storage.Trades()
+	if err != nil {
+		return err
+	}
+
+	for _, trade := range trades {
+		c.initResults(trade.Strategy, trade.Pair)
+		result := &Result{
+			Strategy:      trade.Strategy,
+			Pair:          trade.Pair,
+			ProfitPercent: trade.ProfitPercent,
+			ProfitValue:   trade.ProfitValue,
+			Side:          trade.Side,
+			Duration:      trade.Duration,
+			CreatedAt:     trade.CreatedAt,
+		}
+		c.Results[trade.Pair].add(result)
+		c.StrategyResults[trade.Strategy][trade.Pair].add(result)
+	}
+
+	orders, err := c.storage.Orders(storage.WithStatus(model.OrderStatusTypeFilled))
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		c.initResults(order.Strategy, order.Pair)
+		c.Results[order.Pair].Volume += order.Price * order.Quantity
+		c.StrategyResults[order.Strategy][order.Pair].Volume += order.Price * order.Quantity
+
+		if broker, ok := c.brokers[order.Strategy]; ok {
+			broker.onTrade(*order)
+		}
+	}
+
+	brackets, err := c.storage.Brackets()
+	if err != nil {
+		return err
+	}
+
+	for _, bracket := range brackets {
+		if bracket.Open() {
+			c.brackets[bracket.ID] = bracket
+		}
 	}
+
+	stops, err := c.storage.TrailingStops()
+	if err != nil {
+		return err
+	}
+
+	for _, stop := range stops {
+		if stop.Status == model.TrailingStopStatusActive {
+			c.trailingStops[stop.ID] = stop
+		}
+	}
+
+	snapshots, err := c.storage.EquitySnapshots()
+	if err != nil {
+		return err
+	}
+
+	// the interval of the equity snapshots continues from the last execution
+	if len(snapshots) > 0 {
+		c.lastEquity = snapshots[len(snapshots)-1].Time
+	}
+
+	log.Infof("[SETUP] Restored %d positions, %d trades, %d brackets and %d trailing stops", len(positions),
+		len(trades), len(c.brackets), len(c.trailingStops))
+	return nil
 }
 
 func (c *Controller) Status() Status {
@@ -392,12 +749,49 @@ func (c *Controller) Status() Status {
 func (c *Controller) Start() {
 	if c.status != StatusRunning {
 		c.status = StatusRunning
+
+		// backtests process the order updates synchronously on each candle
+		if c.backtest {
+			if puller, ok := c.exchange.(service.OrderPuller); ok {
+				puller.OrderUpdates()
+			}
+			log.Info("Bot started.")
+			return
+		}
+
+		// with an order stream, polling is only used for reconciliation
+		interval := c.tickerInterval
+		ctx, cancel := context.WithCancel(c.ctx)
+		var (
+			orders chan model.Order
+			errs   chan error
+		)
+		if streamer, ok := c.exchange.(service.OrderStreamer); ok {
+			orders, errs = streamer.OrderSubscription(ctx)
+			interval = c.reconcileInterval
+		}
+
 		go func() {
-			ticker := time.NewTicker(c.tickerInterval)
+			ticker := time.NewTicker(interval)
+			defer cancel()
 			for {
 				select {
 				case <-ticker.C:
 					c.updateOrders()
+				case order, ok := <-orders:
+					if !ok {
+						// stream finished, fallback to polling
+						orders = nil
+						ticker.Reset(c.tickerInterval)
+						continue
+					}
+					c.onOrderUpdate(order)
+				case err, ok := <-errs:
+					if !ok {
+						errs = nil
+						continue
+					}
+					c.notifyError(err)
 				case <-c.finish:
 					ticker.Stop()
 					return
@@ -412,7 +806,9 @@ func (c *Controller) Stop() {
 	if c.status == StatusRunning {
 		c.status = StatusStopped
 		c.updateOrders()
-		c.finish <- true
+		if !c.backtest {
+			c.finish <- true
+		}
 		log.Info("Bot stopped.")
 	}
 }
@@ -441,7 +837,29 @@ func (c *Controller) Order(pair string, id int64) (model.Order, error) {
 	return c.exchange.Order(pair, id)
 }
 
+// PendingOrders returns the stored orders of a pair that are still open in the exchange
+func (c *Controller) PendingOrders(pair string) ([]model.Order, error) {
+	orders, err := c.storage.Orders(storage.WithPair(pair), storage.WithStatusIn(
+		model.OrderStatusTypeNew,
+		model.OrderStatusTypePartiallyFilled,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]model.Order, 0, len(orders))
+	for _, order := range orders {
+		pending = append(pending, *order)
+	}
+	return pending, nil
+}
+
 func (c *Controller) CreateOrderOCO(side model.SideType, pair string, size, price, stop,
+	stopLimit float64) ([]model.Order, error) {
+	return c.createOrderOCO("", side, pair, size, price, stop, stopLimit)
+}
+
+func (c *Controller) createOrderOCO(strategy string, side model.SideType, pair string, size, price, stop,
 	stopLimit float64) ([]model.Order, error) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
@@ -454,6 +872,7 @@ func (c *Controller) CreateOrderOCO(side model.SideType, pair string, size, pric
 	}
 
 	for i := range orders {
+		orders[i].Strategy = strategy
 		err := c.storage.CreateOrder(&orders[i])
 		if err != nil {
 			c.notifyError(err)
@@ -466,6 +885,11 @@ func (c *Controller) CreateOrderOCO(side model.SideType, pair string, size, pric
 }
 
 func (c *Controller) CreateOrderLimit(side model.SideType, pair string, size, limit float64) (model.Order, error) {
+	return c.createOrderLimit("", side, pair, size, limit)
+}
+
+func (c *Controller) createOrderLimit(strategy string, side model.SideType, pair string,
+	size, limit float64) (model.Order, error) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 
@@ -476,6 +900,7 @@ func (c *Controller) CreateOrderLimit(side model.SideType, pair string, size, li
 		return model.Order{}, err
 	}
 
+	order.Strategy = strategy
 	err = c.storage.CreateOrder(&order)
 	if err != nil {
 		c.notifyError(err)
@@ -487,6 +912,11 @@ func (c *Controller) CreateOrderLimit(side model.SideType, pair string, size, li
 }
 
 func (c *Controller) CreateOrderMarketQuote(side model.SideType, pair string, amount float64) (model.Order, error) {
+	return c.createOrderMarketQuote("", side, pair, amount)
+}
+
+func (c *Controller) createOrderMarketQuote(strategy string, side model.SideType, pair string,
+	amount float64) (model.Order, error) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 
@@ -497,6 +927,7 @@ func (c *Controller) CreateOrderMarketQuote(side model.SideType, pair string, am
 		return model.Order{}, err
 	}
 
+	order.Strategy = strategy
 	err = c.storage.CreateOrder(&order)
 	if err != nil {
 		c.notifyError(err)
@@ -511,6 +942,11 @@ func (c *Controller) CreateOrderMarketQuote(side model.SideType, pair string, am
 }
 
 func (c *Controller) CreateOrderMarket(side model.SideType, pair string, size float64) (model.Order, error) {
+	return c.createOrderMarket("", side, pair, size)
+}
+
+func (c *Controller) createOrderMarket(strategy string, side model.SideType, pair string,
+	size float64) (model.Order, error) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 
@@ -521,6 +957,7 @@ func (c *Controller) CreateOrderMarket(side model.SideType, pair string, size fl
 		return model.Order{}, err
 	}
 
+	order.Strategy = strategy
 	err = c.storage.CreateOrder(&order)
 	if err != nil {
 		c.notifyError(err)
@@ -535,6 +972,10 @@ func (c *Controller) CreateOrderMarket(side model.SideType, pair string, size fl
 }
 
 func (c *Controller) CreateOrderStop(pair string, size float64, limit float64) (model.Order, error) {
+	return c.createOrderStop("", pair, size, limit)
+}
+
+func (c *Controller) createOrderStop(strategy string, pair string, size float64, limit float64) (model.Order, error) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 
@@ -545,6 +986,7 @@ func (c *Controller) CreateOrderStop(pair string, size float64, limit float64) (
 		return model.Order{}, err
 	}
 
+	order.Strategy = strategy
 	err = c.storage.CreateOrder(&order)
 	if err != nil {
 		c.notifyError(err)
@@ -558,7 +1000,10 @@ func (c *Controller) CreateOrderStop(pair string, size float64, limit float64) (
 func (c *Controller) Cancel(order model.Order) error {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
+	return c.cancel(order)
+}
 
+func (c *Controller) cancel(order model.Order) error {
 	log.Infof("[ORDER] Cancelling order for %s", order.Pair)
 	err := c.exchange.Cancel(order)
 	if err != nil {
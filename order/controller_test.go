@@ -222,3 +222,167 @@ func TestController_Position(t *testing.T) {
 	assert.Equal(t, 1.0, asset)
 	assert.Equal(t, 1500.0, quote)
 }
+
+func TestController_Restore(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 3000))
+	controller := NewController(ctx, wallet, storage, NewOrderFeed())
+	broker := controller.StrategyBroker("ema", 2000)
+
+	candle := model.Candle{Pair: "BTCUSDT", Close: 1000, Time: time.Now(), Complete: true}
+	wallet.OnCandle(candle)
+	controller.OnCandle(candle)
+	_, err = broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 2)
+	require.NoError(t, err)
+
+	candle = model.Candle{Pair: "BTCUSDT", Close: 1500, Time: candle.Time.Add(time.Hour), Complete: true}
+	wallet.OnCandle(candle)
+	controller.OnCandle(candle)
+	_, err = broker.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	positions, err := storage.Positions()
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	require.Equal(t, "ema", positions[0].Strategy)
+	require.Equal(t, 1.0, positions[0].Quantity)
+
+	snapshots, err := storage.EquitySnapshots()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	require.Equal(t, "USDT", snapshots[0].Asset)
+	require.Equal(t, 3000.0, snapshots[0].Value)
+	require.Equal(t, 4000.0, snapshots[1].Value)
+
+	// a new controller recovers the state from the storage
+	restored := NewController(ctx, wallet, storage, NewOrderFeed())
+	restoredBroker := restored.StrategyBroker("ema", 2000)
+	require.NoError(t, restored.Restore())
+
+	position := restored.position["ema--BTCUSDT"]
+	require.NotNil(t, position)
+	require.Equal(t, model.SideTypeBuy, position.Side)
+	require.Equal(t, 1000.0, position.AvgPrice)
+	require.Equal(t, 1.0, position.Quantity)
+	require.True(t, controller.position["ema--BTCUSDT"].CreatedAt.Equal(position.CreatedAt))
+	require.Equal(t, controller.Results["BTCUSDT"].Profit(), restored.Results["BTCUSDT"].Profit())
+	require.Equal(t, controller.Results["BTCUSDT"].Volume, restored.Results["BTCUSDT"].Volume)
+	require.Len(t, restored.StrategyResults["ema"]["BTCUSDT"].Trades, 1)
+
+	asset, quote, err := restoredBroker.Position("BTCUSDT")
+	require.NoError(t, err)
+	require.Equal(t, 1.0, asset)
+	require.Equal(t, 1500.0, quote)
+
+	// closing the position removes it from the storage
+	restored.OnCandle(candle)
+	// the equity interval continues from the last snapshot
+	snapshots, err = storage.EquitySnapshots()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	_, err = restoredBroker.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 1)
+	require.NoError(t, err)
+	positions, err = storage.Positions()
+	require.NoError(t, err)
+	require.Empty(t, positions)
+
+	trades, err := storage.Trades()
+	require.NoError(t, err)
+	require.Len(t, trades, 2)
+}
+
+func TestController_OrderStream(t *testing.T) {
+	repo, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 3000))
+	controller := NewController(ctx, wallet, repo, NewOrderFeed())
+	controller.tickerInterval = time.Hour
+	controller.reconcileInterval = time.Hour
+
+	controller.Start()
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 1500, Close: 1500})
+	order, err := controller.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 1000)
+	require.NoError(t, err)
+
+	// fill is pushed by the wallet, without polling
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "BTCUSDT", Low: 1000, High: 1000, Close: 1000})
+	require.Eventually(t, func() bool {
+		orders, err := repo.Orders(storage.WithExchangeID(order.ExchangeID),
+			storage.WithStatus(model.OrderStatusTypeFilled))
+		return err == nil && len(orders) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	positions, err := repo.Positions()
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	require.Equal(t, 1000.0, positions[0].AvgPrice)
+
+	controller.Stop()
+}
+
+func TestController_Backtest(t *testing.T) {
+	repo, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 3000))
+	controller := NewController(ctx, wallet, repo, NewOrderFeed())
+	controller.SetBacktest(true)
+
+	controller.Start()
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 1500, Close: 1500})
+	order, err := controller.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 1000)
+	require.NoError(t, err)
+
+	// fill is processed synchronously with the candle
+	candle := model.Candle{Time: time.Now(), Pair: "BTCUSDT", Low: 1000, High: 1000, Close: 1000, Complete: true}
+	wallet.OnCandle(candle)
+	controller.OnCandle(candle)
+
+	orders, err := repo.Orders(storage.WithExchangeID(order.ExchangeID), storage.WithStatus(model.OrderStatusTypeFilled))
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+
+	positions, err := repo.Positions()
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	require.Equal(t, 1000.0, positions[0].AvgPrice)
+
+	controller.Stop()
+}
+
+func TestController_Liquidation(t *testing.T) {
+	repo, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 100),
+		exchange.WithPaperLeverage("BTCUSDT", 10, exchange.MarginTypeIsolated))
+	controller := NewController(ctx, wallet, repo, NewOrderFeed())
+
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 100, Low: 100, Close: 100})
+	_, err = controller.StrategyBroker("long", 0).CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 5)
+	require.NoError(t, err)
+
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 95, Low: 85, Close: 90})
+	orders, err := wallet.Orders("BTCUSDT", 1)
+	require.NoError(t, err)
+	require.Equal(t, model.OrderTypeLiquidation, orders[0].Type)
+
+	// liquidation orders are not created by the bot, the positions of the pair are closed
+	controller.onOrderUpdate(orders[0])
+	positions, err := repo.Positions()
+	require.NoError(t, err)
+	require.Empty(t, positions)
+
+	stored, err := repo.Orders(storage.WithStrategy("long"), storage.WithExchangeID(orders[0].ExchangeID))
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	require.Equal(t, 5.0, stored[0].Quantity)
+
+	trades, err := repo.Trades()
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	require.Less(t, trades[0].ProfitValue, 0.0)
+}
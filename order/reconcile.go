@@ -0,0 +1,260 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/storage"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+// reconcileOrdersLimit is the number of recent orders of each pair checked in the exchange
+const reconcileOrdersLimit = 500
+
+// Discrepancy is a difference between the local state and the exchange, found by the reconciliation
+type Discrepancy struct {
+	Pair    string
+	OrderID int64 // exchange ID of the order, zero for balance discrepancies
+	Message string
+}
+
+func (d Discrepancy) String() string {
+	if d.OrderID == 0 {
+		return fmt.Sprintf("%s: %s", d.Pair, d.Message)
+	}
+	return fmt.Sprintf("%s (order %d): %s", d.Pair, d.OrderID, d.Message)
+}
+
+// Reconcile compares the local state with the exchange, e.g. after a crash with orders in flight.
+// Pending orders have their status repaired (expired when unknown by the exchange), open orders unknown by the bot
+// (e.g. placed manually) are registered, and positions larger than the exchange balances are reduced to them.
+// Discrepancies are reported through the notifier.
+// It must be called after Restore and before starting the controller.
+func (c *Controller) Reconcile(pairs []string) ([]Discrepancy, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	discrepancies, err := c.reconcileOrders()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range pairs {
+		result, err := c.reconcileUnknownOrders(pair)
+		if err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, result...)
+
+		result, err = c.reconcilePositions(pair)
+		if err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, result...)
+	}
+
+	if len(discrepancies) > 0 {
+		messages := make([]string, 0, len(discrepancies))
+		for _, discrepancy := range discrepancies {
+			messages = append(messages, "- "+discrepancy.String())
+		}
+		c.notify(fmt.Sprintf("[RECONCILE] %d discrepancies found\n%s",
+			len(discrepancies), strings.Join(messages, "\n")))
+	}
+
+	return discrepancies, nil
+}
+
+// reconcileOrders updates the status of stored pending orders with the exchange
+func (c *Controller) reconcileOrders() ([]Discrepancy, error) {
+	orders, err := c.storage.Orders(storage.WithStatusIn(
+		model.OrderStatusTypeNew,
+		model.OrderStatusTypePartiallyFilled,
+		model.OrderStatusTypePendingCancel,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	discrepancies := make([]Discrepancy, 0)
+	for _, order := range orders {
+		excOrder, err := c.exchange.Order(order.Pair, order.ExchangeID)
+		if err != nil && !errors.Is(err, exchange.ErrOrderNotFound) {
+			discrepancies = append(discrepancies, Discrepancy{
+				Pair:    order.Pair,
+				OrderID: order.ExchangeID,
+				Message: fmt.Sprintf("order not checked in the exchange: %v", err),
+			})
+			continue
+		}
+
+		// orders unknown by the exchange will not be executed, e.g. rejected while the bot was offline
+		if err != nil {
+			excOrder = *order
+			excOrder.Status = model.OrderStatusTypeExpired
+			excOrder.UpdatedAt = time.Now()
+		}
+
+		status := order.Status
+		if c.updateOrder(order, &excOrder) {
+			discrepancies = append(discrepancies, Discrepancy{
+				Pair:    order.Pair,
+				OrderID: order.ExchangeID,
+				Message: fmt.Sprintf("status updated from %s to %s", status, excOrder.Status),
+			})
+			c.processTrade(&excOrder)
+			c.orderFeed.Publish(excOrder, false)
+		}
+	}
+
+	return discrepancies, nil
+}
+
+// reconcileUnknownOrders registers open orders of the exchange that are not in the storage
+func (c *Controller) reconcileUnknownOrders(pair string) ([]Discrepancy, error) {
+	lister, ok := c.exchange.(service.OrderLister)
+	if !ok {
+		return nil, nil
+	}
+
+	excOrders, err := lister.Orders(pair, reconcileOrdersLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := c.storage.Orders(storage.WithPair(pair))
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]bool)
+	for _, order := range orders {
+		known[order.ExchangeID] = true
+	}
+
+	discrepancies := make([]Discrepancy, 0)
+	for _, excOrder := range excOrders {
+		if known[excOrder.ExchangeID] || !isPending(excOrder.Status) {
+			continue
+		}
+
+		excOrder.ID = 0
+		if err := c.storage.CreateOrder(&excOrder); err != nil {
+			return nil, err
+		}
+
+		discrepancies = append(discrepancies, Discrepancy{
+			Pair:    pair,
+			OrderID: excOrder.ExchangeID,
+			Message: fmt.Sprintf("unknown %s order registered: %s", excOrder.Status, excOrder),
+		})
+	}
+
+	return discrepancies, nil
+}
+
+// reconcilePositions compares the positions of the pair with the exchange balance, and rebuilds them when the
+// balance is smaller, e.g. closed manually. Positions are removed when the balance is zero or in the opposite
+// direction, and scaled to the balance otherwise, keeping the average prices. Differences smaller than the
+// minimum quantity of the pair (e.g. dust of the quantity rounding) are ignored, and balances not traded by
+// the bot (e.g. held manually) are only logged, they are not attached to any strategy.
+func (c *Controller) reconcilePositions(pair string) ([]Discrepancy, error) {
+	balance, _, err := c.exchange.Position(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	var expected float64
+	keys := make([]string, 0)
+	for key, position := range c.position {
+		if key != pair && !strings.HasSuffix(key, "--"+pair) {
+			continue
+		}
+
+		keys = append(keys, key)
+		if position.Side == model.SideTypeBuy {
+			expected += position.Quantity
+		} else {
+			expected -= position.Quantity
+		}
+	}
+
+	info := c.exchange.AssetsInfo(pair)
+	tolerance := math.Max(math.Max(info.MinQuantity, info.StepSize),
+		1e-8*math.Max(math.Abs(balance), math.Abs(expected)))
+	if math.Abs(balance-expected) < tolerance {
+		return nil, nil
+	}
+
+	asset, _ := exchange.SplitAssetQuote(pair)
+	sameSide := math.Abs(balance) >= tolerance && math.Signbit(expected) == math.Signbit(balance)
+	if expected == 0 || (sameSide && math.Abs(balance) > math.Abs(expected)) {
+		log.Warnf("[RECONCILE] %s: balance of %f %s not traded by the bot, positions of %f %s kept", pair,
+			balance, asset, expected, asset)
+		return nil, nil
+	}
+
+	if sameSide {
+		scale := balance / expected
+		now := time.Now()
+		for _, key := range keys {
+			position := c.position[key]
+			position.Quantity *= scale
+			if err := c.storePosition(key, pair, position, now); err != nil {
+				return nil, err
+			}
+		}
+
+		return []Discrepancy{{
+			Pair: pair,
+			Message: fmt.Sprintf("positions of %f %s reduced to the balance of %f %s", expected, asset,
+				balance, asset),
+		}}, nil
+	}
+
+	for _, key := range keys {
+		if err := c.deletePosition(key, pair); err != nil {
+			return nil, err
+		}
+	}
+
+	if math.Abs(balance) >= tolerance {
+		log.Warnf("[RECONCILE] %s: balance of %f %s not traded by the bot", pair, balance, asset)
+	}
+
+	return []Discrepancy{{
+		Pair:    pair,
+		Message: fmt.Sprintf("positions of %f %s closed outside the bot, removed", expected, asset),
+	}}, nil
+}
+
+// strategyOfKey returns the strategy of a position key, see positionKey
+func strategyOfKey(key, pair string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(key, pair), "--")
+}
+
+func (c *Controller) deletePosition(key, pair string) error {
+	if err := c.storage.DeletePosition(strategyOfKey(key, pair), pair); err != nil {
+		return err
+	}
+	delete(c.position, key)
+	return nil
+}
+
+func (c *Controller) storePosition(key, pair string, position *Position, updatedAt time.Time) error {
+	return c.storage.SavePosition(&model.Position{
+		Strategy:  strategyOfKey(key, pair),
+		Pair:      pair,
+		Side:      position.Side,
+		AvgPrice:  position.AvgPrice,
+		Quantity:  position.Quantity,
+		CreatedAt: position.CreatedAt,
+		UpdatedAt: updatedAt,
+	})
+}
@@ -0,0 +1,137 @@
+package order
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/storage"
+)
+
+func TestController_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	repo, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 3000))
+	controller := NewController(ctx, wallet, repo, NewOrderFeed())
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 1500, Close: 1500})
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "ETHUSDT", High: 100, Close: 100})
+
+	// order filled while the bot was offline
+	limit, err := controller.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 1000)
+	require.NoError(t, err)
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "BTCUSDT", Low: 1000, High: 1000, Close: 1000})
+
+	// order placed manually in the exchange
+	manual, err := wallet.CreateOrderLimit(model.SideTypeBuy, "ETHUSDT", 1, 50)
+	require.NoError(t, err)
+
+	// position closed outside the bot
+	require.NoError(t, repo.SavePosition(&model.Position{
+		Strategy: "ema",
+		Pair:     "ETHUSDT",
+		Side:     model.SideTypeBuy,
+		Quantity: 2,
+	}))
+
+	restored := NewController(ctx, wallet, repo, NewOrderFeed())
+	require.NoError(t, restored.Restore())
+	discrepancies, err := restored.Reconcile([]string{"BTCUSDT", "ETHUSDT"})
+	require.NoError(t, err)
+	require.Len(t, discrepancies, 3)
+
+	require.Equal(t, limit.ExchangeID, discrepancies[0].OrderID)
+	require.Contains(t, discrepancies[0].Message, "NEW to FILLED")
+	orders, err := repo.Orders(storage.WithExchangeID(limit.ExchangeID))
+	require.NoError(t, err)
+	require.Equal(t, model.OrderStatusTypeFilled, orders[0].Status)
+	require.Equal(t, 1.0, restored.position["BTCUSDT"].Quantity)
+
+	require.Equal(t, manual.ExchangeID, discrepancies[1].OrderID)
+	orders, err = repo.Orders(storage.WithExchangeID(manual.ExchangeID))
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+
+	require.Equal(t, "ETHUSDT", discrepancies[2].Pair)
+	require.Nil(t, restored.position["ema--ETHUSDT"])
+	positions, err := repo.Positions()
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	require.Equal(t, "BTCUSDT", positions[0].Pair)
+
+	// state is consistent after the reconciliation
+	discrepancies, err = restored.Reconcile([]string{"BTCUSDT", "ETHUSDT"})
+	require.NoError(t, err)
+	require.Empty(t, discrepancies)
+}
+
+func TestController_ReconcileRepair(t *testing.T) {
+	ctx := context.Background()
+	repo, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 3000),
+		exchange.WithPaperAsset("BTC", 2.000000005), exchange.WithPaperAsset("ETH", 3),
+		exchange.WithPaperAsset("BNB", 2), exchange.WithPaperAsset("SOL", 4))
+	wallet.OnCandle(model.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 1000, Close: 1000})
+
+	// pending order unknown by the exchange
+	unknown := &model.Order{ExchangeID: 999, Pair: "BTCUSDT", Side: model.SideTypeBuy, Type: model.OrderTypeLimit,
+		Status: model.OrderStatusTypeNew, Price: 900, Quantity: 1}
+	require.NoError(t, repo.CreateOrder(unknown))
+
+	positions := []*model.Position{
+		// balance with dust of the quantity rounding
+		{Strategy: "ema", Pair: "BTCUSDT", Side: model.SideTypeBuy, AvgPrice: 800, Quantity: 2},
+		// position partially closed outside the bot
+		{Strategy: "ema", Pair: "ETHUSDT", Side: model.SideTypeBuy, AvgPrice: 120, Quantity: 6},
+		// balance held manually, besides the position of the bot
+		{Strategy: "ema", Pair: "BNBUSDT", Side: model.SideTypeBuy, AvgPrice: 300, Quantity: 1},
+		// position reversed outside the bot
+		{Strategy: "ema", Pair: "SOLUSDT", Side: model.SideTypeSell, AvgPrice: 20, Quantity: 1},
+	}
+	for _, position := range positions {
+		require.NoError(t, repo.SavePosition(position))
+	}
+
+	pairs := []string{"BTCUSDT", "ETHUSDT", "BNBUSDT", "SOLUSDT"}
+	controller := NewController(ctx, wallet, repo, NewOrderFeed())
+	require.NoError(t, controller.Restore())
+	discrepancies, err := controller.Reconcile(pairs)
+	require.NoError(t, err)
+	require.Len(t, discrepancies, 3)
+
+	require.Equal(t, unknown.ExchangeID, discrepancies[0].OrderID)
+	require.Contains(t, discrepancies[0].Message, "NEW to EXPIRED")
+	require.Equal(t, model.OrderStatusTypeExpired, storedOrder(t, repo, unknown.ID).Status)
+
+	require.Equal(t, 2.0, controller.position["ema--BTCUSDT"].Quantity)
+
+	require.Equal(t, "ETHUSDT", discrepancies[1].Pair)
+	require.Equal(t, 3.0, controller.position["ema--ETHUSDT"].Quantity)
+	require.Equal(t, 120.0, controller.position["ema--ETHUSDT"].AvgPrice)
+
+	// untracked balances are not attached to the default strategy
+	require.Equal(t, 1.0, controller.position["ema--BNBUSDT"].Quantity)
+	require.Nil(t, controller.position["BNBUSDT"])
+
+	require.Equal(t, "SOLUSDT", discrepancies[2].Pair)
+	require.Nil(t, controller.position["ema--SOLUSDT"])
+	require.Nil(t, controller.position["SOLUSDT"])
+
+	// positions are persisted
+	restored := NewController(ctx, wallet, repo, NewOrderFeed())
+	require.NoError(t, restored.Restore())
+	discrepancies, err = restored.Reconcile(pairs)
+	require.NoError(t, err)
+	require.Empty(t, discrepancies)
+
+	stored, err := repo.Positions()
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+}
@@ -0,0 +1,216 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+var ErrTrailingStopNotFound = errors.New("trailing stop not found")
+
+// CreateTrailingStop creates a trailing stop, an exit order that follows the best price at a given distance.
+// The side is the side of the exit, e.g. sell to protect a long position. When the exchange supports trailing
+// stop orders (e.g. PaperWallet), the order is placed in the exchange. Otherwise, the stop is updated by the
+// controller with the prices of the candles, including partial candles, and a market order is executed when
+// the price reaches the stop.
+func (c *Controller) CreateTrailingStop(side model.SideType, pair string, size,
+	distance float64) (model.TrailingStop, error) {
+	return c.createTrailingStop("", side, pair, size, distance)
+}
+
+func (c *Controller) createTrailingStop(strategy string, side model.SideType, pair string, size,
+	distance float64) (model.TrailingStop, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if size <= 0 || distance <= 0 {
+		return model.TrailingStop{}, &exchange.OrderError{
+			Err:      exchange.ErrInvalidQuantity,
+			Pair:     pair,
+			Quantity: size,
+		}
+	}
+
+	price, ok := c.lastPrice[pair]
+	if !ok {
+		var err error
+		price, err = c.exchange.LastQuote(c.ctx, pair)
+		if err != nil {
+			return model.TrailingStop{}, err
+		}
+	}
+
+	log.Infof("[ORDER] Creating TRAILING STOP %s order for %s", side, pair)
+	stop := &model.TrailingStop{
+		Strategy: strategy,
+		Pair:     pair,
+		Side:     side,
+		Quantity: size,
+		Distance: distance,
+		Price:    price,
+		Stop:     price - distance,
+		Status:   model.TrailingStopStatusActive,
+	}
+	if side == model.SideTypeBuy {
+		stop.Stop = price + distance
+	}
+
+	if trailer, ok := c.exchange.(service.TrailingStopper); ok {
+		order, err := trailer.CreateOrderTrailingStop(side, pair, size, distance)
+		if err != nil {
+			c.notifyError(err)
+			return model.TrailingStop{}, err
+		}
+
+		order.Strategy = strategy
+		err = c.storage.CreateOrder(&order)
+		if err != nil {
+			c.notifyError(err)
+			return model.TrailingStop{}, err
+		}
+
+		stop.Native = true
+		stop.OrderID = order.ID
+		stop.CreatedAt = order.CreatedAt
+		stop.UpdatedAt = order.UpdatedAt
+		if order.Stop != nil {
+			stop.Stop = *order.Stop
+		}
+		go c.orderFeed.Publish(order, true)
+		log.Infof("[ORDER CREATED] %s", order)
+	}
+
+	err := c.storage.SaveTrailingStop(stop)
+	if err != nil {
+		c.notifyError(err)
+		return model.TrailingStop{}, err
+	}
+
+	c.trailingStops[stop.ID] = stop
+	return *stop, nil
+}
+
+// CancelTrailingStop cancels an active trailing stop, and its order when placed in the exchange
+func (c *Controller) CancelTrailingStop(stop model.TrailingStop) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	active, ok := c.trailingStops[stop.ID]
+	if !ok {
+		return ErrTrailingStopNotFound
+	}
+
+	if active.Native {
+		if order := c.pendingOrder(active.OrderID); order != nil {
+			if err := c.cancel(*order); err != nil {
+				return err
+			}
+		}
+	}
+
+	c.saveTrailingStop(active, model.TrailingStopStatusCanceled)
+	return nil
+}
+
+// TrailingStops returns the active trailing stops
+func (c *Controller) TrailingStops() []model.TrailingStop {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	stops := make([]model.TrailingStop, 0, len(c.trailingStops))
+	for _, stop := range c.trailingStops {
+		stops = append(stops, *stop)
+	}
+	return stops
+}
+
+// saveTrailingStop persists the trailing stop state, trailing stops are released from memory when finished
+func (c *Controller) saveTrailingStop(stop *model.TrailingStop, status model.TrailingStopStatus) {
+	stop.Status = status
+	if status != model.TrailingStopStatusActive {
+		delete(c.trailingStops, stop.ID)
+		log.Infof("[TRAILING STOP %s] %s %s | ID: %d, Stop: %f", stop.Status, stop.Side, stop.Pair, stop.ID,
+			stop.Stop)
+	}
+
+	if err := c.storage.SaveTrailingStop(stop); err != nil {
+		c.notifyError(err)
+	}
+}
+
+// trailStops moves the trailing stops managed by the controller with the candle price,
+// and executes the exit orders of the triggered stops
+func (c *Controller) trailStops(candle model.Candle) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, stop := range c.trailingStops {
+		if stop.Pair != candle.Pair || stop.Native {
+			continue
+		}
+
+		moved, triggered := stop.Update(candle.Close)
+		if moved {
+			stop.UpdatedAt = candle.UpdatedAt
+			if stop.UpdatedAt.IsZero() {
+				stop.UpdatedAt = candle.Time
+			}
+		}
+
+		if !triggered {
+			if moved {
+				c.saveTrailingStop(stop, model.TrailingStopStatusActive)
+			}
+			continue
+		}
+
+		log.Infof("[TRAILING STOP] Stop triggered for %s at %f", stop.Pair, candle.Close)
+		order, err := c.exchange.CreateOrderMarket(stop.Side, stop.Pair, stop.Quantity)
+		if err != nil {
+			c.notifyError(fmt.Errorf("trailing stop %d: %w", stop.ID, err))
+			continue
+		}
+
+		order.Strategy = stop.Strategy
+		err = c.storage.CreateOrder(&order)
+		if err != nil {
+			c.notifyError(err)
+			continue
+		}
+
+		stop.OrderID = order.ID
+		c.saveTrailingStop(stop, model.TrailingStopStatusTriggered)
+		c.processTrade(&order)
+		go c.orderFeed.Publish(order, true)
+		log.Infof("[ORDER CREATED] %s", order)
+	}
+}
+
+// updateTrailingStops finishes the trailing stops placed in the exchange with a new state of their orders
+func (c *Controller) updateTrailingStops(order *model.Order) {
+	if order.ID == 0 {
+		return
+	}
+
+	for _, stop := range c.trailingStops {
+		if !stop.Native || stop.OrderID != order.ID {
+			continue
+		}
+
+		if order.Stop != nil {
+			stop.Stop = *order.Stop
+		}
+		stop.UpdatedAt = order.UpdatedAt
+
+		switch order.Status {
+		case model.OrderStatusTypeFilled:
+			c.saveTrailingStop(stop, model.TrailingStopStatusTriggered)
+		case model.OrderStatusTypeCanceled, model.OrderStatusTypeRejected, model.OrderStatusTypeExpired:
+			c.saveTrailingStop(stop, model.TrailingStopStatusCanceled)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package order
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/storage"
+)
+
+// exchange without trailing stop orders, only with the methods of the interface
+type basicExchange struct {
+	service.Exchange
+}
+
+func TestController_TrailingStop(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func(native bool) (*exchange.PaperWallet, *Controller, storage.Storage) {
+		repo, err := storage.FromMemory()
+		require.NoError(t, err)
+		wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 1000))
+		var controller *Controller
+		if native {
+			controller = NewController(ctx, wallet, repo, NewOrderFeed())
+		} else {
+			controller = NewController(ctx, basicExchange{wallet}, repo, NewOrderFeed())
+		}
+
+		candle := model.Candle{Pair: "BTCUSDT", Time: start, High: 100, Low: 100, Close: 100, Complete: true}
+		wallet.OnCandle(candle)
+		controller.OnCandle(candle)
+
+		_, err = controller.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+		return wallet, controller, repo
+	}
+
+	t.Run("managed by the controller", func(t *testing.T) {
+		wallet, controller, repo := setup(false)
+		stop, err := controller.CreateTrailingStop(model.SideTypeSell, "BTCUSDT", 1, 5)
+		require.NoError(t, err)
+		require.False(t, stop.Native)
+		require.Equal(t, 95.0, stop.Stop)
+
+		// partial candles move the stop
+		candle := model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), High: 110, Low: 100, Close: 110}
+		wallet.OnCandle(candle)
+		controller.OnPartialCandle(candle)
+
+		stored, err := repo.TrailingStops()
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		require.Equal(t, 105.0, stored[0].Stop)
+		require.Equal(t, 110.0, stored[0].Price)
+
+		// restored after restart
+		restored := NewController(ctx, basicExchange{wallet}, repo, NewOrderFeed())
+		require.NoError(t, restored.Restore())
+		require.Len(t, restored.TrailingStops(), 1)
+		require.Equal(t, 105.0, restored.TrailingStops()[0].Stop)
+
+		candle = model.Candle{Pair: "BTCUSDT", Time: start.Add(2 * time.Hour), High: 110, Low: 104, Close: 104}
+		wallet.OnCandle(candle)
+		restored.OnPartialCandle(candle)
+
+		require.Empty(t, restored.TrailingStops())
+		stored, err = repo.TrailingStops()
+		require.NoError(t, err)
+		require.Equal(t, model.TrailingStopStatusTriggered, stored[0].Status)
+
+		orders, err := repo.Orders(storage.WithID(stored[0].OrderID))
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		require.Equal(t, model.OrderTypeMarket, orders[0].Type)
+		require.Equal(t, model.SideTypeSell, orders[0].Side)
+		require.Equal(t, model.OrderStatusTypeFilled, orders[0].Status)
+
+		asset, _, err := wallet.Position("BTCUSDT")
+		require.NoError(t, err)
+		require.Zero(t, asset)
+	})
+
+	t.Run("placed in the exchange", func(t *testing.T) {
+		wallet, controller, repo := setup(true)
+		stop, err := controller.CreateTrailingStop(model.SideTypeSell, "BTCUSDT", 1, 5)
+		require.NoError(t, err)
+		require.True(t, stop.Native)
+		require.NotZero(t, stop.OrderID)
+
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), High: 110, Low: 100, Close: 108})
+		wallet.OnCandle(model.Candle{Pair: "BTCUSDT", Time: start.Add(2 * time.Hour), High: 108, Low: 104,
+			Close: 104})
+		controller.updateOrders()
+
+		require.Empty(t, controller.TrailingStops())
+		stored, err := repo.TrailingStops()
+		require.NoError(t, err)
+		require.Equal(t, model.TrailingStopStatusTriggered, stored[0].Status)
+		require.Equal(t, 105.0, stored[0].Stop)
+		require.InDelta(t, 5.0, controller.Results["BTCUSDT"].Profit(), 1e-9)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		wallet, controller, repo := setup(true)
+		stop, err := controller.CreateTrailingStop(model.SideTypeSell, "BTCUSDT", 1, 5)
+		require.NoError(t, err)
+
+		require.NoError(t, controller.CancelTrailingStop(stop))
+		require.ErrorIs(t, controller.CancelTrailingStop(stop), ErrTrailingStopNotFound)
+		require.Empty(t, controller.TrailingStops())
+
+		order, err := repo.Orders(storage.WithID(stop.OrderID))
+		require.NoError(t, err)
+		excOrder, err := wallet.Order("BTCUSDT", order[0].ExchangeID)
+		require.NoError(t, err)
+		require.Equal(t, model.OrderStatusTypeCanceled, excOrder.Status)
+	})
+}
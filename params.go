@@ -0,0 +1,165 @@
+package ninjabot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+func (n *NinjaBot) allocation(name string) (StrategyAllocation, error) {
+	for _, allocation := range n.strategies {
+		if allocation.Name == name {
+			return allocation, nil
+		}
+	}
+	return StrategyAllocation{}, fmt.Errorf("%w: %s", strategy.ErrStrategyNotFound, name)
+}
+
+// StrategyParams returns the current values of the parameters of a strategy, see strategy.ParametrizedStrategy.
+// The strategy of NewBot has an empty name.
+func (n *NinjaBot) StrategyParams(name string) (map[string]any, error) {
+	n.paramsMtx.Lock()
+	defer n.paramsMtx.Unlock()
+
+	allocation, err := n.allocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	parametrized, ok := allocation.Strategy.(strategy.ParametrizedStrategy)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", strategy.ErrNotTunable, name)
+	}
+	return parametrized.ParamValues(), nil
+}
+
+// SetStrategyParams changes some parameters of a running strategy, see strategy.TunableStrategy.
+// The parameters not informed keep their current values. The change is applied atomically between candles
+// of all pairs of the strategy, and persisted in the storage to be restored on restart.
+func (n *NinjaBot) SetStrategyParams(name string, params map[string]any) (map[string]any, error) {
+	n.paramsMtx.Lock()
+	defer n.paramsMtx.Unlock()
+
+	allocation, err := n.allocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tunable, ok := allocation.Strategy.(strategy.TunableStrategy)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", strategy.ErrNotTunable, name)
+	}
+
+	current := tunable.ParamValues()
+	values := make(strategy.Params, len(current)+len(params))
+	for param, value := range current {
+		values[param] = value
+	}
+	for param, value := range params {
+		values[param] = value
+	}
+
+	values, err = strategy.ValidateParams(tunable.Parameters(), values)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.applyParams(name, tunable, values); err != nil {
+		return nil, err
+	}
+
+	err = n.storage.SaveStrategyParams(&model.StrategyParams{
+		Strategy:  name,
+		Params:    values,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		// the running strategy must match the parameters restored on restart
+		if rollbackErr := n.applyParams(name, tunable, current); rollbackErr != nil {
+			log.Errorf("[PARAMS] rollback of strategy %q failed: %s", name, rollbackErr)
+		}
+		return nil, err
+	}
+
+	log.Infof("[PARAMS] strategy %q changed: %s", name, paramsChanges(current, values))
+	return values, nil
+}
+
+// applyParams changes the parameters of a strategy, waiting for the candles in progress and blocking
+// the strategy in all pairs during the change
+func (n *NinjaBot) applyParams(name string, tunable strategy.TunableStrategy, values strategy.Params) error {
+	for _, controller := range n.allocationControllers[name] {
+		controller.Lock()
+	}
+	defer func() {
+		for _, controller := range n.allocationControllers[name] {
+			controller.Unlock()
+		}
+	}()
+
+	return tunable.SetParams(values)
+}
+
+// restoreStrategyParams applies the parameters changed in previous executions
+func (n *NinjaBot) restoreStrategyParams() error {
+	saved, err := n.storage.StrategyParams()
+	if err != nil {
+		return err
+	}
+
+	for _, params := range saved {
+		allocation, err := n.allocation(params.Strategy)
+		if err != nil {
+			continue
+		}
+
+		tunable, ok := allocation.Strategy.(strategy.TunableStrategy)
+		if !ok {
+			continue
+		}
+
+		// parameters declared after the change keep their current values
+		values := tunable.ParamValues()
+		for param, value := range params.Params {
+			values[param] = value
+		}
+
+		values, err = strategy.ValidateParams(tunable.Parameters(), values)
+		if err != nil {
+			log.Warnf("[PARAMS] ignoring saved parameters of strategy %q: %s", params.Strategy, err)
+			continue
+		}
+
+		if err := tunable.SetParams(values); err != nil {
+			return err
+		}
+		log.Infof("[PARAMS] strategy %q restored: %s", params.Strategy, paramsChanges(nil, values))
+	}
+
+	return nil
+}
+
+// paramsChanges describes the values of the parameters, with the previous value of changed parameters
+func paramsChanges(previous, current strategy.Params) string {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changes := make([]string, 0, len(names))
+	for _, name := range names {
+		value, ok := previous[name]
+		if ok && fmt.Sprint(value) != fmt.Sprint(current[name]) {
+			changes = append(changes, fmt.Sprintf("%s=%v (was %v)", name, current[name], value))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s=%v", name, current[name]))
+	}
+	return strings.Join(changes, ", ")
+}
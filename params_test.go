@@ -0,0 +1,119 @@
+package ninjabot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/storage"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+type tunableStrategy struct {
+	fakeStrategy
+	period int
+}
+
+func (e tunableStrategy) Parameters() []strategy.Param {
+	return []strategy.Param{{Name: "period", Type: strategy.ParamInt, Default: 9, Min: 2, Max: 50}}
+}
+
+func (e tunableStrategy) ParamValues() strategy.Params {
+	return strategy.Params{"period": e.period}
+}
+
+func (e *tunableStrategy) SetParams(params strategy.Params) error {
+	e.period = params.Int("period", e.period)
+	return nil
+}
+
+// failingParamsStorage fails to persist the parameters of strategies
+type failingParamsStorage struct {
+	storage.Storage
+}
+
+func (failingParamsStorage) SaveStrategyParams(_ *model.StrategyParams) error {
+	return errors.New("storage unavailable")
+}
+
+func newParamsBot(t *testing.T, db storage.Storage, str strategy.Strategy) *NinjaBot {
+	ctx := context.Background()
+	csvFeed, err := exchange.NewCSVFeed("1d", exchange.PairFeed{
+		Pair:      "BTCUSDT",
+		File:      "testdata/btc-1h.csv",
+		Timeframe: "1h",
+	})
+	require.NoError(t, err)
+
+	paperWallet := exchange.NewPaperWallet(ctx, "USDT",
+		exchange.WithPaperAsset("USDT", 10000),
+		exchange.WithDataFeed(csvFeed),
+	)
+
+	bot, err := NewBot(ctx, Settings{Pairs: []string{"BTCUSDT"}}, paperWallet, nil,
+		WithStrategy("tunable", str, []string{"BTCUSDT"}, 0),
+		WithStrategy("fixed", new(fakeStrategy), []string{"BTCUSDT"}, 0),
+		WithStorage(db),
+		WithBacktest(paperWallet),
+		WithLogLevel(log.ErrorLevel),
+		WithProgressBar(false),
+	)
+	require.NoError(t, err)
+	return bot
+}
+
+func TestNinjaBot_SetStrategyParams(t *testing.T) {
+	db, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	str := &tunableStrategy{period: 9}
+	bot := newParamsBot(t, db, str)
+	require.NoError(t, bot.Run(context.Background()))
+
+	params, err := bot.StrategyParams("tunable")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"period": 9}, params)
+
+	params, err = bot.SetStrategyParams("tunable", map[string]any{"period": "12"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"period": 12}, params)
+	require.Equal(t, 12, str.period)
+
+	saved, err := db.StrategyParams()
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	require.Equal(t, "tunable", saved[0].Strategy)
+	require.Equal(t, 12.0, saved[0].Params["period"])
+
+	_, err = bot.SetStrategyParams("tunable", map[string]any{"period": 100})
+	require.ErrorIs(t, err, strategy.ErrInvalidParam)
+	require.Equal(t, 12, str.period)
+
+	_, err = bot.SetStrategyParams("fixed", map[string]any{"ema": 10})
+	require.ErrorIs(t, err, strategy.ErrNotTunable)
+
+	_, err = bot.StrategyParams("not-found")
+	require.ErrorIs(t, err, strategy.ErrStrategyNotFound)
+
+	t.Run("restore", func(t *testing.T) {
+		restored := &tunableStrategy{period: 9}
+		bot := newParamsBot(t, db, restored)
+		require.NoError(t, bot.restoreStrategyParams())
+		require.Equal(t, 12, restored.period)
+	})
+	t.Run("rollback", func(t *testing.T) {
+		db, err := storage.FromMemory()
+		require.NoError(t, err)
+
+		str := &tunableStrategy{period: 9}
+		bot := newParamsBot(t, failingParamsStorage{db}, str)
+		_, err = bot.SetStrategyParams("tunable", map[string]any{"period": 12})
+		require.ErrorContains(t, err, "storage unavailable")
+		require.Equal(t, 9, str.period)
+	})
+}
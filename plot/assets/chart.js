@@ -14,11 +14,19 @@ function unpack(rows, key) {
   });
 }
 
+// loadData returns the data embedded in standalone reports or fetches it from the server
+function loadData(pair) {
+  if (window.NINJABOT_DATA) {
+    return Promise.resolve(window.NINJABOT_DATA[pair]);
+  }
+  return fetch("/data?pair=" + pair).then((data) => data.json());
+}
+
 document.addEventListener("DOMContentLoaded", function () {
   const params = new URLSearchParams(window.location.search);
-  const pair = params.get("pair") || "";
-  fetch("/data?pair=" + pair)
-    .then((data) => data.json())
+  const pair =
+    params.get("pair") || (window.NINJABOT_PAIRS && window.NINJABOT_PAIRS[0]) || "";
+  loadData(pair)
     .then((data) => {
       const candleStickData = {
         name: "Candles",
@@ -42,6 +50,16 @@ document.addEventListener("DOMContentLoaded", function () {
         yaxis: "y1",
       };
 
+      const benchmarkData = {
+        name: `Buy and Hold (${data.quote})`,
+        x: unpack(data.benchmark_values || [], "time"),
+        y: unpack(data.benchmark_values || [], "value"),
+        mode: "lines",
+        line: { dash: "dot", color: "gray" },
+        xaxis: "x1",
+        yaxis: "y1",
+      };
+
       const assetData = {
         name: `Position (${data.asset}/${data.quote})`,
         x: unpack(data.asset_values, "time"),
@@ -245,6 +263,7 @@ document.addEventListener("DOMContentLoaded", function () {
       let plotData = [
         candleStickData,
         equityData,
+        benchmarkData,
         assetData,
         buyData,
         sellData,
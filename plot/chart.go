@@ -5,9 +5,13 @@ import (
 	"embed"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"net/http"
+	"os"
 	"sort"
 	"strings"
 	"sync"
@@ -22,9 +26,15 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+//go:generate curl -sSfL -o assets/plotly.min.js https://cdn.plot.ly/plotly-2.27.0.min.js
+
 var (
 	//go:embed assets
 	staticFiles embed.FS
+
+	// ErrPlotlyNotEmbedded is returned by the standalone reports without the pinned plotly build,
+	// generated in assets/plotly.min.js with go:generate above
+	ErrPlotlyNotEmbedded = errors.New("plotly build not embedded, run go generate ./plot")
 )
 
 type Chart struct {
@@ -38,7 +48,9 @@ type Chart struct {
 	indicators      []Indicator
 	paperWallet     *exchange.PaperWallet
 	scriptContent   string
+	plotlyContent   string
 	indexHTML       *template.Template
+	reportHTML      *template.Template
 	strategy        strategy.Strategy
 	lastUpdate      time.Time
 }
@@ -175,6 +187,19 @@ func (c *Chart) equityValuesByPair(pair string) (asset []assetValue, quote []ass
 	return assetValues, equityValues
 }
 
+func (c *Chart) benchmarkValues() []assetValue {
+	values := make([]assetValue, 0)
+	if c.paperWallet != nil {
+		for _, value := range c.paperWallet.BenchmarkValues() {
+			values = append(values, assetValue{
+				Time:  value.Time,
+				Value: value.Value,
+			})
+		}
+	}
+	return values
+}
+
 func (c *Chart) indicatorsByPair(pair string) []plotIndicator {
 	indicators := make([]plotIndicator, 0)
 	for _, i := range c.indicators {
@@ -342,15 +367,7 @@ func (c *Chart) handleIndex(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (c *Chart) handleData(w http.ResponseWriter, r *http.Request) {
-	pair := r.URL.Query().Get("pair")
-	if pair == "" {
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-
-	w.Header().Set("Content-type", "text/json")
-
+func (c *Chart) pairData(pair string) map[string]interface{} {
 	var maxDrawdown *drawdown
 	if c.paperWallet != nil {
 		value, start, end := c.paperWallet.MaxDrawdown()
@@ -363,16 +380,29 @@ func (c *Chart) handleData(w http.ResponseWriter, r *http.Request) {
 
 	asset, quote := exchange.SplitAssetQuote(pair)
 	assetValues, equityValues := c.equityValuesByPair(pair)
-	err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"candles":       c.candlesByPair(pair),
-		"indicators":    c.indicatorsByPair(pair),
-		"shapes":        c.shapesByPair(pair),
-		"asset_values":  assetValues,
-		"equity_values": equityValues,
-		"quote":         quote,
-		"asset":         asset,
-		"max_drawdown":  maxDrawdown,
-	})
+	return map[string]interface{}{
+		"candles":          c.candlesByPair(pair),
+		"indicators":       c.indicatorsByPair(pair),
+		"shapes":           c.shapesByPair(pair),
+		"asset_values":     assetValues,
+		"equity_values":    equityValues,
+		"benchmark_values": c.benchmarkValues(),
+		"quote":            quote,
+		"asset":            asset,
+		"max_drawdown":     maxDrawdown,
+	}
+}
+
+func (c *Chart) handleData(w http.ResponseWriter, r *http.Request) {
+	pair := r.URL.Query().Get("pair")
+	if pair == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-type", "text/json")
+
+	err := json.NewEncoder(w).Encode(c.pairData(pair))
 	if err != nil {
 		log.Error(err)
 	}
@@ -417,6 +447,48 @@ func (c *Chart) handleTradingHistoryData(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// WriteHTML writes a standalone HTML page with the chart of all pairs and the report of the results,
+// eg: ninjabot.BacktestReport. The report is optional and rendered as tables in the page.
+func (c *Chart) WriteHTML(writer io.Writer, report interface{}) error {
+	if c.plotlyContent == "" {
+		return ErrPlotlyNotEmbedded
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	pairs := make([]string, 0, len(c.candles))
+	data := make(map[string]interface{}, len(c.candles))
+	for pair := range c.candles {
+		pairs = append(pairs, pair)
+		data[pair] = c.pairData(pair)
+	}
+	sort.Strings(pairs)
+
+	return c.reportHTML.Execute(writer, map[string]interface{}{
+		"pairs":  pairs,
+		"data":   data,
+		"report": report,
+		"script": template.JS(c.scriptContent), //nolint:gosec
+		"plotly": template.JS(c.plotlyContent), //nolint:gosec
+	})
+}
+
+// SaveHTML writes the standalone HTML page in a file, see WriteHTML
+func (c *Chart) SaveHTML(filename string, report interface{}) error {
+	if c.plotlyContent == "" {
+		return ErrPlotlyNotEmbedded
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return c.WriteHTML(file, report)
+}
+
 func (c *Chart) Start() error {
 	http.Handle(
 		"/assets/",
@@ -493,6 +565,18 @@ func NewChart(options ...Option) (*Chart, error) {
 		return nil, err
 	}
 
+	chart.reportHTML, err = template.ParseFS(staticFiles, "assets/report.html")
+	if err != nil {
+		return nil, err
+	}
+
+	// the plotly build is inlined in the standalone reports, see WriteHTML
+	plotlyJS, err := staticFiles.ReadFile("assets/plotly.min.js")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	chart.plotlyContent = string(plotlyJS)
+
 	transpileChartJS := api.Transform(string(chartJS), api.TransformOptions{
 		Loader:            api.LoaderJS,
 		Target:            api.ES2015,
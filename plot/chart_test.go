@@ -1,6 +1,8 @@
 package plot
 
 import (
+	"bytes"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -233,3 +235,41 @@ func TestChart_OrderStringByPair(t *testing.T) {
 	ordersPair2 := c.orderStringByPair(pair2)
 	require.Equal(t, expectPair2, ordersPair2)
 }
+
+func TestChart_WriteHTML(t *testing.T) {
+	c, err := NewChart()
+	require.NoError(t, err)
+
+	// the pinned plotly build is required by the standalone reports
+	c.plotlyContent = ""
+	require.ErrorIs(t, c.WriteHTML(bytes.NewBuffer(nil), nil), ErrPlotlyNotEmbedded)
+	filename := filepath.Join(t.TempDir(), "report.html")
+	require.ErrorIs(t, c.SaveHTML(filename, nil), ErrPlotlyNotEmbedded)
+	require.NoFileExists(t, filename)
+	c.plotlyContent = "window.Plotly = {};"
+
+	c.OnCandle(model.Candle{
+		Pair:     "BTCUSDT",
+		Time:     time.Date(2021, 9, 26, 20, 0, 0, 0, time.UTC),
+		Open:     43000,
+		Close:    43500,
+		Low:      42800,
+		High:     43700,
+		Complete: true,
+	})
+
+	buffer := bytes.NewBuffer(nil)
+	err = c.WriteHTML(buffer, map[string]interface{}{
+		"total": map[string]interface{}{"trades": 3},
+	})
+	require.NoError(t, err)
+
+	html := buffer.String()
+	require.Contains(t, html, `window.NINJABOT_PAIRS = ["BTCUSDT"]`)
+	require.Contains(t, html, `"close":43500`)
+	require.Contains(t, html, `window.NINJABOT_REPORT = {"total":{"trades":3}}`)
+	require.Contains(t, html, "function loadData")
+	require.NotContains(t, html, `src="/assets/chart.js"`)
+	require.Contains(t, html, c.plotlyContent)
+	require.NotContains(t, html, "cdn.plot.ly")
+}
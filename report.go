@@ -0,0 +1,348 @@
+package ninjabot
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/order"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/metrics"
+)
+
+// BacktestReport is a structured version of the bot results, that can be exported to JSON or HTML (see plot.Chart)
+type BacktestReport struct {
+	CreatedAt   time.Time                  `json:"created_at"`
+	Summary     map[string]ReportSummary   `json:"summary"`
+	Total       ReportSummary              `json:"total"`
+	Trades      []ReportTrade              `json:"trades"`
+	Equity      []ReportValue              `json:"equity"`
+	Drawdown    *ReportDrawdown            `json:"drawdown,omitempty"`
+	Bootstrap   map[string]ReportBootstrap `json:"bootstrap"`
+	Performance ReportPerformance          `json:"performance"`
+	Benchmark   *ReportBenchmark           `json:"benchmark,omitempty"`
+	Strategies  []ReportStrategy           `json:"strategies"`
+}
+
+// ReportSummary contains the metrics of the trades of a pair, or all pairs in the total
+type ReportSummary struct {
+	Pair         string  `json:"pair"`
+	Trades       int     `json:"trades"`
+	Win          int     `json:"win"`
+	Loss         int     `json:"loss"`
+	WinPercent   float64 `json:"win_percent"`
+	Payoff       float64 `json:"payoff"`
+	ProfitFactor float64 `json:"profit_factor"`
+	SQN          float64 `json:"sqn"`
+	Profit       float64 `json:"profit"`
+	Volume       float64 `json:"volume"`
+}
+
+type ReportTrade struct {
+	Strategy      string    `json:"strategy,omitempty"`
+	Pair          string    `json:"pair"`
+	Side          string    `json:"side"`
+	CreatedAt     time.Time `json:"created_at"`
+	Duration      string    `json:"duration"`
+	ProfitPercent float64   `json:"profit_percent"`
+	ProfitValue   float64   `json:"profit_value"`
+}
+
+// ReportStrategy describes a strategy of the bot, with the values of its parameters (see strategy.ParametrizedStrategy)
+type ReportStrategy struct {
+	Name      string          `json:"name"`
+	Timeframe string          `json:"timeframe"`
+	Pairs     []string        `json:"pairs"`
+	Capital   float64         `json:"capital,omitempty"`
+	Params    strategy.Params `json:"params,omitempty"`
+}
+
+type ReportValue struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type ReportDrawdown struct {
+	Value float64   `json:"value"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type ReportInterval struct {
+	Mean  float64 `json:"mean"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// ReportBootstrap contains the confidence intervals (95%) of the trades of a pair
+type ReportBootstrap struct {
+	Return       ReportInterval `json:"return"`
+	Payoff       ReportInterval `json:"payoff"`
+	ProfitFactor ReportInterval `json:"profit_factor"`
+}
+
+// ReportPerformance contains risk-adjusted metrics calculated from the equity curve and the trades.
+// Metrics from the equity curve are only available with a paper wallet.
+type ReportPerformance struct {
+	CAGR                 float64 `json:"cagr"`
+	SharpeRatio          float64 `json:"sharpe_ratio"`
+	SortinoRatio         float64 `json:"sortino_ratio"`
+	CalmarRatio          float64 `json:"calmar_ratio"`
+	UlcerIndex           float64 `json:"ulcer_index"`
+	RecoveryFactor       float64 `json:"recovery_factor"`
+	Exposure             float64 `json:"exposure"`
+	AverageDuration      string  `json:"average_duration"`
+	MaxConsecutiveLosses int     `json:"max_consecutive_losses"`
+}
+
+// ReportBenchmark compares the strategy equity with a buy-and-hold of the traded pairs,
+// with the initial portfolio equally divided between the pairs.
+type ReportBenchmark struct {
+	Equity           []ReportValue `json:"equity"`
+	Return           float64       `json:"return"`
+	Alpha            float64       `json:"alpha"`
+	Beta             float64       `json:"beta"`
+	Correlation      float64       `json:"correlation"`
+	InformationRatio float64       `json:"information_ratio"`
+}
+
+// finite replaces NaN and infinite values, not supported by JSON
+func finite(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+	return value
+}
+
+func newReportSummary(summary *order.Summary) ReportSummary {
+	trades := len(summary.Win()) + len(summary.Lose())
+	report := ReportSummary{
+		Pair:         summary.Pair,
+		Trades:       trades,
+		Win:          len(summary.Win()),
+		Loss:         len(summary.Lose()),
+		Payoff:       finite(summary.Payoff()),
+		ProfitFactor: finite(summary.ProfitFactor()),
+		SQN:          finite(summary.SQN()),
+		Profit:       summary.Profit(),
+		Volume:       summary.Volume,
+	}
+
+	if trades > 0 {
+		report.WinPercent = float64(report.Win) / float64(trades) * 100
+	}
+	return report
+}
+
+func newReportInterval(interval metrics.BootstrapInterval) ReportInterval {
+	return ReportInterval{
+		Mean:  finite(interval.Mean),
+		Lower: finite(interval.Lower),
+		Upper: finite(interval.Upper),
+	}
+}
+
+// uniqueValues returns a single value for each time, keeping the last one
+func uniqueValues(values []exchange.AssetValue) []ReportValue {
+	result := make([]ReportValue, 0)
+	for _, value := range values {
+		// wallet registers the values for the candle of each pair
+		if last := len(result) - 1; last >= 0 && result[last].Time.Equal(value.Time) {
+			result[last].Value = value.Value
+			continue
+		}
+		result = append(result, ReportValue{Time: value.Time, Value: value.Value})
+	}
+	return result
+}
+
+// equityCurve returns the equity values of the paper wallet, with a single value for each time
+func (n *NinjaBot) equityCurve() []ReportValue {
+	if n.paperWallet == nil {
+		return make([]ReportValue, 0)
+	}
+	return uniqueValues(n.paperWallet.EquityValues())
+}
+
+// periodsPerYear estimates the number of values per year in the curve
+func periodsPerYear(curve []ReportValue) float64 {
+	duration := curve[len(curve)-1].Time.Sub(curve[0].Time)
+	if duration <= 0 {
+		return 0
+	}
+	return float64(len(curve)-1) * float64(365*24*time.Hour) / float64(duration)
+}
+
+func curveValues(curve []ReportValue) []float64 {
+	values := make([]float64, len(curve))
+	for i, value := range curve {
+		values[i] = value.Value
+	}
+	return values
+}
+
+// benchmark compares the equity curve with the buy-and-hold benchmark of the paper wallet
+func (n *NinjaBot) benchmark() *ReportBenchmark {
+	if n.paperWallet == nil {
+		return nil
+	}
+
+	curve := n.equityCurve()
+	benchmarkCurve := uniqueValues(n.paperWallet.BenchmarkValues())
+	report := &ReportBenchmark{Equity: benchmarkCurve}
+	if len(curve) < 2 || len(benchmarkCurve) != len(curve) {
+		return report
+	}
+
+	benchmarkEquity := curveValues(benchmarkCurve)
+	returns := metrics.Returns(curveValues(curve))
+	benchmarkReturns := metrics.Returns(benchmarkEquity)
+	perYear := periodsPerYear(curve)
+
+	report.Return = finite(benchmarkEquity[len(benchmarkEquity)-1]/benchmarkEquity[0] - 1)
+	report.Alpha = finite(metrics.Alpha(returns, benchmarkReturns, perYear))
+	report.Beta = finite(metrics.Beta(returns, benchmarkReturns))
+	report.Correlation = finite(metrics.Correlation(returns, benchmarkReturns))
+	report.InformationRatio = finite(metrics.InformationRatio(returns, benchmarkReturns, perYear))
+	return report
+}
+
+// performance calculates the risk-adjusted metrics of all trades
+func (n *NinjaBot) performance() ReportPerformance {
+	trades := make([]order.Result, 0)
+	for _, summary := range n.orderController.Results {
+		trades = append(trades, summary.Trades...)
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].CreatedAt.Before(trades[j].CreatedAt)
+	})
+
+	profits := make([]float64, 0, len(trades))
+	durations := make([]time.Duration, 0, len(trades))
+	periods := make([]metrics.Period, 0, len(trades))
+	for _, trade := range trades {
+		profits = append(profits, trade.ProfitValue)
+		durations = append(durations, trade.Duration)
+		periods = append(periods, metrics.Period{Start: trade.CreatedAt.Add(-trade.Duration), End: trade.CreatedAt})
+	}
+
+	performance := ReportPerformance{
+		AverageDuration:      metrics.AverageDuration(durations).String(),
+		MaxConsecutiveLosses: metrics.MaxConsecutiveLosses(profits),
+	}
+
+	curve := n.equityCurve()
+	if len(curve) < 2 {
+		return performance
+	}
+
+	equity := curveValues(curve)
+	start, end := curve[0].Time, curve[len(curve)-1].Time
+	duration := end.Sub(start)
+	perYear := periodsPerYear(curve)
+	returns := metrics.Returns(equity)
+
+	performance.CAGR = finite(metrics.CAGR(equity[0], equity[len(equity)-1], duration))
+	performance.SharpeRatio = finite(metrics.SharpeRatio(returns, perYear))
+	performance.SortinoRatio = finite(metrics.SortinoRatio(returns, perYear))
+	performance.CalmarRatio = finite(metrics.CalmarRatio(equity, duration))
+	performance.UlcerIndex = finite(metrics.UlcerIndex(equity))
+	performance.RecoveryFactor = finite(metrics.RecoveryFactor(equity))
+	performance.Exposure = metrics.Exposure(periods, start, end)
+
+	return performance
+}
+
+// Report returns the results of the bot in a structured format
+func (n *NinjaBot) Report() *BacktestReport {
+	report := &BacktestReport{
+		CreatedAt: time.Now(),
+		Summary:   make(map[string]ReportSummary),
+		Trades:    make([]ReportTrade, 0),
+		Bootstrap: make(map[string]ReportBootstrap),
+	}
+
+	total := &order.Summary{Pair: "TOTAL"}
+	for pair, summary := range n.orderController.Results {
+		report.Summary[pair] = newReportSummary(summary)
+
+		total.WinLong = append(total.WinLong, summary.WinLong...)
+		total.WinShort = append(total.WinShort, summary.WinShort...)
+		total.LoseLong = append(total.LoseLong, summary.LoseLong...)
+		total.LoseShort = append(total.LoseShort, summary.LoseShort...)
+		total.WinLongPercent = append(total.WinLongPercent, summary.WinLongPercent...)
+		total.WinShortPercent = append(total.WinShortPercent, summary.WinShortPercent...)
+		total.LoseLongPercent = append(total.LoseLongPercent, summary.LoseLongPercent...)
+		total.LoseShortPercent = append(total.LoseShortPercent, summary.LoseShortPercent...)
+		total.Volume += summary.Volume
+
+		for _, trade := range summary.Trades {
+			report.Trades = append(report.Trades, ReportTrade{
+				Strategy:      trade.Strategy,
+				Pair:          trade.Pair,
+				Side:          string(trade.Side),
+				CreatedAt:     trade.CreatedAt,
+				Duration:      trade.Duration.String(),
+				ProfitPercent: trade.ProfitPercent,
+				ProfitValue:   trade.ProfitValue,
+			})
+		}
+
+		returns := append(summary.WinPercent(), summary.LosePercent()...)
+		report.Bootstrap[pair] = ReportBootstrap{
+			Return:       newReportInterval(metrics.Bootstrap(returns, metrics.Mean, 10000, 0.95)),
+			Payoff:       newReportInterval(metrics.Bootstrap(returns, metrics.Payoff, 10000, 0.95)),
+			ProfitFactor: newReportInterval(metrics.Bootstrap(returns, metrics.ProfitFactor, 10000, 0.95)),
+		}
+	}
+	report.Total = newReportSummary(total)
+
+	sort.SliceStable(report.Trades, func(i, j int) bool {
+		return report.Trades[i].CreatedAt.Before(report.Trades[j].CreatedAt)
+	})
+
+	for _, allocation := range n.strategies {
+		reportStrategy := ReportStrategy{
+			Name:      allocation.Name,
+			Timeframe: allocation.Strategy.Timeframe(),
+			Pairs:     allocation.Pairs,
+			Capital:   allocation.Capital,
+		}
+		if parametrized, ok := allocation.Strategy.(strategy.ParametrizedStrategy); ok {
+			reportStrategy.Params = parametrized.ParamValues()
+		}
+		report.Strategies = append(report.Strategies, reportStrategy)
+	}
+
+	report.Equity = n.equityCurve()
+	report.Performance = n.performance()
+	report.Benchmark = n.benchmark()
+	if n.paperWallet != nil {
+		value, start, end := n.paperWallet.MaxDrawdown()
+		report.Drawdown = &ReportDrawdown{Value: finite(value), Start: start, End: end}
+	}
+
+	return report
+}
+
+// WriteJSON writes the report in JSON format
+func (r BacktestReport) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// SaveJSON writes the report in a JSON file
+func (r BacktestReport) SaveJSON(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return r.WriteJSON(file)
+}
@@ -0,0 +1,89 @@
+package ninjabot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/storage"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+func TestNinjaBot_Report(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	str := new(fakeStrategy)
+	csvFeed, err := exchange.NewCSVFeed(
+		str.Timeframe(),
+		exchange.PairFeed{
+			Pair:      "BTCUSDT",
+			File:      "testdata/btc-1h.csv",
+			Timeframe: "1h",
+		},
+	)
+	require.NoError(t, err)
+
+	paperWallet := exchange.NewPaperWallet(
+		ctx,
+		"USDT",
+		exchange.WithPaperAsset("USDT", 10000),
+		exchange.WithDataFeed(csvFeed),
+	)
+
+	bot, err := NewBot(ctx, Settings{Pairs: []string{"BTCUSDT"}},
+		paperWallet,
+		str,
+		WithStorage(storage),
+		WithBacktest(paperWallet),
+		WithLogLevel(log.ErrorLevel),
+		WithProgressBar(false),
+	)
+	require.NoError(t, err)
+	require.NoError(t, bot.Run(ctx))
+
+	report := bot.Report()
+	summary := bot.Controller().Results["BTCUSDT"]
+	require.Equal(t, len(summary.Win())+len(summary.Lose()), report.Summary["BTCUSDT"].Trades)
+	require.Equal(t, report.Summary["BTCUSDT"].Trades, report.Total.Trades)
+	require.InDelta(t, summary.Profit(), report.Total.Profit, 1e-9)
+	require.Len(t, report.Trades, report.Total.Trades)
+	require.Equal(t, paperWallet.EquityValues()[0].Value, report.Equity[0].Value)
+	for i := 1; i < len(report.Equity); i++ {
+		require.True(t, report.Equity[i].Time.After(report.Equity[i-1].Time))
+	}
+	require.NotNil(t, report.Drawdown)
+	require.Contains(t, report.Bootstrap, "BTCUSDT")
+	require.NotZero(t, report.Performance.SharpeRatio)
+	require.NotZero(t, report.Performance.CAGR)
+	require.Greater(t, report.Performance.Exposure, 0.0)
+	require.LessOrEqual(t, report.Performance.Exposure, 1.0)
+	require.Greater(t, report.Performance.MaxConsecutiveLosses, 0)
+
+	require.NotNil(t, report.Benchmark)
+	benchmark := report.Benchmark.Equity
+	require.Len(t, benchmark, len(report.Equity))
+	require.InDelta(t, benchmark[len(benchmark)-1].Value/benchmark[0].Value-1, report.Benchmark.Return, 1e-6)
+	require.Greater(t, report.Benchmark.Beta, 0.0)
+	require.Greater(t, report.Benchmark.Correlation, 0.0)
+
+	require.Equal(t, []ReportStrategy{
+		{Timeframe: "1d", Pairs: []string{"BTCUSDT"}, Params: strategy.Params{"ema": 9}},
+	}, report.Strategies)
+
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, report.WriteJSON(buffer))
+
+	var decoded BacktestReport
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &decoded))
+	require.Equal(t, report.Total, decoded.Total)
+	require.Len(t, decoded.Trades, len(report.Trades))
+	require.Equal(t, 9.0, decoded.Strategies[0].Params["ema"])
+}
@@ -0,0 +1,169 @@
+package risk
+
+import (
+	"errors"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+)
+
+var (
+	ErrBracketNotSupported      = errors.New("risk: bracket orders not supported by the broker")
+	ErrTrailingStopNotSupported = errors.New("risk: trailing stops not supported by the broker")
+)
+
+// Broker is a broker with the orders validated by a risk manager
+type Broker struct {
+	manager *Manager
+	broker  service.Broker
+}
+
+// Wrap returns a broker validated by the risk manager, e.g. the broker of each strategy
+func (m *Manager) Wrap(broker service.Broker) *Broker {
+	return &Broker{manager: m, broker: broker}
+}
+
+func (m *Manager) Account() (model.Account, error) {
+	return m.broker.Account()
+}
+
+func (m *Manager) Position(pair string) (asset, quote float64, err error) {
+	return m.broker.Position(pair)
+}
+
+func (m *Manager) Order(pair string, id int64) (model.Order, error) {
+	return m.broker.Order(pair, id)
+}
+
+func (m *Manager) CreateOrderOCO(side model.SideType, pair string, size, price, stop,
+	stopLimit float64) ([]model.Order, error) {
+	return m.Wrap(m.broker).CreateOrderOCO(side, pair, size, price, stop, stopLimit)
+}
+
+func (m *Manager) CreateOrderLimit(side model.SideType, pair string, size, limit float64) (model.Order, error) {
+	return m.Wrap(m.broker).CreateOrderLimit(side, pair, size, limit)
+}
+
+func (m *Manager) CreateOrderMarket(side model.SideType, pair string, size float64) (model.Order, error) {
+	return m.Wrap(m.broker).CreateOrderMarket(side, pair, size)
+}
+
+func (m *Manager) CreateOrderMarketQuote(side model.SideType, pair string, quote float64) (model.Order, error) {
+	return m.Wrap(m.broker).CreateOrderMarketQuote(side, pair, quote)
+}
+
+func (m *Manager) CreateOrderStop(pair string, quantity float64, limit float64) (model.Order, error) {
+	return m.Wrap(m.broker).CreateOrderStop(pair, quantity, limit)
+}
+
+func (m *Manager) CreateOrderBracket(side model.SideType, pair string, size, price, takeProfit,
+	stopLoss float64) (model.Bracket, error) {
+	return m.Wrap(m.broker).CreateOrderBracket(side, pair, size, price, takeProfit, stopLoss)
+}
+
+func (m *Manager) CreateTrailingStop(side model.SideType, pair string, size,
+	distance float64) (model.TrailingStop, error) {
+	return m.Wrap(m.broker).CreateTrailingStop(side, pair, size, distance)
+}
+
+func (m *Manager) CancelTrailingStop(stop model.TrailingStop) error {
+	return m.Wrap(m.broker).CancelTrailingStop(stop)
+}
+
+func (m *Manager) Cancel(order model.Order) error {
+	return m.broker.Cancel(order)
+}
+
+func (b *Broker) Account() (model.Account, error) {
+	return b.broker.Account()
+}
+
+func (b *Broker) Position(pair string) (asset, quote float64, err error) {
+	return b.broker.Position(pair)
+}
+
+func (b *Broker) Order(pair string, id int64) (model.Order, error) {
+	return b.broker.Order(pair, id)
+}
+
+func (b *Broker) CreateOrderOCO(side model.SideType, pair string, size, price, stop,
+	stopLimit float64) ([]model.Order, error) {
+	if err := b.manager.validate(side, pair, size, price); err != nil {
+		return nil, err
+	}
+	return b.broker.CreateOrderOCO(side, pair, size, price, stop, stopLimit)
+}
+
+func (b *Broker) CreateOrderLimit(side model.SideType, pair string, size, limit float64) (model.Order, error) {
+	if err := b.manager.validate(side, pair, size, limit); err != nil {
+		return model.Order{}, err
+	}
+	return b.broker.CreateOrderLimit(side, pair, size, limit)
+}
+
+func (b *Broker) CreateOrderMarket(side model.SideType, pair string, size float64) (model.Order, error) {
+	if err := b.manager.validate(side, pair, size, 0); err != nil {
+		return model.Order{}, err
+	}
+	return b.broker.CreateOrderMarket(side, pair, size)
+}
+
+func (b *Broker) CreateOrderMarketQuote(side model.SideType, pair string, quote float64) (model.Order, error) {
+	b.manager.mtx.Lock()
+	price := b.manager.lastPrice[pair]
+	b.manager.mtx.Unlock()
+
+	var size float64
+	if price > 0 {
+		size = quote / price
+	}
+
+	if err := b.manager.validate(side, pair, size, price); err != nil {
+		return model.Order{}, err
+	}
+	return b.broker.CreateOrderMarketQuote(side, pair, quote)
+}
+
+func (b *Broker) CreateOrderStop(pair string, quantity float64, limit float64) (model.Order, error) {
+	if err := b.manager.validate(model.SideTypeSell, pair, quantity, limit); err != nil {
+		return model.Order{}, err
+	}
+	return b.broker.CreateOrderStop(pair, quantity, limit)
+}
+
+// CreateOrderBracket validates the entry of a bracket order, if supported by the wrapped broker
+func (b *Broker) CreateOrderBracket(side model.SideType, pair string, size, price, takeProfit,
+	stopLoss float64) (model.Bracket, error) {
+	broker, ok := b.broker.(service.BracketBroker)
+	if !ok {
+		return model.Bracket{}, ErrBracketNotSupported
+	}
+
+	if err := b.manager.validate(side, pair, size, price); err != nil {
+		return model.Bracket{}, err
+	}
+	return broker.CreateOrderBracket(side, pair, size, price, takeProfit, stopLoss)
+}
+
+// CreateTrailingStop is always accepted, the exits reduce the positions
+func (b *Broker) CreateTrailingStop(side model.SideType, pair string, size,
+	distance float64) (model.TrailingStop, error) {
+	broker, ok := b.broker.(service.TrailingStopBroker)
+	if !ok {
+		return model.TrailingStop{}, ErrTrailingStopNotSupported
+	}
+	return broker.CreateTrailingStop(side, pair, size, distance)
+}
+
+func (b *Broker) CancelTrailingStop(stop model.TrailingStop) error {
+	broker, ok := b.broker.(service.TrailingStopBroker)
+	if !ok {
+		return ErrTrailingStopNotSupported
+	}
+	return broker.CancelTrailingStop(stop)
+}
+
+// Cancel is always accepted, also after the kill-switch
+func (b *Broker) Cancel(order model.Order) error {
+	return b.broker.Cancel(order)
+}
@@ -0,0 +1,123 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+func TestBroker(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newBroker := func(options ...Option) (*exchange.PaperWallet, *Broker) {
+		wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 10000))
+		manager := New(wallet, options...)
+		for _, candle := range []model.Candle{
+			{Pair: "BTCUSDT", Time: start, Close: 100, Complete: true},
+			{Pair: "ETHUSDT", Time: start, Close: 10, Complete: true},
+		} {
+			wallet.OnCandle(candle)
+			manager.OnCandle(candle)
+		}
+		return wallet, manager.Wrap(wallet)
+	}
+
+	t.Run("max position size", func(t *testing.T) {
+		_, broker := newBroker(WithMaxPositionSize("BTCUSDT", 1000))
+
+		_, err := broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 11)
+		require.ErrorIs(t, err, ErrMaxPositionSize)
+		var orderErr *exchange.OrderError
+		require.ErrorAs(t, err, &orderErr)
+		require.Equal(t, "BTCUSDT", orderErr.Pair)
+
+		_, err = broker.CreateOrderMarketQuote(model.SideTypeBuy, "BTCUSDT", 1100)
+		require.ErrorIs(t, err, ErrMaxPositionSize)
+
+		_, err = broker.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 12, 90)
+		require.ErrorIs(t, err, ErrMaxPositionSize)
+
+		_, err = broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 8)
+		require.NoError(t, err)
+
+		_, err = broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 3)
+		require.ErrorIs(t, err, ErrMaxPositionSize)
+
+		// other pairs are not limited
+		_, err = broker.CreateOrderMarket(model.SideTypeBuy, "ETHUSDT", 200)
+		require.NoError(t, err)
+	})
+
+	t.Run("max exposure", func(t *testing.T) {
+		_, broker := newBroker(WithMaxExposure(1500))
+
+		_, err := broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 10)
+		require.NoError(t, err)
+
+		_, err = broker.CreateOrderMarket(model.SideTypeBuy, "ETHUSDT", 60)
+		require.ErrorIs(t, err, ErrMaxExposure)
+
+		_, err = broker.CreateOrderMarket(model.SideTypeBuy, "ETHUSDT", 50)
+		require.NoError(t, err)
+
+		// orders that reduce the exposure are accepted
+		_, err = broker.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 5)
+		require.NoError(t, err)
+	})
+
+	t.Run("pending limit orders", func(t *testing.T) {
+		_, broker := newBroker(WithMaxPositionSize("BTCUSDT", 1000), WithMaxExposure(1500))
+
+		// limit orders below the price are not filled, but they count for the limits
+		for i := 0; i < 11; i++ {
+			_, err := broker.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 90)
+			require.NoError(t, err)
+		}
+		_, err := broker.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 90)
+		require.ErrorIs(t, err, ErrMaxPositionSize)
+
+		for i := 0; i < 44; i++ {
+			_, err := broker.CreateOrderLimit(model.SideTypeBuy, "ETHUSDT", 1, 9)
+			require.NoError(t, err)
+		}
+		_, err = broker.CreateOrderLimit(model.SideTypeBuy, "ETHUSDT", 1, 9)
+		require.ErrorIs(t, err, ErrMaxExposure)
+	})
+
+	t.Run("max orders per minute", func(t *testing.T) {
+		wallet, broker := newBroker(WithMaxOrdersPerMinute(2))
+
+		_, err := broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+		_, err = broker.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+		_, err = broker.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 1)
+		require.ErrorIs(t, err, ErrMaxOrdersPerMinute)
+
+		// the window is based on the time of the candles
+		candle := model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Minute), Close: 100, Complete: true}
+		wallet.OnCandle(candle)
+		broker.manager.OnCandle(candle)
+
+		_, err = broker.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("cancel after kill-switch", func(t *testing.T) {
+		_, broker := newBroker()
+
+		order, err := broker.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 90)
+		require.NoError(t, err)
+
+		broker.manager.killed = true
+		_, err = broker.CreateOrderLimit(model.SideTypeBuy, "BTCUSDT", 1, 90)
+		require.ErrorIs(t, err, ErrKillSwitch)
+		require.NoError(t, broker.Cancel(order))
+	})
+}
@@ -0,0 +1,339 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+var (
+	ErrMaxPositionSize    = errors.New("risk: max position size exceeded")
+	ErrMaxExposure        = errors.New("risk: max exposure exceeded")
+	ErrMaxOrdersPerMinute = errors.New("risk: max orders per minute exceeded")
+	ErrMaxDailyLoss       = errors.New("risk: max daily loss reached")
+	ErrKillSwitch         = errors.New("risk: trading stopped by max drawdown")
+)
+
+// Stopper stops the execution of orders, e.g. order.Controller
+type Stopper interface {
+	Stop()
+}
+
+// Manager enforces risk limits to the orders of the strategies. The manager is a broker itself, and it can
+// wrap other brokers (see Wrap), sharing the same limits. Limits are evaluated with the account balances
+// and the last price of the pairs, updated by OnCandle. Values are in the quote asset of the pairs.
+type Manager struct {
+	mtx    sync.Mutex
+	broker service.Broker
+
+	maxPositionSize    map[string]float64
+	maxExposure        float64
+	maxOrdersPerMinute int
+	maxDailyLoss       float64
+	maxDrawdown        float64
+
+	stopper  Stopper
+	notifier service.Notifier
+
+	lastPrice  map[string]float64
+	now        time.Time
+	orders     []time.Time
+	peak       float64
+	day        time.Time
+	dayEquity  float64
+	dailyLimit bool
+	killed     bool
+}
+
+type Option func(*Manager)
+
+// WithMaxPositionSize limits the value of the position in a pair
+func WithMaxPositionSize(pair string, value float64) Option {
+	return func(m *Manager) {
+		m.maxPositionSize[pair] = value
+	}
+}
+
+// WithMaxExposure limits the total value of the positions in all pairs
+func WithMaxExposure(value float64) Option {
+	return func(m *Manager) {
+		m.maxExposure = value
+	}
+}
+
+// WithMaxOrdersPerMinute limits the number of orders created in one minute
+func WithMaxOrdersPerMinute(orders int) Option {
+	return func(m *Manager) {
+		m.maxOrdersPerMinute = orders
+	}
+}
+
+// WithMaxDailyLoss blocks orders that increase the positions when the loss of the day
+// (equity at start of the day minus current equity) reaches the value, until the next day (UTC)
+func WithMaxDailyLoss(value float64) Option {
+	return func(m *Manager) {
+		m.maxDailyLoss = value
+	}
+}
+
+// WithMaxDrawdown activates the kill-switch when the equity falls the given fraction from its peak (e.g. 0.2 = 20%).
+// The kill-switch blocks all new orders and stops the stopper, if defined.
+func WithMaxDrawdown(fraction float64) Option {
+	return func(m *Manager) {
+		m.maxDrawdown = fraction
+	}
+}
+
+// WithStopper defines the order executor stopped by the kill-switch, e.g. order.Controller
+func WithStopper(stopper Stopper) Option {
+	return func(m *Manager) {
+		m.stopper = stopper
+	}
+}
+
+// WithNotifier defines the notifier of limits reached
+func WithNotifier(notifier service.Notifier) Option {
+	return func(m *Manager) {
+		m.notifier = notifier
+	}
+}
+
+// New creates a risk manager for a broker. The broker is also used to get the account balances.
+func New(broker service.Broker, options ...Option) *Manager {
+	manager := &Manager{
+		broker:          broker,
+		maxPositionSize: make(map[string]float64),
+		lastPrice:       make(map[string]float64),
+	}
+
+	for _, option := range options {
+		option(manager)
+	}
+
+	return manager
+}
+
+func (m *Manager) SetNotifier(notifier service.Notifier) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.notifier = notifier
+}
+
+// Stopped returns true if the kill-switch was activated
+func (m *Manager) Stopped() bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.killed
+}
+
+func (m *Manager) notify(message string) {
+	log.Warn(message)
+	if m.notifier != nil {
+		m.notifier.Notify(message)
+	}
+}
+
+// clock returns the time of the last candle, or the current time before the first candle
+func (m *Manager) clock() time.Time {
+	if m.now.IsZero() {
+		return time.Now()
+	}
+	return m.now
+}
+
+// OnCandle updates the last price of the pair and, with complete candles, evaluates the daily loss and drawdown
+func (m *Manager) OnCandle(candle model.Candle) {
+	// the stopper is called without the lock, it can wait for pending orders
+	if m.evaluate(candle) && m.stopper != nil {
+		m.stopper.Stop()
+	}
+}
+
+// evaluate updates the state of the manager and returns true when the kill-switch is activated
+func (m *Manager) evaluate(candle model.Candle) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.lastPrice[candle.Pair] = candle.Close
+	m.now = candle.UpdatedAt
+	if m.now.IsZero() {
+		m.now = candle.Time
+	}
+
+	if !candle.Complete || (m.maxDailyLoss <= 0 && m.maxDrawdown <= 0) {
+		return false
+	}
+
+	account, err := m.broker.Account()
+	if err != nil {
+		log.Error(err)
+		return false
+	}
+	equity := m.equity(account)
+
+	day := candle.Time.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(m.day) {
+		m.day = day
+		m.dayEquity = equity
+		m.dailyLimit = false
+	}
+
+	if m.maxDailyLoss > 0 && !m.dailyLimit && m.dayEquity-equity >= m.maxDailyLoss {
+		m.dailyLimit = true
+		m.notify(fmt.Sprintf("[RISK] max daily loss reached: %.2f, new positions blocked until next day",
+			m.dayEquity-equity))
+	}
+
+	m.peak = math.Max(m.peak, equity)
+	if m.maxDrawdown > 0 && !m.killed && m.peak > 0 && (m.peak-equity)/m.peak >= m.maxDrawdown {
+		m.killed = true
+		m.notify(fmt.Sprintf("[RISK] max drawdown reached: %.2f%%, trading stopped",
+			(m.peak-equity)/m.peak*100))
+		return true
+	}
+
+	return false
+}
+
+// equity returns the value of the account, with the assets of the pairs with known price
+func (m *Manager) equity(account model.Account) float64 {
+	var equity float64
+	quotes := make(map[string]bool)
+	for pair, price := range m.lastPrice {
+		asset, quote := exchange.SplitAssetQuote(pair)
+		assetBalance, quoteBalance := account.Balance(asset, quote)
+		if !quotes[quote] {
+			quotes[quote] = true
+			equity += quoteBalance.Free + quoteBalance.Lock
+		}
+		equity += (assetBalance.Free + assetBalance.Lock) * price
+	}
+	return equity
+}
+
+// exposure returns the total value of the positions and of the pending orders that increase them,
+// ignoring a given pair
+func (m *Manager) exposure(account model.Account, ignore string) (float64, error) {
+	var exposure float64
+	for pair, price := range m.lastPrice {
+		if pair == ignore {
+			continue
+		}
+		asset, quote := exchange.SplitAssetQuote(pair)
+		assetBalance, _ := account.Balance(asset, quote)
+		position := assetBalance.Free + assetBalance.Lock
+
+		side := model.SideTypeBuy
+		if position < 0 {
+			side = model.SideTypeSell
+		}
+		pending, err := m.pending(pair, side)
+		if err != nil {
+			return 0, err
+		}
+		exposure += (math.Abs(position) + pending) * price
+	}
+	return exposure, nil
+}
+
+// pending returns the quantity not executed of the open orders of a pair in the given side.
+// Brokers that do not list the pending orders are considered without open orders.
+func (m *Manager) pending(pair string, side model.SideType) (float64, error) {
+	lister, ok := m.broker.(service.PendingOrderLister)
+	if !ok {
+		return 0, nil
+	}
+
+	orders, err := lister.PendingOrders(pair)
+	if err != nil {
+		return 0, err
+	}
+
+	var quantity float64
+	for _, order := range orders {
+		if order.Side == side {
+			quantity += order.Quantity - order.ExecutedQuantity
+		}
+	}
+	return quantity, nil
+}
+
+// validate checks the limits for a new order. The price is the last price for market orders.
+func (m *Manager) validate(side model.SideType, pair string, size, price float64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.killed {
+		return &exchange.OrderError{Err: ErrKillSwitch, Pair: pair, Quantity: size}
+	}
+
+	if price == 0 {
+		price = m.lastPrice[pair]
+	}
+
+	now := m.clock()
+	if m.maxOrdersPerMinute > 0 {
+		recent := make([]time.Time, 0, len(m.orders))
+		for _, t := range m.orders {
+			if now.Sub(t) < time.Minute {
+				recent = append(recent, t)
+			}
+		}
+		m.orders = recent
+
+		if len(m.orders) >= m.maxOrdersPerMinute {
+			return &exchange.OrderError{Err: ErrMaxOrdersPerMinute, Pair: pair, Quantity: size}
+		}
+	}
+
+	account, err := m.broker.Account()
+	if err != nil {
+		return err
+	}
+
+	asset, quote := exchange.SplitAssetQuote(pair)
+	assetBalance, _ := account.Balance(asset, quote)
+	current := assetBalance.Free + assetBalance.Lock
+	next := current + size
+	if side == model.SideTypeSell {
+		next = current - size
+	}
+
+	// orders that reduce the position are always accepted
+	if math.Abs(next) > math.Abs(current) {
+		if m.dailyLimit {
+			return &exchange.OrderError{Err: ErrMaxDailyLoss, Pair: pair, Quantity: size}
+		}
+
+		// open orders in the same side increase the position when executed
+		pending, err := m.pending(pair, side)
+		if err != nil {
+			return err
+		}
+		value := (math.Abs(next) + pending) * price
+
+		if limit, ok := m.maxPositionSize[pair]; ok && value > limit {
+			return &exchange.OrderError{Err: ErrMaxPositionSize, Pair: pair, Quantity: size}
+		}
+
+		if m.maxExposure > 0 {
+			exposure, err := m.exposure(account, pair)
+			if err != nil {
+				return err
+			}
+			if exposure+value > m.maxExposure {
+				return &exchange.OrderError{Err: ErrMaxExposure, Pair: pair, Quantity: size}
+			}
+		}
+	}
+
+	m.orders = append(m.orders, now)
+	return nil
+}
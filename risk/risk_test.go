@@ -0,0 +1,106 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+type stopper struct {
+	stopped int
+}
+
+func (s *stopper) Stop() {
+	s.stopped++
+}
+
+type notifier struct {
+	messages []string
+}
+
+func (n *notifier) Notify(message string) {
+	n.messages = append(n.messages, message)
+}
+
+func (n *notifier) OnOrder(model.Order) {}
+
+func (n *notifier) OnError(error) {}
+
+func TestManager_Drawdown(t *testing.T) {
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 1000))
+	stop := &stopper{}
+	notify := &notifier{}
+	manager := New(wallet, WithMaxDrawdown(0.2), WithStopper(stop), WithNotifier(notify))
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	candle := model.Candle{Pair: "BTCUSDT", Time: start, Close: 100, Complete: true}
+	wallet.OnCandle(candle)
+	manager.OnCandle(candle)
+
+	_, err := manager.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 10)
+	require.NoError(t, err)
+
+	// equity: 1000 -> 850 (15% drawdown)
+	candle = model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), Close: 85, Complete: true}
+	wallet.OnCandle(candle)
+	manager.OnCandle(candle)
+	require.False(t, manager.Stopped())
+
+	// equity: 850 -> 790 (21% drawdown)
+	candle = model.Candle{Pair: "BTCUSDT", Time: start.Add(2 * time.Hour), Close: 79, Complete: true}
+	wallet.OnCandle(candle)
+	manager.OnCandle(candle)
+	require.True(t, manager.Stopped())
+	require.Equal(t, 1, stop.stopped)
+	require.Len(t, notify.messages, 1)
+
+	_, err = manager.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 10)
+	require.ErrorIs(t, err, ErrKillSwitch)
+
+	// kill-switch is activated only once
+	manager.OnCandle(candle)
+	require.Equal(t, 1, stop.stopped)
+}
+
+func TestManager_DailyLoss(t *testing.T) {
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 1000))
+	notify := &notifier{}
+	manager := New(wallet, WithMaxDailyLoss(100), WithNotifier(notify))
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	candle := model.Candle{Pair: "BTCUSDT", Time: start, Close: 100, Complete: true}
+	wallet.OnCandle(candle)
+	manager.OnCandle(candle)
+
+	_, err := manager.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 5)
+	require.NoError(t, err)
+
+	candle = model.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), Close: 80, Complete: true}
+	wallet.OnCandle(candle)
+	manager.OnCandle(candle)
+	require.Len(t, notify.messages, 1)
+
+	// new positions are blocked
+	_, err = manager.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+	require.ErrorIs(t, err, ErrMaxDailyLoss)
+
+	// orders that reduce the position are accepted
+	_, err = manager.CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	// limit is reset in the next day
+	candle = model.Candle{Pair: "BTCUSDT", Time: start.Add(24 * time.Hour), Close: 80, Complete: true}
+	wallet.OnCandle(candle)
+	manager.OnCandle(candle)
+
+	_, err = manager.CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+	require.False(t, manager.Stopped())
+}
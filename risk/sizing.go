@@ -0,0 +1,98 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/rodrigo-brito/ninjabot/indicator"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/order"
+	"github.com/rodrigo-brito/ninjabot/service"
+)
+
+// Position sizing helpers, they return quantities of the asset valid for the exchange.
+// The asset info is available in the exchange, e.g. `exchange.AssetsInfo(pair)`.
+
+// RoundQuantity rounds down the quantity to the step size of the asset. It returns zero when the quantity
+// is below the minimum quantity, and the maximum quantity when above it.
+func RoundQuantity(info model.AssetInfo, quantity float64) float64 {
+	if quantity <= 0 {
+		return 0
+	}
+
+	if info.StepSize > 0 {
+		// small tolerance to avoid floating point errors, e.g. 0.3 / 0.1 = 2.9999999999999996
+		quantity = math.Floor(quantity/info.StepSize+1e-9) * info.StepSize
+		precision := math.Pow(10, math.Ceil(-math.Log10(info.StepSize)-1e-9))
+		if precision > 1 {
+			quantity = math.Round(quantity*precision) / precision
+		}
+	}
+
+	if info.MaxQuantity > 0 && quantity > info.MaxQuantity {
+		quantity = RoundQuantity(model.AssetInfo{StepSize: info.StepSize}, info.MaxQuantity)
+	}
+
+	if quantity < info.MinQuantity || quantity <= 0 {
+		return 0
+	}
+
+	return quantity
+}
+
+// Equity returns the value of the position in a pair, including the quote balance
+func Equity(broker service.Broker, pair string, price float64) (float64, error) {
+	asset, quote, err := broker.Position(pair)
+	if err != nil {
+		return 0, err
+	}
+	return asset*price + quote, nil
+}
+
+// FixedFractional returns the quantity that loses a fraction of the equity (e.g. 0.01 = 1%)
+// when the price moves from the entry to the stop price
+func FixedFractional(info model.AssetInfo, equity, fraction, entry, stop float64) float64 {
+	distance := math.Abs(entry - stop)
+	if distance == 0 {
+		return 0
+	}
+	return RoundQuantity(info, equity*fraction/distance)
+}
+
+// VolatilityTarget returns the quantity where a move of one ATR represents a fraction of the equity
+// (e.g. 0.01 = 1%), based on the Average True Range of the last candles of the dataframe
+func VolatilityTarget(info model.AssetInfo, df *model.Dataframe, equity, fraction float64, period int) float64 {
+	if len(df.Close) <= period {
+		return 0
+	}
+
+	atr := indicator.ATR(df.High, df.Low, df.Close, period)
+	if last := atr[len(atr)-1]; last > 0 {
+		return RoundQuantity(info, equity*fraction/last)
+	}
+	return 0
+}
+
+// KellyFraction returns the fraction of the equity suggested by the Kelly criterion, based on the
+// win rate and payoff of the results: W - (1 - W) / R. It is zero without wins and losses or with negative edge.
+func KellyFraction(summary *order.Summary) float64 {
+	if summary == nil {
+		return 0
+	}
+
+	payoff := summary.Payoff()
+	if payoff == 0 {
+		return 0
+	}
+
+	win := summary.WinPercentage() / 100
+	return math.Max(0, math.Min(1, win-(1-win)/payoff))
+}
+
+// Kelly returns the quantity for a fraction of the Kelly criterion (e.g. 0.5 = half Kelly),
+// based on the historical results of the controller, e.g. `controller.Results[pair]`
+func Kelly(info model.AssetInfo, summary *order.Summary, equity, price, fraction float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	return RoundQuantity(info, equity*fraction*KellyFraction(summary)/price)
+}
@@ -0,0 +1,81 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/order"
+)
+
+func TestRoundQuantity(t *testing.T) {
+	info := model.AssetInfo{StepSize: 0.001, MinQuantity: 0.01, MaxQuantity: 100}
+
+	require.Equal(t, 1.234, RoundQuantity(info, 1.23456))
+	require.Equal(t, 0.3, RoundQuantity(model.AssetInfo{StepSize: 0.1}, 0.3))
+	require.Equal(t, 0.0, RoundQuantity(info, 0.0099))
+	require.Equal(t, 0.0, RoundQuantity(info, -1))
+	require.Equal(t, 100.0, RoundQuantity(info, 150))
+	require.Equal(t, 12.0, RoundQuantity(model.AssetInfo{StepSize: 1}, 12.9))
+	require.Equal(t, 1.23456, RoundQuantity(model.AssetInfo{}, 1.23456))
+}
+
+func TestEquity(t *testing.T) {
+	wallet := exchange.NewPaperWallet(context.Background(), "USDT",
+		exchange.WithPaperAsset("USDT", 1000), exchange.WithPaperAsset("BTC", 2))
+
+	equity, err := Equity(wallet, "BTCUSDT", 100)
+	require.NoError(t, err)
+	require.Equal(t, 1200.0, equity)
+}
+
+func TestFixedFractional(t *testing.T) {
+	info := model.AssetInfo{StepSize: 0.01}
+
+	// risk of 1% (100) with a stop distance of 5
+	require.Equal(t, 20.0, FixedFractional(info, 10000, 0.01, 100, 95))
+	require.Equal(t, 20.0, FixedFractional(info, 10000, 0.01, 100, 105))
+	require.Equal(t, 0.0, FixedFractional(info, 10000, 0.01, 100, 100))
+}
+
+func TestVolatilityTarget(t *testing.T) {
+	info := model.AssetInfo{StepSize: 0.01}
+	df := &model.Dataframe{Pair: "BTCUSDT"}
+	for i := 0; i < 20; i++ {
+		df.Close = append(df.Close, 100)
+		df.High = append(df.High, 101)
+		df.Low = append(df.Low, 99)
+	}
+
+	// ATR = 2, risk of 1% (100)
+	require.InDelta(t, 50.0, VolatilityTarget(info, df, 10000, 0.01, 14), 0.01)
+	require.Equal(t, 0.0, VolatilityTarget(info, df, 10000, 0.01, 20))
+}
+
+func TestKelly(t *testing.T) {
+	info := model.AssetInfo{StepSize: 0.01}
+
+	// win rate of 60% and payoff of 2: 0.6 - 0.4 / 2 = 0.4
+	summary := &order.Summary{
+		WinLong:         []float64{20, 20, 20},
+		WinLongPercent:  []float64{0.2, 0.2, 0.2},
+		LoseLong:        []float64{-10, -10},
+		LoseLongPercent: []float64{-0.1, -0.1},
+	}
+	require.InDelta(t, 0.4, KellyFraction(summary), 1e-9)
+	require.Equal(t, 20.0, Kelly(info, summary, 10000, 100, 0.5))
+
+	// negative edge
+	summary = &order.Summary{
+		WinLong:         []float64{10},
+		WinLongPercent:  []float64{0.1},
+		LoseLong:        []float64{-10, -10},
+		LoseLongPercent: []float64{-0.1, -0.1},
+	}
+	require.Equal(t, 0.0, KellyFraction(summary))
+	require.Equal(t, 0.0, KellyFraction(&order.Summary{}))
+	require.Equal(t, 0.0, KellyFraction(nil))
+}
@@ -0,0 +1,188 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+// operators of the conditions
+const (
+	greater      = ">"
+	greaterEqual = ">="
+	less         = "<"
+	lessEqual    = "<="
+	equal        = "=="
+	notEqual     = "!="
+	crossover    = "crossover"
+	crossunder   = "crossunder"
+)
+
+// seriesPattern matches a series with an optional offset of candles, eg: close[1] is the previous close
+var seriesPattern = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*(?:\.[a-zA-Z_][a-zA-Z0-9_]*)?)(?:\[(\d+)\])?$`)
+
+// operand of a condition, a series of the dataframe or a value
+type operand struct {
+	series string
+	offset int
+	value  Value
+}
+
+type condition struct {
+	left     operand
+	operator string
+	right    operand
+}
+
+func parseCondition(text string) (condition, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 3 {
+		return condition{}, fmt.Errorf("expected: <operand> <operator> <operand>")
+	}
+
+	switch fields[1] {
+	case greater, greaterEqual, less, lessEqual, equal, notEqual, crossover, crossunder:
+	default:
+		return condition{}, fmt.Errorf("unknown operator %s, available: >, >=, <, <=, ==, !=, crossover, crossunder",
+			fields[1])
+	}
+
+	left, err := parseOperand(fields[0])
+	if err != nil {
+		return condition{}, err
+	}
+
+	right, err := parseOperand(fields[2])
+	if err != nil {
+		return condition{}, err
+	}
+
+	if left.series == "" && right.series == "" {
+		return condition{}, fmt.Errorf("at least one operand must be a series")
+	}
+
+	return condition{left: left, operator: fields[1], right: right}, nil
+}
+
+func parseOperand(token string) (operand, error) {
+	if strings.HasPrefix(token, "$") {
+		return operand{value: Value{Param: strings.TrimPrefix(token, "$")}}, nil
+	}
+
+	if number, err := strconv.ParseFloat(token, 64); err == nil {
+		return operand{value: Value{Number: number}}, nil
+	}
+
+	match := seriesPattern.FindStringSubmatch(token)
+	if match == nil {
+		return operand{}, fmt.Errorf("invalid operand %s", token)
+	}
+
+	var offset int
+	if match[2] != "" {
+		offset, _ = strconv.Atoi(match[2])
+	}
+	return operand{series: match[1], offset: offset}, nil
+}
+
+// resolve replaces the references to parameters by their values
+func (c condition) resolve(params strategy.Params) condition {
+	for _, operand := range []*operand{&c.left, &c.right} {
+		if operand.series == "" {
+			operand.value = Value{Number: operand.value.resolve(params)}
+		}
+	}
+	return c
+}
+
+// values returns the series of the operand, values are repeated in a series of two elements
+func (o operand) values(df *model.Dataframe) model.Series[float64] {
+	if o.series == "" {
+		return model.Series[float64]{o.value.Number, o.value.Number}
+	}
+
+	var values model.Series[float64]
+	switch o.series {
+	case "open":
+		values = df.Open
+	case "high":
+		values = df.High
+	case "low":
+		values = df.Low
+	case "close":
+		values = df.Close
+	case "volume":
+		values = df.Volume
+	default:
+		values = df.Metadata[o.series]
+	}
+
+	if len(values) < o.offset {
+		return nil
+	}
+	return values[:len(values)-o.offset]
+}
+
+func (c condition) evaluate(df *model.Dataframe) bool {
+	left, right := c.left.values(df), c.right.values(df)
+	if len(left) == 0 || len(right) == 0 {
+		return false
+	}
+
+	switch c.operator {
+	case greater:
+		return left.Last(0) > right.Last(0)
+	case greaterEqual:
+		return left.Last(0) >= right.Last(0)
+	case less:
+		return left.Last(0) < right.Last(0)
+	case lessEqual:
+		return left.Last(0) <= right.Last(0)
+	case equal:
+		return left.Last(0) == right.Last(0)
+	case notEqual:
+		return left.Last(0) != right.Last(0)
+	}
+
+	if len(left) < 2 || len(right) < 2 {
+		return false
+	}
+
+	if c.operator == crossover {
+		return left.Crossover(right)
+	}
+	return left.Crossunder(right)
+}
+
+// rule is a compiled Rule
+type rule struct {
+	all []condition
+	any []condition
+}
+
+func (r rule) evaluate(df *model.Dataframe) bool {
+	if len(r.all) == 0 && len(r.any) == 0 {
+		return false
+	}
+
+	for _, cond := range r.all {
+		if !cond.evaluate(df) {
+			return false
+		}
+	}
+
+	if len(r.any) == 0 {
+		return true
+	}
+
+	for _, cond := range r.any {
+		if cond.evaluate(df) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,326 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+var ErrInvalidRules = errors.New("invalid rules")
+
+// Sizing types of the entry orders
+const (
+	// SizingPercent buys a percentage of the quote balance, eg: 50 = 50% (default: 100%)
+	SizingPercent = "percent"
+	// SizingQuote buys a fixed value in the quote asset, eg: 100 USDT
+	SizingQuote = "quote"
+	// SizingAmount buys a fixed amount of the asset, eg: 0.01 BTC
+	SizingAmount = "amount"
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Value is a number of the rules, or a reference to a numeric parameter of the strategy, eg: $period
+type Value struct {
+	Number float64
+	Param  string
+}
+
+func (v *Value) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && strings.HasPrefix(node.Value, "$") {
+		v.Param = strings.TrimPrefix(node.Value, "$")
+		return nil
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a number or $param", node.Line)
+	}
+
+	number, err := strconv.ParseFloat(node.Value, 64)
+	if err != nil {
+		return fmt.Errorf("line %d: expected a number or $param, got %s", node.Line, node.Value)
+	}
+	v.Number = number
+	return nil
+}
+
+// IsZero returns true if the value is not defined
+func (v Value) IsZero() bool {
+	return v.Param == "" && v.Number == 0
+}
+
+func (v Value) resolve(params strategy.Params) float64 {
+	if v.Param != "" {
+		return params.Float(v.Param, 0)
+	}
+	return v.Number
+}
+
+// Definition of a strategy with rules, usually loaded from a YAML file, see Load
+type Definition struct {
+	Name      string `yaml:"name"`
+	Timeframe string `yaml:"timeframe"`
+	// Warmup is the warmup period of the strategy, default: calculated from the periods of the indicators
+	Warmup int `yaml:"warmup"`
+
+	// Params are the parameters of the strategy, referenced in values as $name
+	Params     []strategy.Param `yaml:"params"`
+	Indicators []IndicatorRule  `yaml:"indicators"`
+
+	// Entry opens a long position, and Exit closes it
+	Entry Rule `yaml:"entry"`
+	Exit  Rule `yaml:"exit"`
+
+	Sizing Sizing      `yaml:"sizing"`
+	Stops  Stops       `yaml:"stops"`
+	Chart  []ChartRule `yaml:"chart"`
+}
+
+// IndicatorRule calculates an indicator, available in conditions and charts by its name.
+// Indicators with multiple outputs are available as name.output, eg: bb.upper, and the name is the first output.
+type IndicatorRule struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// Source is the input series of the indicator, eg: close (default), volume or the name of another indicator
+	Source string           `yaml:"source"`
+	Params map[string]Value `yaml:"params"`
+}
+
+// Rule is true when all conditions of All are true and at least one condition of Any is true.
+// Conditions compare two operands, eg: "ema crossover sma", "rsi < 30" or "close > close[1]".
+// Operators: >, >=, <, <=, ==, !=, crossover and crossunder.
+type Rule struct {
+	All []string `yaml:"all"`
+	Any []string `yaml:"any"`
+}
+
+// IsZero returns true if the rule has no conditions
+func (r Rule) IsZero() bool {
+	return len(r.All) == 0 && len(r.Any) == 0
+}
+
+type Sizing struct {
+	// Type of the size: percent (default), quote or amount
+	Type  string `yaml:"type"`
+	Value Value  `yaml:"value"`
+}
+
+// Stops close the position when the close price moves a percentage from the entry price, eg: 0.02 = 2%.
+// The trailing stop moves with the highest close since the entry. The stops are checked in the close of each
+// candle and executed with market orders.
+type Stops struct {
+	StopLoss   Value `yaml:"stop_loss"`
+	TakeProfit Value `yaml:"take_profit"`
+	Trailing   Value `yaml:"trailing"`
+}
+
+// ChartRule is a group of series displayed in the chart
+type ChartRule struct {
+	Group   string        `yaml:"group"`
+	Overlay bool          `yaml:"overlay"`
+	Metrics []ChartMetric `yaml:"metrics"`
+}
+
+type ChartMetric struct {
+	Series string `yaml:"series"`
+	// Name of the series in the chart, default: the name of the series
+	Name  string `yaml:"name"`
+	Color string `yaml:"color"`
+	// Style of the series: line (default), bar, scatter, histogram or waterfall
+	Style string `yaml:"style"`
+}
+
+// Load reads and validates the rules of a YAML file
+func Load(file string) (Definition, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return Definition{}, err
+	}
+	return Parse(content)
+}
+
+// Parse reads and validates rules in YAML
+func Parse(content []byte) (Definition, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	var definition Definition
+	if err := decoder.Decode(&definition); err != nil {
+		return Definition{}, fmt.Errorf("%w: %s", ErrInvalidRules, err)
+	}
+
+	if err := definition.Validate(); err != nil {
+		return Definition{}, err
+	}
+	return definition, nil
+}
+
+// Validate checks the structure of the rules and compiles them with the default values of the parameters
+func (d Definition) Validate() error {
+	if d.Timeframe == "" {
+		return fmt.Errorf("%w: timeframe is required", ErrInvalidRules)
+	}
+
+	if d.Warmup < 0 {
+		return fmt.Errorf("%w: warmup must be positive", ErrInvalidRules)
+	}
+
+	params := make(map[string]strategy.Param, len(d.Params))
+	for _, param := range d.Params {
+		if !namePattern.MatchString(param.Name) {
+			return fmt.Errorf("%w: invalid parameter name %q", ErrInvalidRules, param.Name)
+		}
+		if _, ok := params[param.Name]; ok {
+			return fmt.Errorf("%w: duplicated parameter %s", ErrInvalidRules, param.Name)
+		}
+		if _, err := param.Value(nil); err != nil {
+			return fmt.Errorf("%w: default value: %s", ErrInvalidRules, err)
+		}
+		params[param.Name] = param
+	}
+
+	checkValue := func(value Value, context string) error {
+		if value.Param == "" {
+			return nil
+		}
+
+		param, ok := params[value.Param]
+		if !ok {
+			return fmt.Errorf("%w: %s: unknown parameter $%s", ErrInvalidRules, context, value.Param)
+		}
+		if param.Type != strategy.ParamInt && param.Type != strategy.ParamFloat {
+			return fmt.Errorf("%w: %s: parameter $%s must be numeric", ErrInvalidRules, context, value.Param)
+		}
+		return nil
+	}
+
+	series := map[string]bool{"open": true, "high": true, "low": true, "close": true, "volume": true}
+	for _, rule := range d.Indicators {
+		context := fmt.Sprintf("indicator %q", rule.Name)
+		if !namePattern.MatchString(rule.Name) {
+			return fmt.Errorf("%w: %s: invalid name, use letters, numbers and _", ErrInvalidRules, context)
+		}
+		if series[rule.Name] {
+			return fmt.Errorf("%w: %s: duplicated name", ErrInvalidRules, context)
+		}
+
+		spec, ok := indicators[rule.Type]
+		if !ok {
+			return fmt.Errorf("%w: %s: unknown type %q, available: %s", ErrInvalidRules, context, rule.Type,
+				strings.Join(Indicators(), ", "))
+		}
+
+		if rule.Source != "" {
+			if !spec.source {
+				return fmt.Errorf("%w: %s: %s does not use a source", ErrInvalidRules, context, rule.Type)
+			}
+			if !series[rule.Source] {
+				return fmt.Errorf("%w: %s: unknown source %q, sources must be defined before the indicator",
+					ErrInvalidRules, context, rule.Source)
+			}
+		}
+
+		declared := make(map[string]bool, len(spec.params))
+		for _, param := range spec.params {
+			declared[param.name] = true
+			value, ok := rule.Params[param.name]
+			if !ok && isRequired(param) {
+				return fmt.Errorf("%w: %s: missing parameter %s", ErrInvalidRules, context, param.name)
+			}
+			if err := checkValue(value, context); err != nil {
+				return err
+			}
+		}
+		for name := range rule.Params {
+			if !declared[name] {
+				return fmt.Errorf("%w: %s: unknown parameter %s of %s", ErrInvalidRules, context, name, rule.Type)
+			}
+		}
+
+		series[rule.Name] = true
+		for _, output := range spec.outputs {
+			series[rule.Name+"."+output] = true
+		}
+	}
+
+	if d.Entry.IsZero() {
+		return fmt.Errorf("%w: entry rule is required", ErrInvalidRules)
+	}
+
+	for _, rule := range []struct {
+		name string
+		Rule
+	}{{"entry", d.Entry}, {"exit", d.Exit}} {
+		name := rule.name
+		for _, text := range append(append([]string{}, rule.All...), rule.Any...) {
+			cond, err := parseCondition(text)
+			if err != nil {
+				return fmt.Errorf("%w: %s condition %q: %s", ErrInvalidRules, name, text, err)
+			}
+
+			for _, operand := range []operand{cond.left, cond.right} {
+				if operand.series != "" && !series[operand.series] {
+					return fmt.Errorf("%w: %s condition %q: unknown series %s", ErrInvalidRules, name, text,
+						operand.series)
+				}
+				if err := checkValue(operand.value, fmt.Sprintf("%s condition %q", name, text)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	switch d.Sizing.Type {
+	case "", SizingPercent, SizingQuote, SizingAmount:
+	default:
+		return fmt.Errorf("%w: unknown sizing type %q, available: percent, quote, amount", ErrInvalidRules,
+			d.Sizing.Type)
+	}
+
+	for _, value := range []struct {
+		context string
+		Value
+	}{
+		{"sizing", d.Sizing.Value},
+		{"stop_loss", d.Stops.StopLoss},
+		{"take_profit", d.Stops.TakeProfit},
+		{"trailing", d.Stops.Trailing},
+	} {
+		if err := checkValue(value.Value, value.context); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range d.Chart {
+		for _, metric := range group.Metrics {
+			if !series[metric.Series] {
+				return fmt.Errorf("%w: chart: unknown series %q", ErrInvalidRules, metric.Series)
+			}
+
+			switch metric.Style {
+			case "", strategy.StyleLine, strategy.StyleBar, strategy.StyleScatter, strategy.StyleHistogram,
+				strategy.StyleWaterfall:
+			default:
+				return fmt.Errorf("%w: chart: unknown style %q of %s", ErrInvalidRules, metric.Style,
+					metric.Series)
+			}
+		}
+	}
+
+	// the values of the default parameters are validated in the compilation
+	_, err := d.compile(strategy.DefaultParams(d.Params))
+	return err
+}
+
+func isRequired(param specParam) bool {
+	return math.IsNaN(param.fallback)
+}
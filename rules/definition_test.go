@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+func TestLoad(t *testing.T) {
+	definition, err := Load("../examples/rules/emacross.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "emacross-rsi", definition.Name)
+	require.Equal(t, "4h", definition.Timeframe)
+	require.Len(t, definition.Params, 3)
+	require.Equal(t, strategy.ParamInt, definition.Params[0].Type)
+	require.Equal(t, Value{Param: "fast"}, definition.Indicators[0].Params["period"])
+	require.Equal(t, Value{Number: 14}, definition.Indicators[2].Params["period"])
+	require.Equal(t, Value{Number: 0.05}, definition.Stops.StopLoss)
+
+	_, err = Load("not-found.yaml")
+	require.Error(t, err)
+}
+
+func TestParse_Errors(t *testing.T) {
+	const base = `
+timeframe: 1h
+params:
+  - {name: period, type: int, default: 9}
+  - {name: mode, type: string, default: fast}
+entry:
+  all: [close crossover ema]
+indicators:
+  - {name: ema, type: ema, params: {period: $period}}
+  - {name: bb, type: bbands}
+`
+
+	tt := []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{name: "unknown field", rules: base + "stop: 1\n", err: "field stop not found"},
+		{name: "no timeframe", rules: "entry: {all: [close > 1]}\n", err: "timeframe is required"},
+		{name: "no entry", rules: "timeframe: 1h\n", err: "entry rule is required"},
+		{name: "unknown indicator", rules: base + "  - {name: x, type: foo}\n",
+			err: `indicator "x": unknown type "foo", available: adx, atr`},
+		{name: "missing parameter", rules: base + "  - {name: x, type: sma}\n",
+			err: `indicator "x": missing parameter period`},
+		{name: "unknown parameter", rules: base + "  - {name: x, type: rsi, params: {size: 1}}\n",
+			err: `indicator "x": unknown parameter size of rsi`},
+		{name: "unknown reference", rules: base + "  - {name: x, type: rsi, params: {period: $size}}\n",
+			err: `indicator "x": unknown parameter $size`},
+		{name: "string reference", rules: base + "  - {name: x, type: rsi, params: {period: $mode}}\n",
+			err: `indicator "x": parameter $mode must be numeric`},
+		{name: "invalid value", rules: base + "  - {name: x, type: rsi, params: {period: abc}}\n",
+			err: "expected a number or $param, got abc"},
+		{name: "invalid period", rules: base + "  - {name: x, type: rsi, params: {period: 0}}\n",
+			err: `indicator "x": period must be a positive integer, got 0`},
+		{name: "duplicated indicator", rules: base + "  - {name: ema, type: rsi}\n",
+			err: `indicator "ema": duplicated name`},
+		{name: "reserved name", rules: base + "  - {name: close, type: rsi}\n",
+			err: `indicator "close": duplicated name`},
+		{name: "unknown source", rules: base + "  - {name: x, type: rsi, source: y}\n",
+			err: `indicator "x": unknown source "y"`},
+		{name: "source not supported", rules: base + "  - {name: x, type: atr, source: close}\n",
+			err: `indicator "x": atr does not use a source`},
+		{name: "unknown series", rules: base + "exit: {any: [ema > sma]}\n",
+			err: `exit condition "ema > sma": unknown series sma`},
+		{name: "unknown operator", rules: base + "exit: {any: [ema => bb.lower]}\n",
+			err: `exit condition "ema => bb.lower": unknown operator =>`},
+		{name: "invalid condition", rules: base + "exit: {any: [ema crossunder]}\n",
+			err: "expected: <operand> <operator> <operand>"},
+		{name: "constant condition", rules: base + "exit: {any: [1 > 2]}\n",
+			err: "at least one operand must be a series"},
+		{name: "unknown sizing", rules: base + "sizing: {type: kelly}\n", err: `unknown sizing type "kelly"`},
+		{name: "invalid sizing", rules: base + "sizing: {type: quote, value: -1}\n",
+			err: "sizing value must be positive"},
+		{name: "invalid stop", rules: base + "stops: {stop_loss: 2}\n", err: "stops must be fractions"},
+		{name: "unknown chart series", rules: base + "chart: [{metrics: [{series: rsi}]}]\n",
+			err: `chart: unknown series "rsi"`},
+		{name: "unknown chart style", rules: base + "chart: [{metrics: [{series: ema, style: area}]}]\n",
+			err: `chart: unknown style "area" of ema`},
+		{name: "invalid default", rules: "timeframe: 1h\nparams: [{name: p, type: int}]\nentry: {all: [close > 1]}\n",
+			err: "default value: invalid parameter: p must be a number"},
+	}
+
+	_, err := Parse([]byte(base + "exit: {any: [ema crossunder bb.upper, \"close[1] > $period\"]}\n"))
+	require.NoError(t, err)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.rules))
+			require.ErrorIs(t, err, ErrInvalidRules)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package rules
+
+import (
+	"math"
+	"sort"
+
+	"github.com/rodrigo-brito/ninjabot/indicator"
+	"github.com/rodrigo-brito/ninjabot/model"
+)
+
+// required is the default value of the indicator parameters without default
+var required = math.NaN()
+
+type specParam struct {
+	name     string
+	fallback float64
+	// periods must be positive integers, and are used to calculate the warmup of the strategy
+	period bool
+}
+
+type indicatorSpec struct {
+	params []specParam
+	// outputs are the names of the series of indicators with multiple values, eg: bbands.upper
+	outputs []string
+	// source indicators are calculated from a series of the dataframe or of another indicator, default: close
+	source  bool
+	compute func(df *model.Dataframe, source []float64, params map[string]float64) [][]float64
+}
+
+func period(name string, fallback float64) specParam {
+	return specParam{name: name, fallback: fallback, period: true}
+}
+
+func sourceIndicator(fn func([]float64, int) []float64, fallback float64) indicatorSpec {
+	return indicatorSpec{
+		params: []specParam{period("period", fallback)},
+		source: true,
+		compute: func(_ *model.Dataframe, source []float64, params map[string]float64) [][]float64 {
+			return [][]float64{fn(source, int(params["period"]))}
+		},
+	}
+}
+
+func priceIndicator(fn func(high, low, close []float64, period int) []float64, fallback float64) indicatorSpec {
+	return indicatorSpec{
+		params: []specParam{period("period", fallback)},
+		compute: func(df *model.Dataframe, _ []float64, params map[string]float64) [][]float64 {
+			return [][]float64{fn(df.High, df.Low, df.Close, int(params["period"]))}
+		},
+	}
+}
+
+// indicators available in the rules, from the package indicator
+var indicators = map[string]indicatorSpec{
+	"sma":      sourceIndicator(indicator.SMA, required),
+	"ema":      sourceIndicator(indicator.EMA, required),
+	"wma":      sourceIndicator(indicator.WMA, required),
+	"dema":     sourceIndicator(indicator.DEMA, required),
+	"tema":     sourceIndicator(indicator.TEMA, required),
+	"trima":    sourceIndicator(indicator.TRIMA, required),
+	"kama":     sourceIndicator(indicator.KAMA, required),
+	"max":      sourceIndicator(indicator.Max, required),
+	"min":      sourceIndicator(indicator.Min, required),
+	"rsi":      sourceIndicator(indicator.RSI, 14),
+	"cmo":      sourceIndicator(indicator.CMO, 14),
+	"roc":      sourceIndicator(indicator.ROC, 10),
+	"momentum": sourceIndicator(indicator.Momentum, 10),
+	"atr":      priceIndicator(indicator.ATR, 14),
+	"natr":     priceIndicator(indicator.NATR, 14),
+	"adx":      priceIndicator(indicator.ADX, 14),
+	"cci":      priceIndicator(indicator.CCI, 14),
+	"willr":    priceIndicator(indicator.WilliamsR, 14),
+	"stddev": {
+		params: []specParam{period("period", 20), {name: "deviation", fallback: 1}},
+		source: true,
+		compute: func(_ *model.Dataframe, source []float64, params map[string]float64) [][]float64 {
+			return [][]float64{indicator.StdDev(source, int(params["period"]), params["deviation"])}
+		},
+	},
+	"mfi": {
+		params: []specParam{period("period", 14)},
+		compute: func(df *model.Dataframe, _ []float64, params map[string]float64) [][]float64 {
+			return [][]float64{indicator.MFI(df.High, df.Low, df.Close, df.Volume, int(params["period"]))}
+		},
+	},
+	"obv": {
+		source: true,
+		compute: func(df *model.Dataframe, source []float64, _ map[string]float64) [][]float64 {
+			return [][]float64{indicator.OBV(source, df.Volume)}
+		},
+	},
+	"supertrend": {
+		params: []specParam{period("period", 10), {name: "factor", fallback: 3}},
+		compute: func(df *model.Dataframe, _ []float64, params map[string]float64) [][]float64 {
+			return [][]float64{indicator.SuperTrend(df.High, df.Low, df.Close, int(params["period"]),
+				params["factor"])}
+		},
+	},
+	"bbands": {
+		params:  []specParam{period("period", 20), {name: "deviation", fallback: 2}},
+		outputs: []string{"upper", "middle", "lower"},
+		source:  true,
+		compute: func(_ *model.Dataframe, source []float64, params map[string]float64) [][]float64 {
+			upper, middle, lower := indicator.BB(source, int(params["period"]), params["deviation"],
+				indicator.TypeSMA)
+			return [][]float64{upper, middle, lower}
+		},
+	},
+	"macd": {
+		params:  []specParam{period("fast", 12), period("slow", 26), period("signal", 9)},
+		outputs: []string{"macd", "signal", "hist"},
+		source:  true,
+		compute: func(_ *model.Dataframe, source []float64, params map[string]float64) [][]float64 {
+			macd, signal, hist := indicator.MACD(source, int(params["fast"]), int(params["slow"]),
+				int(params["signal"]))
+			return [][]float64{macd, signal, hist}
+		},
+	},
+	"stoch": {
+		params:  []specParam{period("fastk", 14), period("slowk", 3), period("slowd", 3)},
+		outputs: []string{"k", "d"},
+		compute: func(df *model.Dataframe, _ []float64, params map[string]float64) [][]float64 {
+			k, d := indicator.Stoch(df.High, df.Low, df.Close, int(params["fastk"]), int(params["slowk"]),
+				indicator.TypeSMA, int(params["slowd"]), indicator.TypeSMA)
+			return [][]float64{k, d}
+		},
+	},
+}
+
+// Indicators returns the types of indicators available in the rules, sorted alphabetically
+func Indicators() []string {
+	names := make([]string, 0, len(indicators))
+	for name := range indicators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,335 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+type compiledIndicator struct {
+	name   string
+	source string
+	spec   indicatorSpec
+	params map[string]float64
+}
+
+// program is the definition compiled with the values of the parameters
+type program struct {
+	indicators []compiledIndicator
+	entry      rule
+	exit       rule
+	sizing     string
+	size       float64
+	stopLoss   float64
+	takeProfit float64
+	trailing   float64
+	warmup     int
+}
+
+func (d Definition) compile(params strategy.Params) (*program, error) {
+	prog := &program{sizing: d.Sizing.Type, size: d.Sizing.Value.resolve(params)}
+	if prog.sizing == "" {
+		prog.sizing = SizingPercent
+	}
+	if prog.sizing == SizingPercent && d.Sizing.Value.IsZero() {
+		prog.size = 100
+	}
+	if prog.size <= 0 {
+		return nil, fmt.Errorf("%w: sizing value must be positive", ErrInvalidRules)
+	}
+
+	prog.stopLoss = d.Stops.StopLoss.resolve(params)
+	prog.takeProfit = d.Stops.TakeProfit.resolve(params)
+	prog.trailing = d.Stops.Trailing.resolve(params)
+	if prog.stopLoss < 0 || prog.stopLoss >= 1 || prog.takeProfit < 0 || prog.trailing < 0 || prog.trailing >= 1 {
+		return nil, fmt.Errorf("%w: stops must be fractions of the price, eg: 0.02 = 2%%", ErrInvalidRules)
+	}
+
+	// warmup of each series, the sources of indicators are accumulated
+	warmups := make(map[string]int)
+	for _, rule := range d.Indicators {
+		indicator := compiledIndicator{
+			name:   rule.Name,
+			source: rule.Source,
+			spec:   indicators[rule.Type],
+			params: make(map[string]float64),
+		}
+		if indicator.source == "" && indicator.spec.source {
+			indicator.source = "close"
+		}
+
+		warmup := warmups[indicator.source]
+		for _, param := range indicator.spec.params {
+			value := param.fallback
+			if ruleValue, ok := rule.Params[param.name]; ok {
+				value = ruleValue.resolve(params)
+			}
+
+			if param.period {
+				if value < 1 || value != math.Trunc(value) {
+					return nil, fmt.Errorf("%w: indicator %q: %s must be a positive integer, got %v",
+						ErrInvalidRules, rule.Name, param.name, value)
+				}
+				warmup += int(value)
+			}
+			indicator.params[param.name] = value
+		}
+
+		warmups[rule.Name] = warmup
+		for _, output := range indicator.spec.outputs {
+			warmups[rule.Name+"."+output] = warmup
+		}
+		if warmup > prog.warmup {
+			prog.warmup = warmup
+		}
+		prog.indicators = append(prog.indicators, indicator)
+	}
+	// an additional candle is required to detect crosses
+	prog.warmup++
+
+	for _, item := range []struct {
+		definition Rule
+		compiled   *rule
+	}{{d.Entry, &prog.entry}, {d.Exit, &prog.exit}} {
+		for i, conditions := range [][]string{item.definition.All, item.definition.Any} {
+			for _, text := range conditions {
+				cond, err := parseCondition(text)
+				if err != nil {
+					return nil, fmt.Errorf("%w: condition %q: %s", ErrInvalidRules, text, err)
+				}
+
+				cond = cond.resolve(params)
+				if i == 0 {
+					item.compiled.all = append(item.compiled.all, cond)
+				} else {
+					item.compiled.any = append(item.compiled.any, cond)
+				}
+			}
+		}
+	}
+
+	return prog, nil
+}
+
+type position struct {
+	entry   float64
+	highest float64
+}
+
+// Strategy is a long-only strategy executed from rules, see Definition.
+// It implements strategy.TunableStrategy, with the parameters declared in the rules.
+type Strategy struct {
+	definition Definition
+
+	mtx       sync.Mutex
+	params    strategy.Params
+	program   *program
+	positions map[string]*position
+}
+
+// New creates a strategy from rules, the parameters not informed use the default values
+func New(definition Definition, params strategy.Params) (*Strategy, error) {
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Strategy{
+		definition: definition,
+		positions:  make(map[string]*position),
+	}
+	if err := s.SetParams(params); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name returns the name of the strategy in the rules
+func (s *Strategy) Name() string {
+	return s.definition.Name
+}
+
+func (s *Strategy) Parameters() []strategy.Param {
+	return s.definition.Params
+}
+
+func (s *Strategy) ParamValues() strategy.Params {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	values := make(strategy.Params, len(s.params))
+	for name, value := range s.params {
+		values[name] = value
+	}
+	return values
+}
+
+// SetParams validates the parameters and compiles the rules with the new values
+func (s *Strategy) SetParams(params strategy.Params) error {
+	values, err := strategy.ValidateParams(s.definition.Params, params)
+	if err != nil {
+		return err
+	}
+
+	prog, err := s.definition.compile(values)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.params = values
+	s.program = prog
+	return nil
+}
+
+func (s *Strategy) current() *program {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.program
+}
+
+func (s *Strategy) Timeframe() string {
+	return s.definition.Timeframe
+}
+
+func (s *Strategy) WarmupPeriod() int {
+	if s.definition.Warmup > 0 {
+		return s.definition.Warmup
+	}
+	return s.current().warmup
+}
+
+func (s *Strategy) Indicators(df *model.Dataframe) []strategy.ChartIndicator {
+	prog := s.current()
+	for _, indicator := range prog.indicators {
+		var source []float64
+		if indicator.spec.source {
+			source = operand{series: indicator.source}.values(df)
+		}
+
+		outputs := indicator.spec.compute(df, source, indicator.params)
+		df.Metadata[indicator.name] = outputs[0]
+		for i, output := range indicator.spec.outputs {
+			df.Metadata[indicator.name+"."+output] = outputs[i]
+		}
+	}
+
+	charts := make([]strategy.ChartIndicator, 0, len(s.definition.Chart))
+	for _, group := range s.definition.Chart {
+		chart := strategy.ChartIndicator{
+			Time:      df.Time,
+			Overlay:   group.Overlay,
+			GroupName: group.Group,
+			Warmup:    prog.warmup,
+		}
+		for _, metric := range group.Metrics {
+			name := metric.Name
+			if name == "" {
+				name = metric.Series
+			}
+			chart.Metrics = append(chart.Metrics, strategy.IndicatorMetric{
+				Name:   name,
+				Color:  metric.Color,
+				Style:  strategy.MetricStyle(metric.Style),
+				Values: operand{series: metric.Series}.values(df),
+			})
+		}
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+func (s *Strategy) OnCandle(df *model.Dataframe, broker service.Broker) {
+	prog := s.current()
+	closePrice := df.Close.Last(0)
+
+	assetPosition, quotePosition, err := broker.Position(df.Pair)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if assetPosition > 0 {
+		reason := s.exitReason(df, prog, closePrice)
+		if reason == "" {
+			return
+		}
+
+		log.Infof("[RULES] %s %s: closing position by %s", s.definition.Name, df.Pair, reason)
+		if _, err := broker.CreateOrderMarket(model.SideTypeSell, df.Pair, assetPosition); err != nil {
+			log.Error(err)
+			return
+		}
+		s.setPosition(df.Pair, nil)
+		return
+	}
+
+	// position closed out of the strategy, eg: by a manual order
+	s.setPosition(df.Pair, nil)
+
+	if !prog.entry.evaluate(df) {
+		return
+	}
+
+	var order model.Order
+	switch prog.sizing {
+	case SizingAmount:
+		order, err = broker.CreateOrderMarket(model.SideTypeBuy, df.Pair, prog.size)
+	case SizingQuote:
+		order, err = broker.CreateOrderMarketQuote(model.SideTypeBuy, df.Pair, math.Min(prog.size, quotePosition))
+	default:
+		order, err = broker.CreateOrderMarketQuote(model.SideTypeBuy, df.Pair, quotePosition*prog.size/100)
+	}
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	entry := order.Price
+	if entry <= 0 {
+		entry = closePrice
+	}
+	s.setPosition(df.Pair, &position{entry: entry, highest: entry})
+}
+
+// exitReason returns the stop or rule that closes the position, or empty if the position remains open
+func (s *Strategy) exitReason(df *model.Dataframe, prog *program, closePrice float64) string {
+	s.mtx.Lock()
+	current, ok := s.positions[df.Pair]
+	if !ok {
+		// position opened before the start of the bot, the stops are relative to the current price
+		current = &position{entry: closePrice, highest: closePrice}
+		s.positions[df.Pair] = current
+	}
+	current.highest = math.Max(current.highest, closePrice)
+	entry, highest := current.entry, current.highest
+	s.mtx.Unlock()
+
+	switch {
+	case prog.stopLoss > 0 && closePrice <= entry*(1-prog.stopLoss):
+		return "stop loss"
+	case prog.takeProfit > 0 && closePrice >= entry*(1+prog.takeProfit):
+		return "take profit"
+	case prog.trailing > 0 && closePrice <= highest*(1-prog.trailing):
+		return "trailing stop"
+	case prog.exit.evaluate(df):
+		return "exit rule"
+	}
+	return ""
+}
+
+func (s *Strategy) setPosition(pair string, value *position) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if value == nil {
+		delete(s.positions, pair)
+		return
+	}
+	s.positions[pair] = value
+}
@@ -0,0 +1,159 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/examples/strategies"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/testdata/mocks"
+	"github.com/rodrigo-brito/ninjabot/testdata/strategytest"
+)
+
+func TestStrategy_Backtest(t *testing.T) {
+	// same rules of strategies.CrossEMA
+	definition, err := Parse([]byte(`
+timeframe: 4h
+params:
+  - {name: ema, type: int, default: 8, min: 2, max: 50, step: 1}
+indicators:
+  - {name: ema, type: ema, params: {period: $ema}}
+  - {name: sma, type: sma, params: {period: 21}}
+entry:
+  all: [ema crossover sma]
+exit:
+  all: [ema crossunder sma]
+chart:
+  - group: MA's
+    overlay: true
+    metrics:
+      - {series: ema, color: red}
+      - {series: sma, name: SMA 21, color: blue}
+`))
+	require.NoError(t, err)
+
+	str, err := New(definition, nil)
+	require.NoError(t, err)
+	require.Equal(t, 22, str.WarmupPeriod())
+
+	// CrossEMA buys the amount of the quote balance at the close price, and skips the entries without funds
+	expected := strategytest.Backtest(t, &strategies.CrossEMA{}).Controller().Results["BTCUSDT"]
+	result := strategytest.Backtest(t, str).Controller().Results["BTCUSDT"]
+	require.NotZero(t, len(expected.Trades))
+	require.GreaterOrEqual(t, len(result.Trades), len(expected.Trades))
+
+	trades := make(map[time.Time]bool)
+	for _, trade := range result.Trades {
+		trades[trade.CreatedAt] = true
+	}
+	for _, trade := range expected.Trades {
+		require.True(t, trades[trade.CreatedAt], trade.CreatedAt)
+	}
+
+	t.Run("params", func(t *testing.T) {
+		require.Equal(t, strategy.Params{"ema": 8}, str.ParamValues())
+
+		require.NoError(t, str.SetParams(strategy.Params{"ema": 30}))
+		require.Equal(t, strategy.Params{"ema": 30}, str.ParamValues())
+		require.Equal(t, 31, str.WarmupPeriod())
+
+		require.ErrorIs(t, str.SetParams(strategy.Params{"ema": 100}), strategy.ErrInvalidParam)
+		require.Equal(t, strategy.Params{"ema": 30}, str.ParamValues())
+
+		_, err := New(definition, strategy.Params{"sma": 10})
+		require.ErrorIs(t, err, strategy.ErrInvalidParam)
+	})
+
+	t.Run("chart", func(t *testing.T) {
+		df := &model.Dataframe{Metadata: make(map[string]model.Series[float64])}
+		for i := 0; i < str.WarmupPeriod(); i++ {
+			df.Close = append(df.Close, float64(i+1))
+		}
+		charts := str.Indicators(df)
+		require.Len(t, charts, 1)
+		require.True(t, charts[0].Overlay)
+		require.Equal(t, "MA's", charts[0].GroupName)
+		require.Equal(t, "ema", charts[0].Metrics[0].Name)
+		require.Equal(t, "SMA 21", charts[0].Metrics[1].Name)
+		require.Len(t, charts[0].Metrics[1].Values, str.WarmupPeriod())
+	})
+}
+
+func TestStrategy_OnCandle(t *testing.T) {
+	definition, err := Parse([]byte(`
+timeframe: 1h
+entry:
+  all:
+    - close > close[1]
+  any: [close > 100, close < 10]
+exit:
+  any: [close < 50]
+sizing: {type: quote, value: 500}
+stops: {stop_loss: 0.1, take_profit: 0.5, trailing: 0.2}
+`))
+	require.NoError(t, err)
+
+	t.Run("entry", func(t *testing.T) {
+		str, err := New(definition, nil)
+		require.NoError(t, err)
+
+		broker := mocks.NewBroker(t)
+		broker.EXPECT().Position("BTCUSDT").Return(0, 300, nil)
+
+		// close not increasing, or between 10 and 100
+		str.OnCandle(strategytest.Dataframe(110, 105), broker)
+		str.OnCandle(strategytest.Dataframe(40, 50), broker)
+
+		broker.EXPECT().CreateOrderMarketQuote(model.SideTypeBuy, "BTCUSDT", 300.0).
+			Return(model.Order{Price: 101}, nil).Once()
+		str.OnCandle(strategytest.Dataframe(100, 102), broker)
+		require.Equal(t, &position{entry: 101, highest: 101}, str.positions["BTCUSDT"])
+	})
+
+	tt := []struct {
+		name   string
+		closes []float64
+		exit   bool
+	}{
+		{name: "hold", closes: []float64{95}},
+		{name: "stop loss", closes: []float64{89}, exit: true},
+		{name: "take profit", closes: []float64{151}, exit: true},
+		{name: "trailing stop", closes: []float64{140, 111}, exit: true},
+		{name: "trailing stop not reached", closes: []float64{140, 113}},
+		{name: "exit rule", closes: []float64{49}, exit: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			str, err := New(definition, nil)
+			require.NoError(t, err)
+			str.positions["BTCUSDT"] = &position{entry: 100, highest: 100}
+
+			broker := mocks.NewBroker(t)
+			broker.EXPECT().Position("BTCUSDT").Return(2, 0, nil)
+			if tc.exit {
+				broker.EXPECT().CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 2.0).
+					Return(model.Order{}, nil).Once()
+			}
+
+			for _, closePrice := range tc.closes {
+				str.OnCandle(strategytest.Dataframe(closePrice), broker)
+			}
+			require.Equal(t, !tc.exit, str.positions["BTCUSDT"] != nil)
+		})
+	}
+
+	t.Run("position opened out of the strategy", func(t *testing.T) {
+		str, err := New(definition, nil)
+		require.NoError(t, err)
+
+		broker := mocks.NewBroker(t)
+		broker.EXPECT().Position(mock.Anything).Return(1, 0, nil)
+		str.OnCandle(strategytest.Dataframe(80), broker)
+		require.Equal(t, &position{entry: 80, highest: 80}, str.positions["BTCUSDT"])
+	})
+}
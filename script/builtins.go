@@ -0,0 +1,342 @@
+package script
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/rodrigo-brito/ninjabot/indicator"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+)
+
+// thread locals of the script executions
+const (
+	localParams = "params"
+	localCharts = "charts"
+)
+
+type builtinFunc = func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error)
+
+// sourceFunc wraps indicators of a series with a period, eg: ta.ema(df.close, 8)
+func sourceFunc(fn func([]float64, int) []float64) builtinFunc {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+		kwargs []starlark.Tuple) (starlark.Value, error) {
+		var (
+			source starlark.Value
+			period int
+		)
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "source", &source, "period", &period); err != nil {
+			return nil, err
+		}
+
+		values, err := floats(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		if err := checkPeriod(b.Name(), len(values), period); err != nil {
+			return nil, err
+		}
+		return series(fn(values, period)), nil
+	}
+}
+
+// priceFunc wraps indicators of high, low and close with a period, eg: ta.atr(df.high, df.low, df.close, 14)
+func priceFunc(fn func(high, low, close []float64, period int) []float64) builtinFunc {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+		kwargs []starlark.Tuple) (starlark.Value, error) {
+		var (
+			high, low, close starlark.Value
+			period           int
+		)
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "high", &high, "low", &low, "close", &close,
+			"period", &period); err != nil {
+			return nil, err
+		}
+
+		inputs, err := floatsOf(b.Name(), high, low, close)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPeriod(b.Name(), len(inputs[2]), period); err != nil {
+			return nil, err
+		}
+		return series(fn(inputs[0], inputs[1], inputs[2], period)), nil
+	}
+}
+
+// floatsOf converts series with the same length
+func floatsOf(name string, values ...starlark.Value) ([][]float64, error) {
+	inputs := make([][]float64, 0, len(values))
+	for _, value := range values {
+		input, err := floats(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if len(inputs) > 0 && len(input) != len(inputs[0]) {
+			return nil, fmt.Errorf("%s: series with different lengths", name)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// checkPeriod avoids a panic of talib in series shorter than the period
+func checkPeriod(name string, size, period int) error {
+	if period < 1 {
+		return fmt.Errorf("%s: period must be positive, got %d", name, period)
+	}
+	if size < period {
+		return fmt.Errorf("%s: series with %d values, shorter than the period %d", name, size, period)
+	}
+	return nil
+}
+
+func bbands(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		source    starlark.Value
+		period    int
+		deviation number = 2
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "source", &source, "period", &period,
+		"deviation?", &deviation); err != nil {
+		return nil, err
+	}
+
+	values, err := floats(source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	if err := checkPeriod(b.Name(), len(values), period); err != nil {
+		return nil, err
+	}
+
+	upper, middle, lower := indicator.BB(values, period, float64(deviation), indicator.TypeSMA)
+	return starlark.Tuple{series(upper), series(middle), series(lower)}, nil
+}
+
+func macd(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		source             starlark.Value
+		fast, slow, signal = 12, 26, 9
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "source", &source, "fast?", &fast, "slow?", &slow,
+		"signal?", &signal); err != nil {
+		return nil, err
+	}
+
+	values, err := floats(source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	if err := checkPeriod(b.Name(), len(values), slow+signal); err != nil {
+		return nil, err
+	}
+
+	line, signalLine, hist := indicator.MACD(values, fast, slow, signal)
+	return starlark.Tuple{series(line), series(signalLine), series(hist)}, nil
+}
+
+func obv(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var source, volume starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "source", &source, "volume", &volume); err != nil {
+		return nil, err
+	}
+
+	inputs, err := floatsOf(b.Name(), source, volume)
+	if err != nil {
+		return nil, err
+	}
+	return series(indicator.OBV(inputs[0], inputs[1])), nil
+}
+
+func stddev(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		source    starlark.Value
+		period    int
+		deviation number = 1
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "source", &source, "period", &period,
+		"deviation?", &deviation); err != nil {
+		return nil, err
+	}
+
+	values, err := floats(source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	if err := checkPeriod(b.Name(), len(values), period); err != nil {
+		return nil, err
+	}
+	return series(indicator.StdDev(values, period, float64(deviation))), nil
+}
+
+// crossFunc compares the last two values of series or numbers, eg: ta.crossover(ema, sma)
+func crossFunc(cross func(a, b model.Series[float64]) bool) builtinFunc {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+		kwargs []starlark.Tuple) (starlark.Value, error) {
+		var x, y starlark.Value
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &x, &y); err != nil {
+			return nil, err
+		}
+
+		inputs := make([]model.Series[float64], 0, 2)
+		for _, value := range []starlark.Value{x, y} {
+			if constant, ok := starlark.AsFloat(value); ok {
+				inputs = append(inputs, model.Series[float64]{constant, constant})
+				continue
+			}
+
+			values, err := floats(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			if len(values) < 2 {
+				return nil, fmt.Errorf("%s: at least two values are required", b.Name())
+			}
+			inputs = append(inputs, values)
+		}
+		return starlark.Bool(cross(inputs[0], inputs[1])), nil
+	}
+}
+
+// ta is the module with the functions of the package indicator
+var ta = &starlarkstruct.Module{
+	Name: "ta",
+	Members: starlark.StringDict{
+		"sma":        starlark.NewBuiltin("sma", sourceFunc(indicator.SMA)),
+		"ema":        starlark.NewBuiltin("ema", sourceFunc(indicator.EMA)),
+		"wma":        starlark.NewBuiltin("wma", sourceFunc(indicator.WMA)),
+		"dema":       starlark.NewBuiltin("dema", sourceFunc(indicator.DEMA)),
+		"tema":       starlark.NewBuiltin("tema", sourceFunc(indicator.TEMA)),
+		"trima":      starlark.NewBuiltin("trima", sourceFunc(indicator.TRIMA)),
+		"kama":       starlark.NewBuiltin("kama", sourceFunc(indicator.KAMA)),
+		"max":        starlark.NewBuiltin("max", sourceFunc(indicator.Max)),
+		"min":        starlark.NewBuiltin("min", sourceFunc(indicator.Min)),
+		"sum":        starlark.NewBuiltin("sum", sourceFunc(indicator.Sum)),
+		"rsi":        starlark.NewBuiltin("rsi", sourceFunc(indicator.RSI)),
+		"cmo":        starlark.NewBuiltin("cmo", sourceFunc(indicator.CMO)),
+		"roc":        starlark.NewBuiltin("roc", sourceFunc(indicator.ROC)),
+		"momentum":   starlark.NewBuiltin("momentum", sourceFunc(indicator.Momentum)),
+		"atr":        starlark.NewBuiltin("atr", priceFunc(indicator.ATR)),
+		"natr":       starlark.NewBuiltin("natr", priceFunc(indicator.NATR)),
+		"adx":        starlark.NewBuiltin("adx", priceFunc(indicator.ADX)),
+		"cci":        starlark.NewBuiltin("cci", priceFunc(indicator.CCI)),
+		"willr":      starlark.NewBuiltin("willr", priceFunc(indicator.WilliamsR)),
+		"stddev":     starlark.NewBuiltin("stddev", stddev),
+		"bbands":     starlark.NewBuiltin("bbands", bbands),
+		"macd":       starlark.NewBuiltin("macd", macd),
+		"obv":        starlark.NewBuiltin("obv", obv),
+		"crossover":  starlark.NewBuiltin("crossover", crossFunc(model.Series[float64].Crossover)),
+		"crossunder": starlark.NewBuiltin("crossunder", crossFunc(model.Series[float64].Crossunder)),
+	},
+}
+
+// param returns the current value of a parameter declared in the script, eg: param("ema")
+func param(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &name); err != nil {
+		return nil, err
+	}
+
+	params, ok := thread.Local(localParams).(strategy.Params)
+	if !ok {
+		return nil, fmt.Errorf("%s: parameters are only available in functions", b.Name())
+	}
+
+	value, ok := params[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown parameter %q", b.Name(), name)
+	}
+	return toStarlark(value)
+}
+
+// plot adds a series to the chart of the strategy, it is only available in indicators(df)
+func plot(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		name, group, color string
+		values             starlark.Value
+		overlay            bool
+		style              = string(strategy.StyleLine)
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "values", &values, "group?", &group,
+		"color?", &color, "overlay?", &overlay, "style?", &style); err != nil {
+		return nil, err
+	}
+
+	charts, ok := thread.Local(localCharts).(*[]strategy.ChartIndicator)
+	if !ok {
+		return nil, fmt.Errorf("%s: only available in indicators(df)", b.Name())
+	}
+
+	switch strategy.MetricStyle(style) {
+	case strategy.StyleLine, strategy.StyleBar, strategy.StyleScatter, strategy.StyleHistogram,
+		strategy.StyleWaterfall:
+	default:
+		return nil, fmt.Errorf("%s: unknown style %q", b.Name(), style)
+	}
+
+	series, err := floats(values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+
+	if group == "" {
+		group = name
+	}
+	metric := strategy.IndicatorMetric{Name: name, Color: color, Style: strategy.MetricStyle(style), Values: series}
+	for i := range *charts {
+		if (*charts)[i].GroupName == group {
+			(*charts)[i].Metrics = append((*charts)[i].Metrics, metric)
+			return starlark.None, nil
+		}
+	}
+	*charts = append(*charts, strategy.ChartIndicator{
+		GroupName: group,
+		Overlay:   overlay,
+		Metrics:   []strategy.IndicatorMetric{metric},
+	})
+	return starlark.None, nil
+}
+
+// toStarlark converts a parameter value
+func toStarlark(value any) (starlark.Value, error) {
+	switch v := value.(type) {
+	case int:
+		return starlark.MakeInt(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	}
+	return nil, fmt.Errorf("unsupported value %v (%T)", value, value)
+}
+
+// fromStarlark converts the default value of a parameter declared in the script, with its type
+func fromStarlark(value starlark.Value) (any, strategy.ParamType, error) {
+	switch v := value.(type) {
+	case starlark.Int:
+		number, ok := v.Int64()
+		if !ok {
+			return nil, "", fmt.Errorf("integer %s out of range", v)
+		}
+		return int(number), strategy.ParamInt, nil
+	case starlark.Float:
+		return float64(v), strategy.ParamFloat, nil
+	case starlark.Bool:
+		return bool(v), strategy.ParamBool, nil
+	case starlark.String:
+		return string(v), strategy.ParamString, nil
+	}
+	return nil, "", fmt.Errorf("unsupported type %s", value.Type())
+}
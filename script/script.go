@@ -0,0 +1,445 @@
+// Package script executes strategies written in Starlark, a dialect of Python for embedded and sandboxed scripts.
+//
+// A script declares the globals:
+//
+//	timeframe = "1h"               # required
+//	warmup = 22                    # required, number of candles of the dataframe
+//	params = {"ema": 8}            # optional, default values of the parameters
+//
+//	def indicators(df):            # optional, called before on_candle
+//	    df.metadata["ema"] = ta.ema(df.close, param("ema"))
+//	    plot("ema", df.metadata["ema"], overlay=True, color="red")
+//
+//	def on_candle(df, broker, state):  # required, state is an optional dict kept between candles of the pair
+//	    if ta.crossover(df.metadata["ema"], df.close):
+//	        broker.buy(quote=100)
+//
+// Scripts have no access to files, network, clock or random values, so the executions are deterministic in
+// backtests. The functions are limited by execution steps, and optionally by time (see WithTimeout), and errors are
+// logged without stopping the bot.
+package script
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+const (
+	// DefaultMaxSteps is the default limit of execution steps of each function call
+	DefaultMaxSteps = 1_000_000
+	// DefaultTimeout is the recommended limit of time of each function call in paper and live modes
+	DefaultTimeout = time.Second
+)
+
+var ErrInvalidScript = errors.New("invalid script")
+
+// fileOptions are the Starlark dialect of scripts, without while loops, recursion and top-level statements
+var fileOptions = &syntax.FileOptions{}
+
+type Option func(*Strategy)
+
+// WithMaxSteps limits the execution steps of each function call, zero disables the limit.
+// Unlike the timeout, it is deterministic, but it does not count the time of the functions of the module ta.
+func WithMaxSteps(steps uint64) Option {
+	return func(s *Strategy) {
+		s.maxSteps = steps
+	}
+}
+
+// WithTimeout limits the time of each function call, disabled by default. The limit depends on the wall clock,
+// so it must not be used in backtests, which would not be deterministic, e.g. DefaultTimeout for live executions.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Strategy) {
+		s.timeout = timeout
+	}
+}
+
+// WithAutoReload reloads the script when the file changes, checked before each candle
+func WithAutoReload() Option {
+	return func(s *Strategy) {
+		s.autoReload = true
+	}
+}
+
+// program is a script executed, with its globals
+type program struct {
+	timeframe  string
+	warmup     int
+	params     []strategy.Param
+	indicators *starlark.Function
+	onCandle   *starlark.Function
+	modTime    time.Time
+}
+
+// Strategy is a strategy executed from a script.
+// It implements strategy.TunableStrategy, with the parameters declared in the script.
+type Strategy struct {
+	file       string
+	maxSteps   uint64
+	timeout    time.Duration
+	autoReload bool
+
+	mtx     sync.Mutex
+	program *program
+	params  strategy.Params
+	states  map[string]*starlark.Dict
+}
+
+// Load executes a script file, the parameters not informed use the default values.
+// The functions are limited only by DefaultMaxSteps, see WithTimeout for paper and live executions.
+func Load(file string, params strategy.Params, options ...Option) (*Strategy, error) {
+	s := &Strategy{
+		file:     file,
+		maxSteps: DefaultMaxSteps,
+		states:   make(map[string]*starlark.Dict),
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	prog, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := strategy.ValidateParams(prog.params, params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.program = prog
+	s.params = values
+	return s, nil
+}
+
+// load reads and executes the script file
+func (s *Strategy) load() (*program, error) {
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(s.file)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := s.newThread(nil)
+	stop := s.limit(thread)
+	globals, err := starlark.ExecFileOptions(fileOptions, thread, filepath.Base(s.file), content, predeclared)
+	stop()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidScript, err)
+	}
+
+	prog, err := compile(globals)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidScript, filepath.Base(s.file), err)
+	}
+	prog.modTime = info.ModTime()
+	return prog, nil
+}
+
+var predeclared = starlark.StringDict{
+	"ta":    ta,
+	"param": starlark.NewBuiltin("param", param),
+	"plot":  starlark.NewBuiltin("plot", plot),
+}
+
+// compile validates the globals declared by the script
+func compile(globals starlark.StringDict) (*program, error) {
+	prog := new(program)
+
+	timeframe, ok := globals["timeframe"].(starlark.String)
+	if !ok || timeframe == "" {
+		return nil, errors.New("timeframe must be a string, eg: timeframe = \"1h\"")
+	}
+	prog.timeframe = string(timeframe)
+
+	warmup, ok := globals["warmup"].(starlark.Int)
+	if !ok {
+		return nil, errors.New("warmup must be an integer, eg: warmup = 20")
+	}
+	value, ok := warmup.Int64()
+	if !ok || value < 1 {
+		return nil, fmt.Errorf("warmup must be positive, got %s", warmup)
+	}
+	prog.warmup = int(value)
+
+	if declared, ok := globals["params"]; ok {
+		params, ok := declared.(*starlark.Dict)
+		if !ok {
+			return nil, errors.New("params must be a dict, eg: params = {\"ema\": 8}")
+		}
+
+		for _, item := range params.Items() {
+			name, ok := starlark.AsString(item[0])
+			if !ok || name == "" {
+				return nil, fmt.Errorf("params: invalid name %s", item[0])
+			}
+
+			fallback, paramType, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, fmt.Errorf("params: %s: %s", name, err)
+			}
+			prog.params = append(prog.params, strategy.Param{Name: name, Type: paramType, Default: fallback})
+		}
+	}
+
+	for _, item := range []struct {
+		name     string
+		function **starlark.Function
+		required bool
+		params   []int
+	}{
+		{"on_candle", &prog.onCandle, true, []int{2, 3}},
+		{"indicators", &prog.indicators, false, []int{1}},
+	} {
+		value, ok := globals[item.name]
+		if !ok {
+			if item.required {
+				return nil, fmt.Errorf("function %s is required", item.name)
+			}
+			continue
+		}
+
+		function, ok := value.(*starlark.Function)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a function", item.name)
+		}
+
+		valid := false
+		for _, params := range item.params {
+			valid = valid || function.NumParams() == params
+		}
+		if !valid {
+			return nil, fmt.Errorf("%s has %d parameters, expected %v", item.name, function.NumParams(),
+				item.params)
+		}
+		*item.function = function
+	}
+
+	return prog, nil
+}
+
+// newThread creates a thread for a function call. The loads of modules are not allowed, and prints are logged.
+func (s *Strategy) newThread(params strategy.Params) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: s.Name(),
+		Print: func(_ *starlark.Thread, msg string) {
+			log.Infof("[SCRIPT] %s: %s", s.Name(), msg)
+		},
+	}
+	if params != nil {
+		thread.SetLocal(localParams, params)
+	}
+	return thread
+}
+
+// limit sets the limits of steps and time of the thread, the returned function stops the timer
+func (s *Strategy) limit(thread *starlark.Thread) func() {
+	if s.maxSteps > 0 {
+		thread.SetMaxExecutionSteps(s.maxSteps)
+	}
+
+	if s.timeout <= 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(s.timeout, func() {
+		thread.Cancel(fmt.Sprintf("timeout of %s", s.timeout))
+	})
+	return func() {
+		timer.Stop()
+	}
+}
+
+// call executes a function of the script with the limits of the strategy
+func (s *Strategy) call(thread *starlark.Thread, function *starlark.Function, args ...starlark.Value) (err error) {
+	stop := s.limit(thread)
+	defer stop()
+
+	// errors of the scripts must not stop the bot
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	_, err = starlark.Call(thread, function, args, nil)
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+// Reload executes the script file again, keeping the values of the parameters still declared.
+// The timeframe can not be changed, and the current script is kept in case of errors.
+func (s *Strategy) Reload() error {
+	prog, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if prog.timeframe != s.program.timeframe {
+		return fmt.Errorf("%w: timeframe can not be changed from %s to %s", ErrInvalidScript,
+			s.program.timeframe, prog.timeframe)
+	}
+
+	current := make(strategy.Params)
+	for _, param := range prog.params {
+		if value, ok := s.params[param.Name]; ok {
+			current[param.Name] = value
+		}
+	}
+
+	values, err := strategy.ValidateParams(prog.params, current)
+	if err != nil {
+		return err
+	}
+
+	s.program = prog
+	s.params = values
+	return nil
+}
+
+// reloadIfChanged reloads the script when the modification time of the file changes
+func (s *Strategy) reloadIfChanged() {
+	info, err := os.Stat(s.file)
+	if err != nil {
+		log.Errorf("[SCRIPT] %s: %s", s.Name(), err)
+		return
+	}
+
+	if info.ModTime().Equal(s.current().modTime) {
+		return
+	}
+
+	if err := s.Reload(); err != nil {
+		log.Errorf("[SCRIPT] %s: reload: %s", s.Name(), err)
+
+		// avoid a new reload until the next change of the file
+		s.mtx.Lock()
+		s.program.modTime = info.ModTime()
+		s.mtx.Unlock()
+		return
+	}
+	log.Infof("[SCRIPT] %s: reloaded", s.Name())
+}
+
+// Name returns the name of the script file, without extension
+func (s *Strategy) Name() string {
+	return strings.TrimSuffix(filepath.Base(s.file), filepath.Ext(s.file))
+}
+
+func (s *Strategy) Parameters() []strategy.Param {
+	return s.current().params
+}
+
+func (s *Strategy) ParamValues() strategy.Params {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	values := make(strategy.Params, len(s.params))
+	for name, value := range s.params {
+		values[name] = value
+	}
+	return values
+}
+
+// SetParams validates the parameters, the new values are used from the next candle
+func (s *Strategy) SetParams(params strategy.Params) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	values, err := strategy.ValidateParams(s.program.params, params)
+	if err != nil {
+		return err
+	}
+	s.params = values
+	return nil
+}
+
+func (s *Strategy) current() *program {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.program
+}
+
+func (s *Strategy) Timeframe() string {
+	return s.current().timeframe
+}
+
+func (s *Strategy) WarmupPeriod() int {
+	return s.current().warmup
+}
+
+func (s *Strategy) Indicators(df *model.Dataframe) []strategy.ChartIndicator {
+	if s.autoReload {
+		s.reloadIfChanged()
+	}
+
+	prog := s.current()
+	if prog.indicators == nil {
+		return nil
+	}
+
+	var charts []strategy.ChartIndicator
+	thread := s.newThread(s.ParamValues())
+	thread.SetLocal(localCharts, &charts)
+	if err := s.call(thread, prog.indicators, dataframe{df: df}); err != nil {
+		log.Errorf("[SCRIPT] %s %s: indicators: %s", s.Name(), df.Pair, err)
+		return nil
+	}
+
+	for i := range charts {
+		charts[i].Time = df.Time
+		charts[i].Warmup = prog.warmup
+	}
+	return charts
+}
+
+func (s *Strategy) OnCandle(df *model.Dataframe, broker service.Broker) {
+	if err := s.onCandle(df, broker); err != nil {
+		log.Errorf("[SCRIPT] %s %s: on_candle: %s", s.Name(), df.Pair, err)
+	}
+}
+
+func (s *Strategy) onCandle(df *model.Dataframe, broker service.Broker) error {
+	prog := s.current()
+	args := []starlark.Value{dataframe{df: df}, newBroker(df.Pair, broker)}
+	if prog.onCandle.NumParams() == 3 {
+		args = append(args, s.state(df.Pair))
+	}
+	return s.call(s.newThread(s.ParamValues()), prog.onCandle, args...)
+}
+
+// state returns the dict of a pair kept between the candles
+func (s *Strategy) state(pair string) *starlark.Dict {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	state, ok := s.states[pair]
+	if !ok {
+		state = starlark.NewDict(0)
+		s.states[pair] = state
+	}
+	return state
+}
@@ -0,0 +1,314 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/examples/strategies"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/testdata/mocks"
+	"github.com/rodrigo-brito/ninjabot/testdata/strategytest"
+)
+
+func writeScript(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "test.star")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func TestStrategy_Backtest(t *testing.T) {
+	str, err := Load("../examples/scripts/emacross.star", nil)
+	require.NoError(t, err)
+	require.Equal(t, "emacross", str.Name())
+	require.Equal(t, "4h", str.Timeframe())
+	require.Equal(t, 22, str.WarmupPeriod())
+
+	// the script has the same logic of strategies.CrossEMA
+	expected := strategytest.Backtest(t, &strategies.CrossEMA{}).Controller().Results["BTCUSDT"]
+	require.NotZero(t, len(expected.Trades))
+
+	// executions are deterministic
+	for i := 0; i < 2; i++ {
+		str, err := Load("../examples/scripts/emacross.star", nil)
+		require.NoError(t, err)
+
+		result := strategytest.Backtest(t, str).Controller().Results["BTCUSDT"]
+		require.Len(t, result.Trades, len(expected.Trades))
+		for j, trade := range result.Trades {
+			require.Equal(t, expected.Trades[j].CreatedAt, trade.CreatedAt)
+			require.Equal(t, expected.Trades[j].Side, trade.Side)
+			require.InDelta(t, expected.Trades[j].ProfitValue, trade.ProfitValue, 1e-6)
+		}
+	}
+
+	t.Run("params", func(t *testing.T) {
+		require.Equal(t, []strategy.Param{
+			{Name: "ema", Type: strategy.ParamInt, Default: 8},
+			{Name: "sma", Type: strategy.ParamInt, Default: 21},
+		}, str.Parameters())
+		require.Equal(t, strategy.Params{"ema": 8, "sma": 21}, str.ParamValues())
+
+		require.NoError(t, str.SetParams(strategy.Params{"ema": 9}))
+		require.Equal(t, strategy.Params{"ema": 9, "sma": 21}, str.ParamValues())
+
+		require.ErrorIs(t, str.SetParams(strategy.Params{"rsi": 14}), strategy.ErrInvalidParam)
+		require.ErrorIs(t, str.SetParams(strategy.Params{"ema": "fast"}), strategy.ErrInvalidParam)
+
+		_, err := Load("../examples/scripts/emacross.star", strategy.Params{"ema": 1.5})
+		require.ErrorIs(t, err, strategy.ErrInvalidParam)
+	})
+
+	t.Run("chart", func(t *testing.T) {
+		df := strategytest.Dataframe()
+		for i := 0; i < str.WarmupPeriod(); i++ {
+			df.Close = append(df.Close, float64(i+1))
+		}
+
+		charts := str.Indicators(df)
+		require.Len(t, charts, 1)
+		require.Equal(t, "MA's", charts[0].GroupName)
+		require.True(t, charts[0].Overlay)
+		require.Equal(t, 22, charts[0].Warmup)
+		require.Len(t, charts[0].Metrics, 2)
+		require.Equal(t, "EMA 9", charts[0].Metrics[0].Name)
+		require.Equal(t, "red", charts[0].Metrics[0].Color)
+		require.Equal(t, "SMA 21", charts[0].Metrics[1].Name)
+		require.Equal(t, df.Metadata["sma"], charts[0].Metrics[1].Values)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	tt := []struct {
+		name    string
+		content string
+	}{
+		{"syntax error", "timeframe = \n"},
+		{"without timeframe", "warmup = 1\ndef on_candle(df, broker):\n    pass\n"},
+		{"without warmup", "timeframe = \"1h\"\ndef on_candle(df, broker):\n    pass\n"},
+		{"invalid warmup", "timeframe = \"1h\"\nwarmup = 0\ndef on_candle(df, broker):\n    pass\n"},
+		{"without on_candle", "timeframe = \"1h\"\nwarmup = 1\n"},
+		{"invalid on_candle", "timeframe = \"1h\"\nwarmup = 1\non_candle = 1\n"},
+		{"on_candle parameters", "timeframe = \"1h\"\nwarmup = 1\ndef on_candle(df):\n    pass\n"},
+		{"invalid params", "timeframe = \"1h\"\nwarmup = 1\nparams = [1]\ndef on_candle(df, broker):\n    pass\n"},
+		{"param type", "timeframe = \"1h\"\nwarmup = 1\nparams = {\"x\": [1]}\ndef on_candle(df, broker):\n    pass\n"},
+		{"load", "load(\"module.star\", \"x\")\n"},
+		{"while", "def f():\n    while True:\n        pass\n"},
+		{"top-level statement", "for i in range(10):\n    pass\n"},
+		{"runtime error", "timeframe = \"1h\"\nwarmup = 1 // 0\n"},
+		{"param in top-level", "timeframe = \"1h\"\nwarmup = param(\"x\")\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(writeScript(t, tc.content), nil)
+			require.ErrorIs(t, err, ErrInvalidScript)
+		})
+	}
+
+	t.Run("file not found", func(t *testing.T) {
+		_, err := Load("not-found.star", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("infinite execution in top-level", func(t *testing.T) {
+		file := writeScript(t, "timeframe = \"1h\"\nwarmup = len([i for i in range(1000000000)])\n")
+		_, err := Load(file, nil, WithMaxSteps(1000))
+		require.ErrorIs(t, err, ErrInvalidScript)
+		require.ErrorContains(t, err, "too many steps")
+	})
+}
+
+func TestStrategy_OnCandle(t *testing.T) {
+	t.Run("broker and state", func(t *testing.T) {
+		str, err := Load(writeScript(t, `
+timeframe = "1h"
+warmup = 2
+params = {"size": 0.5}
+
+def on_candle(df, broker, state):
+    state["candles"] = state.get("candles", 0) + 1
+    asset, quote = broker.position()
+    if asset == 0 and df.close[-1] > df.close.last(1):
+        order = broker.buy(amount=param("size"))
+        state["entry"] = order["price"]
+    elif asset > 0 and df.close[-1] < state["entry"] * 0.9:
+        broker.sell()
+`), nil)
+		require.NoError(t, err)
+
+		broker := mocks.NewBroker(t)
+		broker.EXPECT().Position("BTCUSDT").Return(0, 1000, nil).Times(2)
+		broker.EXPECT().CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 0.5).
+			Return(model.Order{Side: model.SideTypeBuy, Price: 100}, nil).Once()
+
+		require.NoError(t, str.onCandle(strategytest.Dataframe(100, 99), broker))
+		require.NoError(t, str.onCandle(strategytest.Dataframe(99, 100), broker))
+
+		broker.EXPECT().Position("BTCUSDT").Return(0.5, 950, nil)
+		require.NoError(t, str.onCandle(strategytest.Dataframe(100, 95), broker))
+
+		broker.EXPECT().CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 0.5).Return(model.Order{}, nil).Once()
+		require.NoError(t, str.onCandle(strategytest.Dataframe(95, 89), broker))
+
+		// state by pair
+		require.Equal(t, `{"candles": 4, "entry": 100.0}`, str.state("BTCUSDT").String())
+		require.Equal(t, "{}", str.state("ETHUSDT").String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		str, err := Load(writeScript(t, `
+timeframe = "1h"
+warmup = 1
+
+def on_candle(df, broker):
+    if df.close[-1] > 100:
+        broker.buy(amount=1, quote=100)
+    elif df.close[-1] > 50:
+        broker.buy()
+    else:
+        df.close[-10]
+`), nil)
+		require.NoError(t, err)
+
+		broker := mocks.NewBroker(t)
+		require.ErrorContains(t, str.onCandle(strategytest.Dataframe(110), broker), "amount and quote are exclusive")
+		require.ErrorContains(t, str.onCandle(strategytest.Dataframe(60), broker), "amount or quote must be positive")
+		require.ErrorContains(t, str.onCandle(strategytest.Dataframe(10), broker), "index -10 out of range")
+
+		// errors are logged
+		str.OnCandle(strategytest.Dataframe(10), broker)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		content := `
+timeframe = "1h"
+warmup = 1
+
+def on_candle(df, broker):
+    for i in range(int(df.close[-1])):
+        pass
+    broker.position()
+`
+		str, err := Load(writeScript(t, content), nil, WithMaxSteps(10000), WithTimeout(0))
+		require.NoError(t, err)
+
+		broker := mocks.NewBroker(t)
+		broker.EXPECT().Position("BTCUSDT").Return(0, 0, nil).Once()
+		require.NoError(t, str.onCandle(strategytest.Dataframe(100), broker))
+		require.ErrorContains(t, str.onCandle(strategytest.Dataframe(1e9), broker), "too many steps")
+
+		// a new thread is created for each call
+		broker.EXPECT().Position("BTCUSDT").Return(0, 0, nil).Once()
+		require.NoError(t, str.onCandle(strategytest.Dataframe(100), broker))
+
+		// the timeout is disabled by default, backtests are deterministic
+		str, err = Load(writeScript(t, content), nil)
+		require.NoError(t, err)
+		require.Zero(t, str.timeout)
+
+		str, err = Load(writeScript(t, content), nil, WithMaxSteps(0), WithTimeout(10*time.Millisecond))
+		require.NoError(t, err)
+		require.ErrorContains(t, str.onCandle(strategytest.Dataframe(1e12), broker), "timeout of 10ms")
+	})
+
+	t.Run("indicators", func(t *testing.T) {
+		str, err := Load(writeScript(t, `
+timeframe = "1h"
+warmup = 3
+
+def indicators(df):
+    upper, middle, lower = ta.bbands(df.close, 3)
+    df.metadata["upper"] = upper
+    mean = 0.0
+    for value in df.close:
+        mean += value / len(df.close)
+    df.metadata["mean"] = [mean] * len(df.close)
+    plot("upper", upper, group="bands", overlay=True, style="scatter")
+    plot("lower", lower, group="bands")
+    plot("rsi", ta.rsi(df.close, 2))
+
+def on_candle(df, broker):
+    if ta.crossover(df.close, df.metadata["mean"]) or ta.crossunder(df.close, 100):
+        broker.sell()
+`), nil)
+		require.NoError(t, err)
+
+		df := strategytest.Dataframe(110, 90, 95, 120)
+		charts := str.Indicators(df)
+		require.Len(t, charts, 2)
+		require.Equal(t, "bands", charts[0].GroupName)
+		require.True(t, charts[0].Overlay)
+		require.Equal(t, strategy.MetricStyle(strategy.StyleScatter), charts[0].Metrics[0].Style)
+		require.Equal(t, "lower", charts[0].Metrics[1].Name)
+		require.Equal(t, "rsi", charts[1].GroupName)
+		require.False(t, charts[1].Overlay)
+		require.Len(t, df.Metadata["upper"], 4)
+		require.InDelta(t, 103.75, df.Metadata["mean"].Last(0), 1e-9)
+
+		broker := mocks.NewBroker(t)
+		broker.EXPECT().Position("BTCUSDT").Return(1, 0, nil).Once()
+		broker.EXPECT().CreateOrderMarket(model.SideTypeSell, "BTCUSDT", 1.0).Return(model.Order{}, nil).Once()
+		require.NoError(t, str.onCandle(df, broker))
+
+		// indicators with insufficient data are logged
+		require.Nil(t, str.Indicators(strategytest.Dataframe(110, 90)))
+	})
+}
+
+func TestStrategy_Reload(t *testing.T) {
+	file := writeScript(t, `
+timeframe = "1h"
+warmup = 1
+params = {"size": 1, "side": "buy"}
+
+def on_candle(df, broker):
+    broker.buy(amount=param("size"))
+`)
+
+	str, err := Load(file, strategy.Params{"size": 2}, WithAutoReload())
+	require.NoError(t, err)
+
+	broker := mocks.NewBroker(t)
+	broker.EXPECT().CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 2.0).Return(model.Order{}, nil).Once()
+	str.Indicators(strategytest.Dataframe(100))
+	str.OnCandle(strategytest.Dataframe(100), broker)
+
+	update := func(content string) {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+		modTime := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(file, modTime, modTime))
+	}
+
+	// the values of the parameters still declared are kept
+	update(`
+timeframe = "1h"
+warmup = 2
+params = {"size": 1, "multiplier": 3}
+
+def on_candle(df, broker):
+    broker.buy(amount=param("size") * param("multiplier"))
+`)
+	broker.EXPECT().CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 6.0).Return(model.Order{}, nil).Once()
+	str.Indicators(strategytest.Dataframe(100))
+	str.OnCandle(strategytest.Dataframe(100), broker)
+	require.Equal(t, 2, str.WarmupPeriod())
+	require.Equal(t, strategy.Params{"size": 2, "multiplier": 3}, str.ParamValues())
+
+	// invalid scripts are not loaded
+	update("timeframe = \"1h\"\nwarmup = 2\n")
+	broker.EXPECT().CreateOrderMarket(model.SideTypeBuy, "BTCUSDT", 6.0).Return(model.Order{}, nil).Once()
+	str.Indicators(strategytest.Dataframe(100))
+	str.OnCandle(strategytest.Dataframe(100), broker)
+
+	update("timeframe = \"4h\"\nwarmup = 2\ndef on_candle(df, broker):\n    pass\n")
+	require.ErrorIs(t, str.Reload(), ErrInvalidScript)
+	require.Equal(t, "1h", str.Timeframe())
+
+	broker.AssertNotCalled(t, "CreateOrderMarketQuote", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,362 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+)
+
+// series is a read-only sequence of floats, eg: df.close. Negative indexes are relative to the end, eg: close[-1].
+type series []float64
+
+var (
+	_ starlark.Indexable = series(nil)
+	_ starlark.Sliceable = series(nil)
+	_ starlark.Iterable  = series(nil)
+	_ starlark.HasAttrs  = series(nil)
+)
+
+func (s series) String() string {
+	values := make([]string, 0, len(s))
+	for _, value := range s {
+		values = append(values, starlark.Float(value).String())
+	}
+	return "series([" + strings.Join(values, ", ") + "])"
+}
+
+func (s series) Type() string               { return "series" }
+func (s series) Freeze()                    {}
+func (s series) Truth() starlark.Bool       { return len(s) > 0 }
+func (s series) Len() int                   { return len(s) }
+func (s series) Index(i int) starlark.Value { return starlark.Float(s[i]) }
+
+func (s series) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: series")
+}
+
+func (s series) Slice(start, end, step int) starlark.Value {
+	if step == 1 {
+		return s[start:end]
+	}
+
+	var values series
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		values = append(values, s[i])
+	}
+	return values
+}
+
+func (s series) Iterate() starlark.Iterator {
+	return &seriesIterator{values: s}
+}
+
+// Attr returns the methods of the series: last(n) is the n-th value from the end, eg: last(0) = close[-1]
+func (s series) Attr(name string) (starlark.Value, error) {
+	if name != "last" {
+		return nil, nil
+	}
+
+	return starlark.NewBuiltin("last", func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple,
+		kwargs []starlark.Tuple) (starlark.Value, error) {
+		var position int
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0, &position); err != nil {
+			return nil, err
+		}
+		if position < 0 || position >= len(s) {
+			return nil, fmt.Errorf("%s: position %d out of range [0:%d]", fn.Name(), position, len(s))
+		}
+		return starlark.Float(model.Series[float64](s).Last(position)), nil
+	}), nil
+}
+
+func (s series) AttrNames() []string {
+	return []string{"last"}
+}
+
+type seriesIterator struct {
+	values series
+	index  int
+}
+
+func (it *seriesIterator) Next(p *starlark.Value) bool {
+	if it.index >= len(it.values) {
+		return false
+	}
+	*p = starlark.Float(it.values[it.index])
+	it.index++
+	return true
+}
+
+func (it *seriesIterator) Done() {}
+
+// number is an argument of builtins that accepts integers and floats
+type number float64
+
+func (n *number) Unpack(value starlark.Value) error {
+	v, ok := starlark.AsFloat(value)
+	if !ok {
+		return fmt.Errorf("got %s, want number", value.Type())
+	}
+	*n = number(v)
+	return nil
+}
+
+// floats converts a series, or a list or tuple of numbers, to a slice of floats
+func floats(value starlark.Value) ([]float64, error) {
+	if values, ok := value.(series); ok {
+		return values, nil
+	}
+
+	iterable, ok := value.(starlark.Indexable)
+	if !ok {
+		return nil, fmt.Errorf("expected a series or a list of numbers, got %s", value.Type())
+	}
+
+	values := make([]float64, 0, iterable.Len())
+	for i := 0; i < iterable.Len(); i++ {
+		number, ok := starlark.AsFloat(iterable.Index(i))
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %s", iterable.Index(i).Type())
+		}
+		values = append(values, number)
+	}
+	return values, nil
+}
+
+// dataframe exposes the candles of a pair, with the metadata shared between the script functions
+type dataframe struct {
+	df *model.Dataframe
+}
+
+var _ starlark.HasAttrs = dataframe{}
+
+func (d dataframe) String() string        { return fmt.Sprintf("dataframe(%s)", d.df.Pair) }
+func (d dataframe) Type() string          { return "dataframe" }
+func (d dataframe) Freeze()               {}
+func (d dataframe) Truth() starlark.Bool  { return true }
+func (d dataframe) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: dataframe") }
+
+func (d dataframe) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "pair":
+		return starlark.String(d.df.Pair), nil
+	case "open":
+		return series(d.df.Open), nil
+	case "high":
+		return series(d.df.High), nil
+	case "low":
+		return series(d.df.Low), nil
+	case "close":
+		return series(d.df.Close), nil
+	case "volume":
+		return series(d.df.Volume), nil
+	case "time":
+		// unix timestamps of the candles, the current time is not available to keep the execution deterministic
+		times := make([]starlark.Value, 0, len(d.df.Time))
+		for _, t := range d.df.Time {
+			times = append(times, starlark.MakeInt64(t.Unix()))
+		}
+		return starlark.NewList(times), nil
+	case "metadata":
+		return metadata{df: d.df}, nil
+	}
+	return nil, nil
+}
+
+func (d dataframe) AttrNames() []string {
+	return []string{"close", "high", "low", "metadata", "open", "pair", "time", "volume"}
+}
+
+// metadata is the custom series of the dataframe, eg: df.metadata["ema"] = ta.ema(df.close, 8)
+type metadata struct {
+	df *model.Dataframe
+}
+
+var (
+	_ starlark.HasSetKey = metadata{}
+	_ starlark.HasAttrs  = metadata{}
+)
+
+func (m metadata) String() string        { return "metadata" }
+func (m metadata) Type() string          { return "metadata" }
+func (m metadata) Freeze()               {}
+func (m metadata) Truth() starlark.Bool  { return len(m.df.Metadata) > 0 }
+func (m metadata) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: metadata") }
+
+func (m metadata) Get(key starlark.Value) (starlark.Value, bool, error) {
+	name, ok := starlark.AsString(key)
+	if !ok {
+		return nil, false, fmt.Errorf("metadata: expected a string key, got %s", key.Type())
+	}
+
+	values, ok := m.df.Metadata[name]
+	if !ok {
+		return nil, false, nil
+	}
+	return series(values), true, nil
+}
+
+func (m metadata) SetKey(key, value starlark.Value) error {
+	name, ok := starlark.AsString(key)
+	if !ok {
+		return fmt.Errorf("metadata: expected a string key, got %s", key.Type())
+	}
+
+	values, err := floats(value)
+	if err != nil {
+		return fmt.Errorf("metadata[%q]: %w", name, err)
+	}
+
+	if m.df.Metadata == nil {
+		m.df.Metadata = make(map[string]model.Series[float64])
+	}
+	m.df.Metadata[name] = values
+	return nil
+}
+
+// Attr returns the method keys(), with the names of the series sorted
+func (m metadata) Attr(name string) (starlark.Value, error) {
+	if name != "keys" {
+		return nil, nil
+	}
+
+	return starlark.NewBuiltin("keys", func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple,
+		kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0); err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(m.df.Metadata))
+		for key := range m.df.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		values := make([]starlark.Value, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, starlark.String(key))
+		}
+		return starlark.NewList(values), nil
+	}), nil
+}
+
+func (m metadata) AttrNames() []string {
+	return []string{"keys"}
+}
+
+// broker is a restricted service.Broker, with market orders and position of the pair of the dataframe
+type broker struct {
+	pair   string
+	broker service.Broker
+}
+
+var _ starlark.HasAttrs = broker{}
+
+func newBroker(pair string, b service.Broker) broker {
+	return broker{pair: pair, broker: b}
+}
+
+func (b broker) String() string        { return fmt.Sprintf("broker(%s)", b.pair) }
+func (b broker) Type() string          { return "broker" }
+func (b broker) Freeze()               {}
+func (b broker) Truth() starlark.Bool  { return true }
+func (b broker) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: broker") }
+
+func (b broker) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "position":
+		return starlark.NewBuiltin(name, b.position), nil
+	case "buy":
+		return starlark.NewBuiltin(name, b.buy), nil
+	case "sell":
+		return starlark.NewBuiltin(name, b.sell), nil
+	}
+	return nil, nil
+}
+
+func (b broker) AttrNames() []string {
+	return []string{"buy", "position", "sell"}
+}
+
+// position returns the tuple (asset, quote) of the pair
+func (b broker) position(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+
+	asset, quote, err := b.broker.Position(b.pair)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Tuple{starlark.Float(asset), starlark.Float(quote)}, nil
+}
+
+// buy creates a market order with the amount of the asset or the value in quote, eg: buy(quote=100)
+func (b broker) buy(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var amount, quote number
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "amount?", &amount, "quote?", &quote); err != nil {
+		return nil, err
+	}
+
+	var (
+		order model.Order
+		err   error
+	)
+	switch {
+	case amount > 0 && quote > 0:
+		return nil, fmt.Errorf("%s: amount and quote are exclusive", fn.Name())
+	case amount > 0:
+		order, err = b.broker.CreateOrderMarket(model.SideTypeBuy, b.pair, float64(amount))
+	case quote > 0:
+		order, err = b.broker.CreateOrderMarketQuote(model.SideTypeBuy, b.pair, float64(quote))
+	default:
+		return nil, fmt.Errorf("%s: amount or quote must be positive", fn.Name())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return orderValue(order), nil
+}
+
+// sell creates a market order with the amount of the asset, default: the whole position
+func (b broker) sell(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var amount number
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "amount?", &amount); err != nil {
+		return nil, err
+	}
+
+	if amount <= 0 {
+		asset, _, err := b.broker.Position(b.pair)
+		if err != nil {
+			return nil, err
+		}
+		if asset <= 0 {
+			return nil, fmt.Errorf("%s: no position in %s", fn.Name(), b.pair)
+		}
+		amount = number(asset)
+	}
+
+	order, err := b.broker.CreateOrderMarket(model.SideTypeSell, b.pair, float64(amount))
+	if err != nil {
+		return nil, err
+	}
+	return orderValue(order), nil
+}
+
+// orderValue returns the fields of an order used by scripts
+func orderValue(order model.Order) starlark.Value {
+	dict := starlark.NewDict(4)
+	_ = dict.SetKey(starlark.String("side"), starlark.String(order.Side))
+	_ = dict.SetKey(starlark.String("price"), starlark.Float(order.Price))
+	_ = dict.SetKey(starlark.String("quantity"), starlark.Float(order.Quantity))
+	_ = dict.SetKey(starlark.String("status"), starlark.String(order.Status))
+	return dict
+}
@@ -22,6 +22,20 @@ type Feeder interface {
 	CandlesSubscription(ctx context.Context, pair, timeframe string) (chan model.Candle, chan error)
 }
 
+// OrderBookFeeder is an optional capability of a Feeder, that provides depth snapshots of the order book
+// and streams the diffs of the following updates
+type OrderBookFeeder interface {
+	OrderBook(ctx context.Context, pair string, depth int) (model.OrderBook, error)
+	OrderBookSubscription(ctx context.Context, pair string) (chan model.OrderBook, chan error)
+}
+
+// TradeFeeder is an optional capability of a Feeder, that provides the recent aggregated trades of a pair
+// and streams the new trades
+type TradeFeeder interface {
+	Trades(ctx context.Context, pair string, limit int) ([]model.TradeTick, error)
+	TradeSubscription(ctx context.Context, pair string) (chan model.TradeTick, chan error)
+}
+
 type Broker interface {
 	Account() (model.Account, error)
 	Position(pair string) (asset, quote float64, err error)
@@ -34,6 +48,48 @@ type Broker interface {
 	Cancel(model.Order) error
 }
 
+// OrderStreamer is an optional capability of a Broker, that pushes the updates of the orders
+// (e.g. execution reports) as soon as they happen, instead of requiring the polling of each order
+type OrderStreamer interface {
+	OrderSubscription(ctx context.Context) (chan model.Order, chan error)
+}
+
+// OrderPuller is an optional capability of a Broker, that queues the updates of the orders to be consumed
+// synchronously, e.g. by backtests after each candle, making the execution deterministic
+type OrderPuller interface {
+	OrderUpdates() []model.Order
+}
+
+// OrderLister is an optional capability of a Broker, that lists the most recent orders of a pair
+type OrderLister interface {
+	Orders(pair string, limit int) ([]model.Order, error)
+}
+
+// PendingOrderLister is an optional capability of a Broker, that lists the orders of a pair not executed yet
+type PendingOrderLister interface {
+	PendingOrders(pair string) ([]model.Order, error)
+}
+
+// BracketBroker is an optional capability of a Broker, that creates an entry order with attached take-profit
+// and stop-loss exits, submitted when the entry is filled. A price equal to zero creates a market entry.
+type BracketBroker interface {
+	CreateOrderBracket(side model.SideType, pair string, size, price, takeProfit,
+		stopLoss float64) (model.Bracket, error)
+}
+
+// TrailingStopper is an optional capability of an Exchange, that executes trailing stop orders: market orders
+// triggered when the price moves a distance against the best price since the creation of the order
+type TrailingStopper interface {
+	CreateOrderTrailingStop(side model.SideType, pair string, size, distance float64) (model.Order, error)
+}
+
+// TrailingStopBroker is an optional capability of a Broker, that manages trailing stops. The stop follows the
+// price at the given distance, and the side is the side of the exit order.
+type TrailingStopBroker interface {
+	CreateTrailingStop(side model.SideType, pair string, size, distance float64) (model.TrailingStop, error)
+	CancelTrailingStop(stop model.TrailingStop) error
+}
+
 type Notifier interface {
 	Notify(string)
 	OnOrder(order model.Order)
@@ -44,3 +100,10 @@ type Telegram interface {
 	Notifier
 	Start()
 }
+
+// ParamsController reads and changes the parameters of the running strategies, by strategy name
+type ParamsController interface {
+	StrategyParams(name string) (map[string]any, error)
+	// SetStrategyParams applies new values to some parameters of a strategy, and returns all values
+	SetStrategyParams(name string, params map[string]any) (map[string]any, error)
+}
@@ -3,7 +3,9 @@ package storage
 import (
 	"encoding/json"
 	"log"
+	"sort"
 	"strconv"
+	"strings"
 	"sync/atomic"
 
 	"github.com/tidwall/buntdb"
@@ -11,6 +13,15 @@ import (
 	"github.com/rodrigo-brito/ninjabot/model"
 )
 
+const (
+	positionPrefix = "position:"
+	tradePrefix    = "trade:"
+	equityPrefix   = "equity:"
+	bracketPrefix  = "bracket:"
+	trailingPrefix = "trailing:"
+	paramsPrefix   = "params:"
+)
+
 type Bunt struct {
 	lastID int64
 	db     *buntdb.DB
@@ -35,46 +46,71 @@ func newBunt(sourceFile string) (Storage, error) {
 		return nil, err
 	}
 
-	return &Bunt{
+	err = db.CreateIndex("trade_index", tradePrefix+"*", buntdb.IndexJSON("created_at"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.CreateIndex("equity_index", equityPrefix+"*", buntdb.IndexJSON("time"))
+	if err != nil {
+		return nil, err
+	}
+
+	bunt := &Bunt{
 		db: db,
-	}, nil
+	}
+
+	// continue the IDs of an existing database
+	err = db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys("*", func(key, _ string) bool {
+			id, err := strconv.ParseInt(key[strings.LastIndex(key, ":")+1:], 10, 64)
+			if err == nil && id > bunt.lastID {
+				bunt.lastID = id
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bunt, nil
 }
 
 func (b *Bunt) getID() int64 {
 	return atomic.AddInt64(&b.lastID, 1)
 }
 
-func (b *Bunt) CreateOrder(order *model.Order) error {
+func (b *Bunt) set(key string, value interface{}) error {
 	return b.db.Update(func(tx *buntdb.Tx) error {
-		order.ID = b.getID()
-		content, err := json.Marshal(order)
+		content, err := json.Marshal(value)
 		if err != nil {
 			return err
 		}
 
-		_, _, err = tx.Set(strconv.FormatInt(order.ID, 10), string(content), nil)
+		_, _, err = tx.Set(key, string(content), nil)
 		return err
 	})
 }
 
-func (b Bunt) UpdateOrder(order *model.Order) error {
-	return b.db.Update(func(tx *buntdb.Tx) error {
-		id := strconv.FormatInt(order.ID, 10)
-
-		content, err := json.Marshal(order)
-		if err != nil {
-			return err
-		}
+func (b *Bunt) CreateOrder(order *model.Order) error {
+	order.ID = b.getID()
+	return b.set(strconv.FormatInt(order.ID, 10), order)
+}
 
-		_, _, err = tx.Set(id, string(content), nil)
-		return err
-	})
+func (b Bunt) UpdateOrder(order *model.Order) error {
+	return b.set(strconv.FormatInt(order.ID, 10), order)
 }
 
 func (b Bunt) Orders(filters ...OrderFilter) ([]*model.Order, error) {
 	orders := make([]*model.Order, 0)
 	err := b.db.View(func(tx *buntdb.Tx) error {
-		err := tx.Ascend("update_index", func(_, value string) bool {
+		err := tx.Ascend("update_index", func(key, value string) bool {
+			// positions, trades, equity snapshots, brackets and trailing stops are stored with a prefix
+			if strings.Contains(key, ":") {
+				return true
+			}
+
 			var order model.Order
 			err := json.Unmarshal([]byte(value), &order)
 			if err != nil {
@@ -99,3 +135,171 @@ func (b Bunt) Orders(filters ...OrderFilter) ([]*model.Order, error) {
 	}
 	return orders, nil
 }
+
+func (b *Bunt) SavePosition(position *model.Position) error {
+	return b.set(positionPrefix+position.Strategy+"--"+position.Pair, position)
+}
+
+func (b *Bunt) DeletePosition(strategy, pair string) error {
+	return b.db.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(positionPrefix + strategy + "--" + pair)
+		if err == buntdb.ErrNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
+func (b *Bunt) Positions() ([]*model.Position, error) {
+	positions := make([]*model.Position, 0)
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys(positionPrefix+"*", func(_, value string) bool {
+			var position model.Position
+			if err := json.Unmarshal([]byte(value), &position); err != nil {
+				log.Println(err)
+				return true
+			}
+			positions = append(positions, &position)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+func (b *Bunt) CreateTrade(trade *model.Trade) error {
+	trade.ID = b.getID()
+	return b.set(tradePrefix+strconv.FormatInt(trade.ID, 10), trade)
+}
+
+func (b *Bunt) Trades() ([]*model.Trade, error) {
+	trades := make([]*model.Trade, 0)
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("trade_index", func(_, value string) bool {
+			var trade model.Trade
+			if err := json.Unmarshal([]byte(value), &trade); err != nil {
+				log.Println(err)
+				return true
+			}
+			trades = append(trades, &trade)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+func (b *Bunt) CreateEquitySnapshot(snapshot *model.EquitySnapshot) error {
+	snapshot.ID = b.getID()
+	return b.set(equityPrefix+strconv.FormatInt(snapshot.ID, 10), snapshot)
+}
+
+func (b *Bunt) EquitySnapshots() ([]*model.EquitySnapshot, error) {
+	snapshots := make([]*model.EquitySnapshot, 0)
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("equity_index", func(_, value string) bool {
+			var snapshot model.EquitySnapshot
+			if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+				log.Println(err)
+				return true
+			}
+			snapshots = append(snapshots, &snapshot)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func (b *Bunt) SaveBracket(bracket *model.Bracket) error {
+	if bracket.ID == 0 {
+		bracket.ID = b.getID()
+	}
+	return b.set(bracketPrefix+strconv.FormatInt(bracket.ID, 10), bracket)
+}
+
+func (b *Bunt) Brackets() ([]*model.Bracket, error) {
+	brackets := make([]*model.Bracket, 0)
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys(bracketPrefix+"*", func(_, value string) bool {
+			var bracket model.Bracket
+			if err := json.Unmarshal([]byte(value), &bracket); err != nil {
+				log.Println(err)
+				return true
+			}
+			brackets = append(brackets, &bracket)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(brackets, func(i, j int) bool {
+		return brackets[i].ID < brackets[j].ID
+	})
+	return brackets, nil
+}
+
+func (b *Bunt) SaveTrailingStop(stop *model.TrailingStop) error {
+	if stop.ID == 0 {
+		stop.ID = b.getID()
+	}
+	return b.set(trailingPrefix+strconv.FormatInt(stop.ID, 10), stop)
+}
+
+func (b *Bunt) TrailingStops() ([]*model.TrailingStop, error) {
+	stops := make([]*model.TrailingStop, 0)
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys(trailingPrefix+"*", func(_, value string) bool {
+			var stop model.TrailingStop
+			if err := json.Unmarshal([]byte(value), &stop); err != nil {
+				log.Println(err)
+				return true
+			}
+			stops = append(stops, &stop)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(stops, func(i, j int) bool {
+		return stops[i].ID < stops[j].ID
+	})
+	return stops, nil
+}
+
+func (b *Bunt) SaveStrategyParams(params *model.StrategyParams) error {
+	return b.set(paramsPrefix+params.Strategy, params)
+}
+
+func (b *Bunt) StrategyParams() ([]*model.StrategyParams, error) {
+	params := make([]*model.StrategyParams, 0)
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys(paramsPrefix+"*", func(_, value string) bool {
+			var strategyParams model.StrategyParams
+			if err := json.Unmarshal([]byte(value), &strategyParams); err != nil {
+				log.Println(err)
+				return true
+			}
+			params = append(params, &strategyParams)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Strategy < params[j].Strategy
+	})
+	return params, nil
+}
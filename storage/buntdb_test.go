@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
 )
 
 func TestFromFile(t *testing.T) {
@@ -16,6 +18,22 @@ func TestFromFile(t *testing.T) {
 	db, err := FromFile(file.Name())
 	require.NoError(t, err)
 	require.NotNil(t, db)
+
+	order := &model.Order{Pair: "BTCUSDT"}
+	require.NoError(t, db.CreateOrder(order))
+	require.NoError(t, db.CreateTrade(&model.Trade{Pair: "BTCUSDT"}))
+	require.NoError(t, db.(*Bunt).db.Close())
+
+	// IDs continue after reopening the file
+	db, err = FromFile(file.Name())
+	require.NoError(t, err)
+	next := &model.Order{Pair: "ETHUSDT"}
+	require.NoError(t, db.CreateOrder(next))
+	require.Equal(t, int64(3), next.ID)
+
+	orders, err := db.Orders()
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
 }
 
 func TestNewBunt(t *testing.T) {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/samber/lo"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/rodrigo-brito/ninjabot/model"
 )
@@ -35,7 +36,8 @@ func FromSQL(dialect gorm.Dialector, opts ...gorm.Option) (Storage, error) {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	err = db.AutoMigrate(&model.Order{})
+	err = db.AutoMigrate(&model.Order{}, &model.Position{}, &model.Trade{}, &model.EquitySnapshot{},
+		&model.Bracket{}, &model.TrailingStop{}, &model.StrategyParams{})
 	if err != nil {
 		return nil, err
 	}
@@ -78,3 +80,105 @@ func (s *SQL) Orders(filters ...OrderFilter) ([]*model.Order, error) {
 		return true
 	}), nil
 }
+
+// SavePosition creates or updates the position of a strategy in a pair
+func (s *SQL) SavePosition(position *model.Position) error {
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(position)
+	return result.Error
+}
+
+// DeletePosition removes the position of a strategy in a pair
+func (s *SQL) DeletePosition(strategy, pair string) error {
+	result := s.db.Where("strategy = ? AND pair = ?", strategy, pair).Delete(&model.Position{})
+	return result.Error
+}
+
+// Positions returns the open positions
+func (s *SQL) Positions() ([]*model.Position, error) {
+	positions := make([]*model.Position, 0)
+	result := s.db.Find(&positions)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return positions, nil
+}
+
+// CreateTrade registers a closed trade
+func (s *SQL) CreateTrade(trade *model.Trade) error {
+	result := s.db.Create(trade)
+	return result.Error
+}
+
+// Trades returns the closed trades, ordered by creation time
+func (s *SQL) Trades() ([]*model.Trade, error) {
+	trades := make([]*model.Trade, 0)
+	result := s.db.Order("created_at").Find(&trades)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return trades, nil
+}
+
+// CreateEquitySnapshot registers the account equity
+func (s *SQL) CreateEquitySnapshot(snapshot *model.EquitySnapshot) error {
+	result := s.db.Create(snapshot)
+	return result.Error
+}
+
+// EquitySnapshots returns the equity snapshots, ordered by time
+func (s *SQL) EquitySnapshots() ([]*model.EquitySnapshot, error) {
+	snapshots := make([]*model.EquitySnapshot, 0)
+	result := s.db.Order("time").Find(&snapshots)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return snapshots, nil
+}
+
+// SaveBracket creates or updates a bracket order
+func (s *SQL) SaveBracket(bracket *model.Bracket) error {
+	result := s.db.Save(bracket)
+	return result.Error
+}
+
+// Brackets returns the bracket orders, ordered by ID
+func (s *SQL) Brackets() ([]*model.Bracket, error) {
+	brackets := make([]*model.Bracket, 0)
+	result := s.db.Order("id").Find(&brackets)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return brackets, nil
+}
+
+// SaveTrailingStop creates or updates a trailing stop
+func (s *SQL) SaveTrailingStop(stop *model.TrailingStop) error {
+	result := s.db.Save(stop)
+	return result.Error
+}
+
+// TrailingStops returns the trailing stops, ordered by ID
+func (s *SQL) TrailingStops() ([]*model.TrailingStop, error) {
+	stops := make([]*model.TrailingStop, 0)
+	result := s.db.Order("id").Find(&stops)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return stops, nil
+}
+
+// SaveStrategyParams creates or updates the parameters of a strategy
+func (s *SQL) SaveStrategyParams(params *model.StrategyParams) error {
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(params)
+	return result.Error
+}
+
+// StrategyParams returns the parameters of the strategies, ordered by strategy name
+func (s *SQL) StrategyParams() ([]*model.StrategyParams, error) {
+	params := make([]*model.StrategyParams, 0)
+	result := s.db.Order("strategy").Find(&params)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return params, nil
+}
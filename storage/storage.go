@@ -12,6 +12,31 @@ type Storage interface {
 	CreateOrder(order *model.Order) error
 	UpdateOrder(order *model.Order) error
 	Orders(filters ...OrderFilter) ([]*model.Order, error)
+
+	// SavePosition creates or updates the position of a strategy in a pair
+	SavePosition(position *model.Position) error
+	DeletePosition(strategy, pair string) error
+	Positions() ([]*model.Position, error)
+
+	// CreateTrade registers a closed trade, trades are listed by creation time
+	CreateTrade(trade *model.Trade) error
+	Trades() ([]*model.Trade, error)
+
+	// CreateEquitySnapshot registers the account equity, snapshots are listed by time
+	CreateEquitySnapshot(snapshot *model.EquitySnapshot) error
+	EquitySnapshots() ([]*model.EquitySnapshot, error)
+
+	// SaveBracket creates or updates a bracket order, brackets without ID are created
+	SaveBracket(bracket *model.Bracket) error
+	Brackets() ([]*model.Bracket, error)
+
+	// SaveTrailingStop creates or updates a trailing stop, trailing stops without ID are created
+	SaveTrailingStop(stop *model.TrailingStop) error
+	TrailingStops() ([]*model.TrailingStop, error)
+
+	// SaveStrategyParams creates or updates the parameters of a strategy
+	SaveStrategyParams(params *model.StrategyParams) error
+	StrategyParams() ([]*model.StrategyParams, error)
 }
 
 func WithStatusIn(status ...model.OrderStatusType) OrderFilter {
@@ -37,6 +62,24 @@ func WithPair(pair string) OrderFilter {
 	}
 }
 
+func WithExchangeID(id int64) OrderFilter {
+	return func(order model.Order) bool {
+		return order.ExchangeID == id
+	}
+}
+
+func WithID(id int64) OrderFilter {
+	return func(order model.Order) bool {
+		return order.ID == id
+	}
+}
+
+func WithStrategy(strategy string) OrderFilter {
+	return func(order model.Order) bool {
+		return order.Strategy == strategy
+	}
+}
+
 func WithUpdateAtBeforeOrEqual(time time.Time) OrderFilter {
 	return func(order model.Order) bool {
 		return !order.UpdatedAt.After(time)
@@ -81,4 +81,160 @@ func storageUseCase(repo Storage, t *testing.T) {
 		require.Equal(t, firstOrder.Price, orders[0].Price)
 		require.Equal(t, firstOrder.Quantity, orders[0].Quantity)
 	})
+
+	t.Run("positions", func(t *testing.T) {
+		position := &model.Position{
+			Strategy:  "ema",
+			Pair:      "BTCUSDT",
+			Side:      model.SideTypeBuy,
+			AvgPrice:  10,
+			Quantity:  1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, repo.SavePosition(position))
+		require.NoError(t, repo.SavePosition(&model.Position{Pair: "ETHUSDT", Quantity: 2}))
+
+		position.Quantity = 3
+		require.NoError(t, repo.SavePosition(position))
+
+		positions, err := repo.Positions()
+		require.NoError(t, err)
+		require.Len(t, positions, 2)
+		for _, p := range positions {
+			if p.Strategy == "ema" {
+				require.Equal(t, 3.0, p.Quantity)
+				require.Equal(t, "BTCUSDT", p.Pair)
+			}
+		}
+
+		require.NoError(t, repo.DeletePosition("ema", "BTCUSDT"))
+		require.NoError(t, repo.DeletePosition("ema", "BTCUSDT"))
+		positions, err = repo.Positions()
+		require.NoError(t, err)
+		require.Len(t, positions, 1)
+		require.Equal(t, "ETHUSDT", positions[0].Pair)
+
+		// positions are not listed as orders
+		orders, err := repo.Orders()
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+	})
+
+	t.Run("trades", func(t *testing.T) {
+		require.NoError(t, repo.CreateTrade(&model.Trade{Pair: "ETHUSDT", ProfitValue: 2, CreatedAt: now}))
+		require.NoError(t, repo.CreateTrade(&model.Trade{
+			Pair:          "BTCUSDT",
+			Side:          model.SideTypeBuy,
+			ProfitPercent: 0.1,
+			ProfitValue:   1,
+			Duration:      time.Hour,
+			CreatedAt:     now.Add(-time.Hour),
+		}))
+
+		trades, err := repo.Trades()
+		require.NoError(t, err)
+		require.Len(t, trades, 2)
+		require.Equal(t, "BTCUSDT", trades[0].Pair)
+		require.Equal(t, time.Hour, trades[0].Duration)
+		require.Equal(t, "ETHUSDT", trades[1].Pair)
+		require.NotEqual(t, trades[0].ID, trades[1].ID)
+	})
+
+	t.Run("equity snapshots", func(t *testing.T) {
+		require.NoError(t, repo.CreateEquitySnapshot(&model.EquitySnapshot{Asset: "USDT", Value: 20, Time: now}))
+		require.NoError(t, repo.CreateEquitySnapshot(&model.EquitySnapshot{
+			Asset: "USDT",
+			Value: 10,
+			Time:  now.Add(-time.Hour),
+		}))
+
+		snapshots, err := repo.EquitySnapshots()
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+		require.Equal(t, 10.0, snapshots[0].Value)
+		require.Equal(t, 20.0, snapshots[1].Value)
+	})
+
+	t.Run("brackets", func(t *testing.T) {
+		bracket := &model.Bracket{
+			Pair:       "BTCUSDT",
+			Side:       model.SideTypeBuy,
+			Quantity:   1,
+			TakeProfit: 12,
+			StopLoss:   8,
+			Status:     model.BracketStatusPending,
+			EntryID:    firstOrder.ID,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		require.NoError(t, repo.SaveBracket(bracket))
+		require.NotZero(t, bracket.ID)
+
+		bracket.Status = model.BracketStatusActive
+		bracket.TakeProfitID = secondOrder.ID
+		require.NoError(t, repo.SaveBracket(bracket))
+		require.NoError(t, repo.SaveBracket(&model.Bracket{Pair: "ETHUSDT", Status: model.BracketStatusPending}))
+
+		brackets, err := repo.Brackets()
+		require.NoError(t, err)
+		require.Len(t, brackets, 2)
+		require.Equal(t, bracket.ID, brackets[0].ID)
+		require.Equal(t, model.BracketStatusActive, brackets[0].Status)
+		require.Equal(t, secondOrder.ID, brackets[0].TakeProfitID)
+		require.Equal(t, "ETHUSDT", brackets[1].Pair)
+
+		// brackets are not listed as orders
+		orders, err := repo.Orders()
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+	})
+
+	t.Run("trailing stops", func(t *testing.T) {
+		stop := &model.TrailingStop{
+			Pair:     "BTCUSDT",
+			Side:     model.SideTypeSell,
+			Quantity: 1,
+			Distance: 2,
+			Price:    10,
+			Stop:     8,
+			Status:   model.TrailingStopStatusActive,
+		}
+		require.NoError(t, repo.SaveTrailingStop(stop))
+		require.NotZero(t, stop.ID)
+
+		stop.Price = 11
+		stop.Stop = 9
+		require.NoError(t, repo.SaveTrailingStop(stop))
+
+		stops, err := repo.TrailingStops()
+		require.NoError(t, err)
+		require.Len(t, stops, 1)
+		require.Equal(t, stop.ID, stops[0].ID)
+		require.Equal(t, 9.0, stops[0].Stop)
+
+		orders, err := repo.Orders()
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+	})
+
+	t.Run("strategy params", func(t *testing.T) {
+		require.NoError(t, repo.SaveStrategyParams(&model.StrategyParams{
+			Strategy: "emacross",
+			Params:   map[string]any{"ema": 8, "mode": "fast"},
+		}))
+		require.NoError(t, repo.SaveStrategyParams(&model.StrategyParams{
+			Strategy: "emacross",
+			Params:   map[string]any{"ema": 9, "mode": "slow"},
+		}))
+		require.NoError(t, repo.SaveStrategyParams(&model.StrategyParams{Params: map[string]any{"ema": 5}}))
+
+		params, err := repo.StrategyParams()
+		require.NoError(t, err)
+		require.Len(t, params, 2)
+		require.Equal(t, "", params[0].Strategy)
+		require.Equal(t, "emacross", params[1].Strategy)
+		// numbers are decoded as float64
+		require.Equal(t, map[string]any{"ema": 9.0, "mode": "slow"}, params[1].Params)
+	})
 }
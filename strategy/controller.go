@@ -1,17 +1,28 @@
 package strategy
 
 import (
+	"sync"
+	"time"
+
 	log "github.com/sirupsen/logrus"
+	"github.com/xhit/go-str2duration/v2"
 
 	"github.com/rodrigo-brito/ninjabot/model"
 	"github.com/rodrigo-brito/ninjabot/service"
 )
 
 type Controller struct {
+	mtx       sync.Mutex
 	strategy  Strategy
 	dataframe *model.Dataframe
 	broker    service.Broker
 	started   bool
+
+	// additional timeframes for multi-timeframe strategies
+	duration       time.Duration
+	timeframes     map[string]int
+	durations      map[string]time.Duration
+	pendingCandles map[string][]model.Candle
 }
 
 func NewStrategyController(pair string, strategy Strategy, broker service.Broker) *Controller {
@@ -20,66 +31,205 @@ func NewStrategyController(pair string, strategy Strategy, broker service.Broker
 		Metadata: make(map[string]model.Series[float64]),
 	}
 
-	return &Controller{
-		dataframe: dataframe,
-		strategy:  strategy,
-		broker:    broker,
+	controller := &Controller{
+		dataframe:      dataframe,
+		strategy:       strategy,
+		broker:         broker,
+		timeframes:     make(map[string]int),
+		durations:      make(map[string]time.Duration),
+		pendingCandles: make(map[string][]model.Candle),
 	}
+
+	if str, ok := strategy.(MultiTimeframeStrategy); ok {
+		var err error
+		controller.duration, err = str2duration.ParseDuration(strategy.Timeframe())
+		if err != nil {
+			log.Errorf("invalid timeframe %s: %v", strategy.Timeframe(), err)
+		}
+
+		dataframe.Higher = make(map[string]*model.Dataframe)
+		for timeframe, warmup := range str.Timeframes() {
+			duration, err := str2duration.ParseDuration(timeframe)
+			if err != nil {
+				log.Errorf("invalid timeframe %s: %v", timeframe, err)
+				continue
+			}
+
+			controller.timeframes[timeframe] = warmup
+			controller.durations[timeframe] = duration
+			dataframe.Higher[timeframe] = &model.Dataframe{
+				Pair:     pair,
+				Metadata: make(map[string]model.Series[float64]),
+			}
+		}
+	}
+
+	return controller
 }
 
 func (s *Controller) Start() {
 	s.started = true
 }
 
+// Timeframe returns the main timeframe of the strategy
+func (s *Controller) Timeframe() string {
+	return s.strategy.Timeframe()
+}
+
+// WarmupPeriod returns the warmup period of the main timeframe
+func (s *Controller) WarmupPeriod() int {
+	return s.strategy.WarmupPeriod()
+}
+
+// Timeframes returns the additional timeframes consumed by the strategy and their warmup periods
+func (s *Controller) Timeframes() map[string]int {
+	return s.timeframes
+}
+
+// OrderBookDepth returns the order book depth consumed by the strategy, or zero if the strategy
+// does not implement OrderBookStrategy
+func (s *Controller) OrderBookDepth() int {
+	if str, ok := s.strategy.(OrderBookStrategy); ok {
+		return str.OrderBookDepth()
+	}
+	return 0
+}
+
+// ready checks if the strategy has enough candles in every timeframe to fill its indicators
+func (s *Controller) ready() bool {
+	if len(s.dataframe.Close) < s.strategy.WarmupPeriod() {
+		return false
+	}
+
+	for timeframe, warmup := range s.timeframes {
+		if len(s.dataframe.Higher[timeframe].Close) < warmup {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Lock blocks the execution of the strategy until Unlock is called, eg: to change its parameters between candles
+func (s *Controller) Lock() {
+	s.mtx.Lock()
+}
+
+// Unlock releases the execution of the strategy, see Lock
+func (s *Controller) Unlock() {
+	s.mtx.Unlock()
+}
+
 func (s *Controller) OnPartialCandle(candle model.Candle) {
-	if !candle.Complete && len(s.dataframe.Close) >= s.strategy.WarmupPeriod() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !candle.Complete && s.ready() {
 		if str, ok := s.strategy.(HighFrequencyStrategy); ok {
-			s.updateDataFrame(candle)
+			s.updateDataFrame(s.dataframe, candle)
 			str.Indicators(s.dataframe)
 			str.OnPartialCandle(s.dataframe, s.broker)
 		}
 	}
 }
 
-func (s *Controller) updateDataFrame(candle model.Candle) {
-	if len(s.dataframe.Time) > 0 && candle.Time.Equal(s.dataframe.Time[len(s.dataframe.Time)-1]) {
-		last := len(s.dataframe.Time) - 1
-		s.dataframe.Close[last] = candle.Close
-		s.dataframe.Open[last] = candle.Open
-		s.dataframe.High[last] = candle.High
-		s.dataframe.Low[last] = candle.Low
-		s.dataframe.Volume[last] = candle.Volume
-		s.dataframe.Time[last] = candle.Time
+// OnHigherCandle receives candles from additional timeframes. The candles are kept in a buffer
+// until the close of a candle from the main timeframe, to avoid look-ahead bias
+func (s *Controller) OnHigherCandle(candle model.Candle) {
+	if _, ok := s.timeframes[candle.Timeframe]; !ok || !candle.Complete {
+		return
+	}
+
+	pending := s.pendingCandles[candle.Timeframe]
+	if last := len(pending) - 1; last >= 0 && candle.Time.Equal(pending[last].Time) {
+		pending[last] = candle
+		return
+	}
+
+	s.pendingCandles[candle.Timeframe] = append(pending, candle)
+}
+
+// alignTimeframes moves the pending candles of additional timeframes, closed before the end of
+// the given candle, to their dataframes
+func (s *Controller) alignTimeframes(candle model.Candle) {
+	closeTime := candle.Time.Add(s.duration)
+	for timeframe, pending := range s.pendingCandles {
+		var i int
+		for ; i < len(pending); i++ {
+			if pending[i].Time.Add(s.durations[timeframe]).After(closeTime) {
+				break
+			}
+			s.updateDataFrame(s.dataframe.Higher[timeframe], pending[i])
+		}
+		s.pendingCandles[timeframe] = pending[i:]
+	}
+}
+
+func (s *Controller) updateDataFrame(dataframe *model.Dataframe, candle model.Candle) {
+	if len(dataframe.Time) > 0 && candle.Time.Equal(dataframe.Time[len(dataframe.Time)-1]) {
+		last := len(dataframe.Time) - 1
+		dataframe.Close[last] = candle.Close
+		dataframe.Open[last] = candle.Open
+		dataframe.High[last] = candle.High
+		dataframe.Low[last] = candle.Low
+		dataframe.Volume[last] = candle.Volume
+		dataframe.Time[last] = candle.Time
 		for k, v := range candle.Metadata {
-			s.dataframe.Metadata[k][last] = v
+			dataframe.Metadata[k][last] = v
 		}
 	} else {
-		s.dataframe.Close = append(s.dataframe.Close, candle.Close)
-		s.dataframe.Open = append(s.dataframe.Open, candle.Open)
-		s.dataframe.High = append(s.dataframe.High, candle.High)
-		s.dataframe.Low = append(s.dataframe.Low, candle.Low)
-		s.dataframe.Volume = append(s.dataframe.Volume, candle.Volume)
-		s.dataframe.Time = append(s.dataframe.Time, candle.Time)
-		s.dataframe.LastUpdate = candle.Time
+		dataframe.Close = append(dataframe.Close, candle.Close)
+		dataframe.Open = append(dataframe.Open, candle.Open)
+		dataframe.High = append(dataframe.High, candle.High)
+		dataframe.Low = append(dataframe.Low, candle.Low)
+		dataframe.Volume = append(dataframe.Volume, candle.Volume)
+		dataframe.Time = append(dataframe.Time, candle.Time)
+		dataframe.LastUpdate = candle.Time
 		for k, v := range candle.Metadata {
-			s.dataframe.Metadata[k] = append(s.dataframe.Metadata[k], v)
+			dataframe.Metadata[k] = append(dataframe.Metadata[k], v)
 		}
 	}
 }
 
 func (s *Controller) OnCandle(candle model.Candle) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
 	if len(s.dataframe.Time) > 0 && candle.Time.Before(s.dataframe.Time[len(s.dataframe.Time)-1]) {
 		log.Errorf("late candle received: %#v", candle)
 		return
 	}
 
-	s.updateDataFrame(candle)
+	s.alignTimeframes(candle)
+	s.updateDataFrame(s.dataframe, candle)
 
-	if len(s.dataframe.Close) >= s.strategy.WarmupPeriod() {
+	if s.ready() {
 		sample := s.dataframe.Sample(s.strategy.WarmupPeriod())
+		if len(s.timeframes) > 0 {
+			sample.Higher = make(map[string]*model.Dataframe, len(s.timeframes))
+			for timeframe, warmup := range s.timeframes {
+				higher := s.dataframe.Higher[timeframe].Sample(warmup)
+				sample.Higher[timeframe] = &higher
+			}
+		}
+
 		s.strategy.Indicators(&sample)
 		if s.started {
 			s.strategy.OnCandle(&sample, s.broker)
 		}
 	}
 }
+
+// OnOrderBook receives the updates of the order book. The updates are received concurrently with
+// the candles, and dispatched to the strategy only after the warmup period.
+func (s *Controller) OnOrderBook(book model.OrderBook) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	str, ok := s.strategy.(OrderBookStrategy)
+	if !ok || !s.started || !s.ready() {
+		return
+	}
+
+	str.OnOrderBook(s.dataframe, book, s.broker)
+}
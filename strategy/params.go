@@ -0,0 +1,210 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+var (
+	ErrInvalidParam = errors.New("invalid parameter")
+	ErrNotTunable   = errors.New("strategy parameters cannot be changed")
+)
+
+// ParamType is the type of the value of a strategy parameter
+type ParamType string
+
+const (
+	ParamInt    ParamType = "int"
+	ParamFloat  ParamType = "float"
+	ParamBool   ParamType = "bool"
+	ParamString ParamType = "string"
+)
+
+// Param declares a parameter of a strategy, see ParametrizedStrategy
+type Param struct {
+	Name        string    `json:"name"`
+	Type        ParamType `json:"type"`
+	Default     any       `json:"default"`
+	Description string    `json:"description,omitempty"`
+
+	// Min, Max and Step define the range of numeric parameters, eg: the search space of the optimizer.
+	// The range is not validated when Max is not greater than Min.
+	Min  float64 `json:"min,omitempty"`
+	Max  float64 `json:"max,omitempty"`
+	Step float64 `json:"step,omitempty"`
+
+	// Options are the allowed values of string parameters, any value is allowed if empty
+	Options []string `json:"options,omitempty"`
+}
+
+// Value converts a value to the type of the parameter and validates it.
+// A nil value returns the default value of the parameter.
+func (p Param) Value(value any) (any, error) {
+	if value == nil {
+		value = p.Default
+	}
+
+	switch p.Type {
+	case ParamInt, ParamFloat:
+		number, ok := toFloat(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a number, got %v", ErrInvalidParam, p.Name, value)
+		}
+
+		if p.Max > p.Min && (number < p.Min || number > p.Max) {
+			return nil, fmt.Errorf("%w: %s must be between %v and %v, got %v", ErrInvalidParam, p.Name,
+				p.Min, p.Max, number)
+		}
+
+		if p.Type == ParamFloat {
+			return number, nil
+		}
+
+		if number != math.Trunc(number) {
+			return nil, fmt.Errorf("%w: %s must be an integer, got %v", ErrInvalidParam, p.Name, number)
+		}
+		return int(number), nil
+	case ParamBool:
+		switch value := value.(type) {
+		case bool:
+			return value, nil
+		case string:
+			if boolean, err := strconv.ParseBool(value); err == nil {
+				return boolean, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s must be a boolean, got %v", ErrInvalidParam, p.Name, value)
+	case ParamString:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a string, got %v", ErrInvalidParam, p.Name, value)
+		}
+
+		if len(p.Options) > 0 && !lo.Contains(p.Options, text) {
+			return nil, fmt.Errorf("%w: %s must be one of %s, got %s", ErrInvalidParam, p.Name,
+				strings.Join(p.Options, ", "), text)
+		}
+		return text, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown type %s of %s", ErrInvalidParam, p.Type, p.Name)
+}
+
+// String returns a description of the parameter, eg: ema=8 (int, 2..50 step 1)
+func (p Param) String() string {
+	details := []string{string(p.Type)}
+	if p.Max > p.Min {
+		interval := fmt.Sprintf("%v..%v", p.Min, p.Max)
+		if p.Step > 0 {
+			interval += fmt.Sprintf(" step %v", p.Step)
+		}
+		details = append(details, interval)
+	}
+	if len(p.Options) > 0 {
+		details = append(details, strings.Join(p.Options, "|"))
+	}
+
+	description := fmt.Sprintf("%s=%v (%s)", p.Name, p.Default, strings.Join(details, ", "))
+	if p.Description != "" {
+		description += " " + p.Description
+	}
+	return description
+}
+
+// ValidateParams converts the values to the types of the declared parameters, filling the missing values
+// with the defaults. Unknown parameters and values out of range are rejected.
+func ValidateParams(declarations []Param, values Params) (Params, error) {
+	declared := make(map[string]bool, len(declarations))
+	result := make(Params, len(declarations))
+	for _, param := range declarations {
+		value, err := param.Value(values[param.Name])
+		if err != nil {
+			return nil, err
+		}
+		result[param.Name] = value
+		declared[param.Name] = true
+	}
+
+	unknown := make([]string, 0)
+	for name := range values {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: unknown %s", ErrInvalidParam, strings.Join(unknown, ", "))
+	}
+
+	return result, nil
+}
+
+// DefaultParams returns the default values of the declared parameters
+func DefaultParams(declarations []Param) Params {
+	params := make(Params, len(declarations))
+	for _, param := range declarations {
+		params[param.Name] = param.Default
+	}
+	return params
+}
+
+// Params are the parameters used to build a strategy, eg: loaded from a config file
+type Params map[string]any
+
+// Float returns a numeric parameter, or the fallback value if the parameter is not defined
+func (p Params) Float(name string, fallback float64) float64 {
+	if number, ok := toFloat(p[name]); ok {
+		return number
+	}
+	return fallback
+}
+
+// Int returns an integer parameter, or the fallback value if the parameter is not defined
+func (p Params) Int(name string, fallback int) int {
+	return int(p.Float(name, float64(fallback)))
+}
+
+// String returns a text parameter, or the fallback value if the parameter is not defined
+func (p Params) String(name string, fallback string) string {
+	if value, ok := p[name]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return fallback
+}
+
+// Bool returns a boolean parameter, or the fallback value if the parameter is not defined
+func (p Params) Bool(name string, fallback bool) bool {
+	switch value := p[name].(type) {
+	case bool:
+		return value
+	case string:
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return fallback
+}
+
+func toFloat(value any) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case string:
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number, true
+		}
+	}
+	return 0, false
+}
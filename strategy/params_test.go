@@ -0,0 +1,83 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParams(t *testing.T) {
+	params := Params{"int": 5, "int64": int64(6), "float": 1.5, "text": "2.5", "bool": true, "flag": "false"}
+
+	require.Equal(t, 5.0, params.Float("int", 0))
+	require.Equal(t, 6.0, params.Float("int64", 0))
+	require.Equal(t, 2.5, params.Float("text", 0))
+	require.Equal(t, 3.0, params.Float("missing", 3))
+	require.Equal(t, 1, params.Int("float", 0))
+	require.Equal(t, "2.5", params.String("text", ""))
+	require.Equal(t, "5", params.String("int", ""))
+	require.Equal(t, "default", params.String("missing", "default"))
+	require.True(t, params.Bool("bool", false))
+	require.False(t, params.Bool("flag", true))
+	require.True(t, params.Bool("missing", true))
+}
+
+func TestParam_Value(t *testing.T) {
+	tt := []struct {
+		name     string
+		param    Param
+		value    any
+		expected any
+		err      string
+	}{
+		{name: "int", param: Param{Name: "n", Type: ParamInt}, value: 5.0, expected: 5},
+		{name: "int from string", param: Param{Name: "n", Type: ParamInt}, value: "7", expected: 7},
+		{name: "int default", param: Param{Name: "n", Type: ParamInt, Default: 3}, expected: 3},
+		{name: "int not integer", param: Param{Name: "n", Type: ParamInt}, value: 1.5, err: "must be an integer"},
+		{name: "int not number", param: Param{Name: "n", Type: ParamInt}, value: "x", err: "must be a number"},
+		{name: "float", param: Param{Name: "f", Type: ParamFloat}, value: int64(2), expected: 2.0},
+		{name: "in range", param: Param{Name: "f", Type: ParamFloat, Min: 1, Max: 2}, value: 1.5, expected: 1.5},
+		{name: "out of range", param: Param{Name: "f", Type: ParamFloat, Min: 1, Max: 2}, value: 3,
+			err: "must be between 1 and 2"},
+		{name: "bool", param: Param{Name: "b", Type: ParamBool}, value: "true", expected: true},
+		{name: "invalid bool", param: Param{Name: "b", Type: ParamBool}, value: 1, err: "must be a boolean"},
+		{name: "string", param: Param{Name: "s", Type: ParamString, Options: []string{"a", "b"}}, value: "b",
+			expected: "b"},
+		{name: "invalid option", param: Param{Name: "s", Type: ParamString, Options: []string{"a", "b"}},
+			value: "c", err: "must be one of a, b"},
+		{name: "invalid string", param: Param{Name: "s", Type: ParamString}, value: 1, err: "must be a string"},
+		{name: "unknown type", param: Param{Name: "u", Type: "date"}, value: 1, err: "unknown type"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := tc.param.Value(tc.value)
+			if tc.err != "" {
+				require.ErrorIs(t, err, ErrInvalidParam)
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	declarations := []Param{
+		{Name: "ema", Type: ParamInt, Default: 8, Min: 2, Max: 50, Step: 1},
+		{Name: "ratio", Type: ParamFloat, Default: 0.5},
+	}
+
+	params, err := ValidateParams(declarations, Params{"ema": 9.0})
+	require.NoError(t, err)
+	require.Equal(t, Params{"ema": 9, "ratio": 0.5}, params)
+	require.Equal(t, Params{"ema": 8, "ratio": 0.5}, DefaultParams(declarations))
+
+	_, err = ValidateParams(declarations, Params{"sma": 1, "ema": 9, "size": 2})
+	require.ErrorIs(t, err, ErrInvalidParam)
+	require.ErrorContains(t, err, "unknown size, sma")
+
+	require.Equal(t, "ema=8 (int, 2..50 step 1)", declarations[0].String())
+	require.Equal(t, "ratio=0.5 (float)", declarations[1].String())
+}
@@ -0,0 +1,103 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrStrategyNotFound = errors.New("strategy not found")
+
+// Factory builds a strategy from its parameters
+type Factory func(params Params) (Strategy, error)
+
+var (
+	registryMtx sync.RWMutex
+	registry    = make(map[string]Factory)
+)
+
+// Register makes a strategy available by name, eg: for the bots created from config files.
+// It panics if the name is already registered or the factory is nil.
+func Register(name string, factory Factory) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	if factory == nil {
+		panic("strategy: register nil factory for " + name)
+	}
+
+	if _, ok := registry[name]; ok {
+		panic("strategy: register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// New builds a registered strategy with the given parameters. The parameters of a ParametrizedStrategy are
+// validated and completed with the default values before the strategy is built.
+func New(name string, params Params) (Strategy, error) {
+	factory, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	declarations, err := Describe(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(declarations) > 0 {
+		params, err = ValidateParams(declarations, params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if params == nil {
+		params = make(Params)
+	}
+	return factory(params)
+}
+
+// Describe returns the parameters declared by a registered strategy, or nil if the strategy
+// does not implement ParametrizedStrategy
+func Describe(name string) ([]Param, error) {
+	factory, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	str, err := factory(make(Params))
+	if err != nil {
+		return nil, err
+	}
+
+	if parametrized, ok := str.(ParametrizedStrategy); ok {
+		return parametrized.Parameters(), nil
+	}
+	return nil, nil
+}
+
+func lookup(name string) (Factory, error) {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStrategyNotFound, name)
+	}
+	return factory, nil
+}
+
+// Registered returns the names of the registered strategies, sorted alphabetically
+func Registered() []string {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
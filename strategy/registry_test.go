@@ -0,0 +1,99 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/service"
+)
+
+type registryStrategy struct {
+	period int
+}
+
+func (r registryStrategy) Timeframe() string                              { return "1h" }
+func (r registryStrategy) WarmupPeriod() int                              { return r.period }
+func (r registryStrategy) Indicators(_ *model.Dataframe) []ChartIndicator { return nil }
+func (r registryStrategy) OnCandle(_ *model.Dataframe, _ service.Broker)  {}
+
+func TestRegistry(t *testing.T) {
+	Register("registry-test", func(params Params) (Strategy, error) {
+		if params.Int("period", 10) <= 0 {
+			return nil, errors.New("invalid period")
+		}
+		return registryStrategy{period: params.Int("period", 10)}, nil
+	})
+
+	require.Contains(t, Registered(), "registry-test")
+
+	str, err := New("registry-test", nil)
+	require.NoError(t, err)
+	require.Equal(t, 10, str.WarmupPeriod())
+
+	str, err = New("registry-test", Params{"period": 20})
+	require.NoError(t, err)
+	require.Equal(t, 20, str.WarmupPeriod())
+
+	_, err = New("registry-test", Params{"period": -1})
+	require.Error(t, err)
+
+	_, err = New("not-found", nil)
+	require.ErrorIs(t, err, ErrStrategyNotFound)
+
+	require.Panics(t, func() {
+		Register("registry-test", func(Params) (Strategy, error) { return nil, nil })
+	})
+	require.Panics(t, func() {
+		Register("registry-nil", nil)
+	})
+}
+
+type parametrizedStrategy struct {
+	registryStrategy
+	mode string
+}
+
+func (p parametrizedStrategy) Parameters() []Param {
+	return []Param{
+		{Name: "period", Type: ParamInt, Default: 10, Min: 2, Max: 50, Step: 1},
+		{Name: "mode", Type: ParamString, Default: "fast", Options: []string{"fast", "slow"}},
+	}
+}
+
+func (p parametrizedStrategy) ParamValues() Params {
+	return Params{"period": p.period, "mode": p.mode}
+}
+
+func TestRegistry_Parametrized(t *testing.T) {
+	Register("registry-parametrized", func(params Params) (Strategy, error) {
+		return parametrizedStrategy{
+			registryStrategy: registryStrategy{period: params.Int("period", 10)},
+			mode:             params.String("mode", "fast"),
+		}, nil
+	})
+
+	declarations, err := Describe("registry-parametrized")
+	require.NoError(t, err)
+	require.Len(t, declarations, 2)
+	require.Equal(t, "period", declarations[0].Name)
+
+	str, err := New("registry-parametrized", Params{"period": "20"})
+	require.NoError(t, err)
+	require.Equal(t, Params{"period": 20, "mode": "fast"}, str.(ParametrizedStrategy).ParamValues())
+
+	_, err = New("registry-parametrized", Params{"period": 100})
+	require.ErrorIs(t, err, ErrInvalidParam)
+
+	_, err = New("registry-parametrized", Params{"size": 1})
+	require.ErrorIs(t, err, ErrInvalidParam)
+
+	declarations, err = Describe("registry-test")
+	require.NoError(t, err)
+	require.Nil(t, declarations)
+
+	_, err = Describe("not-found")
+	require.ErrorIs(t, err, ErrStrategyNotFound)
+}
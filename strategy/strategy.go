@@ -24,3 +24,40 @@ type HighFrequencyStrategy interface {
 	// OnPartialCandle will be executed for each new partial candle, after indicators are filled.
 	OnPartialCandle(df *model.Dataframe, broker service.Broker)
 }
+
+type MultiTimeframeStrategy interface {
+	Strategy
+
+	// Timeframes are the additional timeframes consumed by the strategy, mapped to their warmup period.
+	// The warmup is measured in candles of each timeframe, eg: {"4h": 21, "1d": 50}
+	// Aligned dataframes are available in `df.Higher`, containing only candles closed before the current one.
+	Timeframes() map[string]int
+}
+
+type OrderBookStrategy interface {
+	Strategy
+
+	// OrderBookDepth is the number of levels of each side of the order book received by the strategy, eg: 20
+	OrderBookDepth() int
+	// OnOrderBook will be executed for each update of the order book, after the warmup period.
+	// The order book contains the full book limited to `OrderBookDepth` levels, not only the changed levels.
+	OnOrderBook(df *model.Dataframe, book model.OrderBook, broker service.Broker)
+}
+
+type ParametrizedStrategy interface {
+	Strategy
+
+	// Parameters declares the parameters of the strategy, with their types, default values and ranges.
+	// A strategy built with empty params (see Register) must use the default values.
+	Parameters() []Param
+	// ParamValues returns the current values of the parameters, eg: to include them in backtest reports
+	ParamValues() Params
+}
+
+type TunableStrategy interface {
+	ParametrizedStrategy
+
+	// SetParams applies new values of the parameters while the bot is running, between candles.
+	// The values are validated and complete, with all declared parameters.
+	SetParams(params Params) error
+}
@@ -0,0 +1,57 @@
+// Package strategytest provides the helpers shared by the tests of strategy implementations
+package strategytest
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigo-brito/ninjabot"
+	"github.com/rodrigo-brito/ninjabot/exchange"
+	"github.com/rodrigo-brito/ninjabot/model"
+	"github.com/rodrigo-brito/ninjabot/storage"
+	"github.com/rodrigo-brito/ninjabot/strategy"
+	"github.com/rodrigo-brito/ninjabot/tools/log"
+)
+
+// Backtest runs a strategy with the BTCUSDT 1h candles of the testdata, and returns the bot with the results
+func Backtest(t *testing.T, str strategy.Strategy) *ninjabot.NinjaBot {
+	t.Helper()
+
+	_, file, _, _ := runtime.Caller(0)
+	ctx := context.Background()
+	csvFeed, err := exchange.NewCSVFeed(str.Timeframe(), exchange.PairFeed{
+		Pair:      "BTCUSDT",
+		File:      filepath.Join(filepath.Dir(file), "..", "btc-1h.csv"),
+		Timeframe: "1h",
+	})
+	require.NoError(t, err)
+
+	wallet := exchange.NewPaperWallet(ctx, "USDT", exchange.WithPaperAsset("USDT", 10000),
+		exchange.WithDataFeed(csvFeed))
+
+	db, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	bot, err := ninjabot.NewBot(ctx, ninjabot.Settings{Pairs: []string{"BTCUSDT"}}, wallet, str,
+		ninjabot.WithBacktest(wallet),
+		ninjabot.WithStorage(db),
+		ninjabot.WithLogLevel(log.ErrorLevel),
+		ninjabot.WithProgressBar(false),
+	)
+	require.NoError(t, err)
+	require.NoError(t, bot.Run(ctx))
+	return bot
+}
+
+// Dataframe returns a BTCUSDT dataframe with the close prices
+func Dataframe(closes ...float64) *model.Dataframe {
+	return &model.Dataframe{
+		Pair:     "BTCUSDT",
+		Close:    closes,
+		Metadata: make(map[string]model.Series[float64]),
+	}
+}
@@ -49,6 +49,11 @@ func SetLevel(level logrus.Level) {
 	logrus.SetLevel(level)
 }
 
+// ParseLevel returns the level of a name, eg: debug, info, warn, error
+func ParseLevel(level string) (logrus.Level, error) {
+	return logrus.ParseLevel(level)
+}
+
 func WithField(key string, value interface{}) *logrus.Entry {
 	return logrus.WithField(key, value)
 }
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// align truncates the returns and the benchmark returns to the same length
+func align(returns, benchmark []float64) ([]float64, []float64) {
+	size := len(returns)
+	if len(benchmark) < size {
+		size = len(benchmark)
+	}
+	return returns[:size], benchmark[:size]
+}
+
+// Beta measures the sensitivity of the returns to the benchmark returns
+func Beta(returns, benchmark []float64) float64 {
+	returns, benchmark = align(returns, benchmark)
+	if len(returns) < 2 {
+		return 0
+	}
+
+	variance := stat.Variance(benchmark, nil)
+	if variance == 0 {
+		return 0
+	}
+
+	return stat.Covariance(returns, benchmark, nil) / variance
+}
+
+// Correlation calculates the Pearson correlation between the returns and the benchmark returns
+func Correlation(returns, benchmark []float64) float64 {
+	returns, benchmark = align(returns, benchmark)
+	if len(returns) < 2 || stat.StdDev(returns, nil) == 0 || stat.StdDev(benchmark, nil) == 0 {
+		return 0
+	}
+
+	return stat.Correlation(returns, benchmark, nil)
+}
+
+// Alpha calculates the annualized excess return over the return explained by the benchmark (Jensen's alpha),
+// without risk-free rate
+func Alpha(returns, benchmark []float64, periodsPerYear float64) float64 {
+	returns, benchmark = align(returns, benchmark)
+	if len(returns) < 2 {
+		return 0
+	}
+
+	beta := Beta(returns, benchmark)
+	return (stat.Mean(returns, nil) - beta*stat.Mean(benchmark, nil)) * periodsPerYear
+}
+
+// InformationRatio calculates the annualized active return (returns - benchmark) divided by the tracking error
+func InformationRatio(returns, benchmark []float64, periodsPerYear float64) float64 {
+	returns, benchmark = align(returns, benchmark)
+	if len(returns) < 2 {
+		return 0
+	}
+
+	active := make([]float64, len(returns))
+	for i := range returns {
+		active[i] = returns[i] - benchmark[i]
+	}
+
+	trackingError := stat.StdDev(active, nil)
+	if trackingError == 0 {
+		return 0
+	}
+
+	return stat.Mean(active, nil) / trackingError * math.Sqrt(periodsPerYear)
+}
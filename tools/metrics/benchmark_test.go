@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchmarkMetrics(t *testing.T) {
+	benchmark := []float64{0.01, -0.02, 0.03, 0.01}
+
+	t.Run("same returns", func(t *testing.T) {
+		require.InDelta(t, 1.0, Beta(benchmark, benchmark), 1e-9)
+		require.InDelta(t, 1.0, Correlation(benchmark, benchmark), 1e-9)
+		require.InDelta(t, 0.0, Alpha(benchmark, benchmark, 365), 1e-9)
+		require.Zero(t, InformationRatio(benchmark, benchmark, 365))
+	})
+
+	t.Run("leveraged returns", func(t *testing.T) {
+		returns := []float64{0.03, -0.03, 0.07, 0.03}
+		require.InDelta(t, 2.0, Beta(returns, benchmark), 1e-9)
+		require.InDelta(t, 1.0, Correlation(returns, benchmark), 1e-9)
+		require.InDelta(t, 0.01, Alpha(returns, benchmark, 1), 1e-9)
+		require.Greater(t, InformationRatio(returns, benchmark, 365), 0.0)
+	})
+
+	t.Run("inverse returns", func(t *testing.T) {
+		returns := []float64{-0.01, 0.02, -0.03, -0.01, 0.5}
+		require.InDelta(t, -1.0, Beta(returns, benchmark), 1e-9)
+		require.InDelta(t, -1.0, Correlation(returns, benchmark), 1e-9)
+	})
+
+	t.Run("insufficient data", func(t *testing.T) {
+		require.Zero(t, Beta([]float64{0.1}, []float64{0.1}))
+		require.Zero(t, Correlation([]float64{0.1, 0.1}, benchmark))
+		require.Zero(t, Alpha(nil, benchmark, 365))
+	})
+}
@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+const year = 365 * 24 * time.Hour
+
+// Period is a time interval, eg: the time a trade was open
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Returns calculates the percentage change between consecutive values of an equity curve
+func Returns(equity []float64) []float64 {
+	if len(equity) < 2 {
+		return nil
+	}
+
+	returns := make([]float64, 0, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1] == 0 {
+			returns = append(returns, 0)
+			continue
+		}
+		returns = append(returns, equity[i]/equity[i-1]-1)
+	}
+	return returns
+}
+
+// SharpeRatio calculates the annualized Sharpe ratio of periodic returns, without risk-free rate.
+// periodsPerYear is the number of returns in a year, eg: 365 for daily returns.
+func SharpeRatio(returns []float64, periodsPerYear float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	stdDev := stat.StdDev(returns, nil)
+	if stdDev == 0 {
+		return 0
+	}
+
+	return stat.Mean(returns, nil) / stdDev * math.Sqrt(periodsPerYear)
+}
+
+// SortinoRatio calculates the annualized Sortino ratio of periodic returns, penalizing only the negative returns
+func SortinoRatio(returns []float64, periodsPerYear float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	var downside float64
+	for _, value := range returns {
+		if value < 0 {
+			downside += value * value
+		}
+	}
+
+	downsideDeviation := math.Sqrt(downside / float64(len(returns)))
+	if downsideDeviation == 0 {
+		return 0
+	}
+
+	return stat.Mean(returns, nil) / downsideDeviation * math.Sqrt(periodsPerYear)
+}
+
+// CAGR calculates the compound annual growth rate between two values
+func CAGR(start, end float64, duration time.Duration) float64 {
+	if start <= 0 || end <= 0 || duration <= 0 {
+		return 0
+	}
+
+	return math.Pow(end/start, float64(year)/float64(duration)) - 1
+}
+
+// MaxDrawdown returns the largest percentage drop from a peak of the equity curve, as a negative value
+func MaxDrawdown(equity []float64) float64 {
+	var peak, maxDrawdown float64
+	for _, value := range equity {
+		peak = math.Max(peak, value)
+		if peak > 0 {
+			maxDrawdown = math.Min(maxDrawdown, value/peak-1)
+		}
+	}
+	return maxDrawdown
+}
+
+// CalmarRatio calculates the CAGR divided by the absolute max drawdown of the equity curve
+func CalmarRatio(equity []float64, duration time.Duration) float64 {
+	maxDrawdown := MaxDrawdown(equity)
+	if len(equity) < 2 || maxDrawdown == 0 {
+		return 0
+	}
+
+	return CAGR(equity[0], equity[len(equity)-1], duration) / math.Abs(maxDrawdown)
+}
+
+// UlcerIndex measures the depth and duration of drawdowns, as the quadratic mean of drawdowns in percentage
+func UlcerIndex(equity []float64) float64 {
+	if len(equity) == 0 {
+		return 0
+	}
+
+	var peak, sum float64
+	for _, value := range equity {
+		peak = math.Max(peak, value)
+		if peak > 0 {
+			drawdown := (value/peak - 1) * 100
+			sum += drawdown * drawdown
+		}
+	}
+	return math.Sqrt(sum / float64(len(equity)))
+}
+
+// RecoveryFactor calculates the net profit divided by the largest drop in value of the equity curve
+func RecoveryFactor(equity []float64) float64 {
+	if len(equity) < 2 {
+		return 0
+	}
+
+	var peak, maxDrop float64
+	for _, value := range equity {
+		peak = math.Max(peak, value)
+		maxDrop = math.Max(maxDrop, peak-value)
+	}
+
+	if maxDrop == 0 {
+		return 0
+	}
+
+	return (equity[len(equity)-1] - equity[0]) / maxDrop
+}
+
+// MaxConsecutiveLosses returns the longest sequence of negative values
+func MaxConsecutiveLosses(values []float64) int {
+	var current, maxLosses int
+	for _, value := range values {
+		if value < 0 {
+			current++
+			if current > maxLosses {
+				maxLosses = current
+			}
+		} else {
+			current = 0
+		}
+	}
+	return maxLosses
+}
+
+// AverageDuration calculates the mean of durations, eg: the time of trades
+func AverageDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, duration := range durations {
+		total += duration
+	}
+	return total / time.Duration(len(durations))
+}
+
+// Exposure calculates the fraction of time between start and end covered by at least one period,
+// eg: the time with open positions in a backtest
+func Exposure(periods []Period, start, end time.Time) float64 {
+	if !end.After(start) || len(periods) == 0 {
+		return 0
+	}
+
+	sorted := make([]Period, len(periods))
+	copy(sorted, periods)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	var covered time.Duration
+	var current *Period
+	for i := range sorted {
+		period := Period{Start: sorted[i].Start, End: sorted[i].End}
+		if period.Start.Before(start) {
+			period.Start = start
+		}
+		if period.End.After(end) {
+			period.End = end
+		}
+		if !period.End.After(period.Start) {
+			continue
+		}
+
+		if current != nil && !period.Start.After(current.End) {
+			if period.End.After(current.End) {
+				current.End = period.End
+			}
+			continue
+		}
+
+		if current != nil {
+			covered += current.End.Sub(current.Start)
+		}
+		current = &period
+	}
+
+	if current != nil {
+		covered += current.End.Sub(current.Start)
+	}
+
+	return float64(covered) / float64(end.Sub(start))
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReturns(t *testing.T) {
+	require.Nil(t, Returns([]float64{100}))
+	require.InDeltaSlice(t, []float64{0.1, -0.5}, Returns([]float64{100, 110, 55}), 1e-9)
+}
+
+func TestSharpeAndSortino(t *testing.T) {
+	returns := []float64{0.01, -0.02, 0.03, 0.01}
+	require.InDelta(t, 0.3638, SharpeRatio(returns, 1), 1e-4)
+	require.InDelta(t, 0.3638*math.Sqrt(365), SharpeRatio(returns, 365), 1e-2)
+	require.InDelta(t, 0.75, SortinoRatio(returns, 1), 1e-9)
+
+	require.Zero(t, SharpeRatio([]float64{0.01, 0.01}, 365))
+	require.Zero(t, SortinoRatio([]float64{0.01, 0.02}, 365))
+}
+
+func TestCAGR(t *testing.T) {
+	require.InDelta(t, 0.1, CAGR(100, 121, 2*year), 1e-9)
+	require.InDelta(t, 0.21, CAGR(100, 110, year/2), 1e-9)
+	require.Zero(t, CAGR(0, 110, year))
+}
+
+func TestDrawdownMetrics(t *testing.T) {
+	equity := []float64{100, 120, 90, 110, 150}
+
+	require.InDelta(t, -0.25, MaxDrawdown(equity), 1e-9)
+	require.InDelta(t, 2.5, CalmarRatio([]float64{100, 80, 150}, year), 1e-9)
+	require.InDelta(t, math.Sqrt((25*25+(110.0/120-1)*100*(110.0/120-1)*100)/5), UlcerIndex(equity), 1e-9)
+	require.InDelta(t, 50.0/30.0, RecoveryFactor(equity), 1e-9)
+	require.Zero(t, RecoveryFactor([]float64{100, 110}))
+}
+
+func TestMaxConsecutiveLosses(t *testing.T) {
+	require.Equal(t, 3, MaxConsecutiveLosses([]float64{1, -1, -2, 3, -1, -1, -1, 2}))
+	require.Equal(t, 0, MaxConsecutiveLosses([]float64{1, 2}))
+}
+
+func TestDurations(t *testing.T) {
+	require.Equal(t, 2*time.Hour, AverageDuration([]time.Duration{time.Hour, 3 * time.Hour}))
+	require.Zero(t, AverageDuration(nil))
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Hour)
+	periods := []Period{
+		{Start: start.Add(time.Hour), End: start.Add(3 * time.Hour)},
+		{Start: start.Add(2 * time.Hour), End: start.Add(4 * time.Hour)},  // overlap
+		{Start: start.Add(8 * time.Hour), End: start.Add(12 * time.Hour)}, // after end
+	}
+	require.InDelta(t, 0.5, Exposure(periods, start, end), 1e-9)
+	require.Zero(t, Exposure(nil, start, end))
+}